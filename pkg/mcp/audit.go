@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// AuditEvent records one tool call made by ExecuteTool.
+type AuditEvent struct {
+	// Tool is the tool's name on its server, and Server the server's name.
+	Tool   string
+	Server string
+	// Arguments is a copy of the arguments sent, with the values of sensitive
+	// arguments replaced by protocol.RedactedValue. Changing it does not
+	// affect the call.
+	Arguments map[string]interface{}
+	// Start is when the call was sent and Duration how long it took.
+	Start    time.Time
+	Duration time.Duration
+	// IsError is set when the call failed or the tool reported an error.
+	// Err holds the failure, and is nil for a tool error result.
+	IsError bool
+	Err     error
+}
+
+// sanitizeArguments copies args for an AuditEvent, redacting the values t
+// marks as sensitive.
+func sanitizeArguments(t *protocol.Tool, args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return map[string]interface{}{}
+	}
+	return t.RedactArguments(args)
+}
@@ -2,20 +2,50 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"go-mcp/pkg/mcp/protocol"
 	"go-mcp/pkg/mcp/server"
+	"go-mcp/pkg/mcp/tool"
 )
 
 var (
 	ErrNotInitialized     = errors.New("MCP client not initialized")
 	ErrAlreadyInitialized = errors.New("MCP client already initialized")
-	ErrToolNotFound       = errors.New("tool not found")
+	ErrToolNotFound       = tool.ErrToolNotFound
+	ErrAmbiguousTool      = tool.ErrAmbiguousTool
+	ErrToolConflict       = errors.New("tool name conflict")
+	ErrInvalidArguments   = errors.New("invalid tool arguments")
 )
 
+// ToolConflictPolicy decides what AddServer does when a server exposes a tool
+// whose name another server already provides.
+type ToolConflictPolicy int
+
+const (
+	// ConflictNamespace keeps both tools; they are told apart by their
+	// qualified names ("server/tool").
+	ConflictNamespace ToolConflictPolicy = iota
+	// ConflictError rejects the new server.
+	ConflictError
+	// ConflictSkip keeps the existing tool and skips the new one with a warning.
+	ConflictSkip
+)
+
+// ToolWithSource is a tool along with the server that provides it.
+type ToolWithSource struct {
+	Tool *protocol.Tool
+	// Server is the name of the server providing the tool.
+	Server string
+	// QualifiedName ("server/tool") names the tool unambiguously in
+	// ExecuteTool and GetTool.
+	QualifiedName string
+}
+
 type ToolResult struct {
 	ToolName string
 	Contents []protocol.Content
@@ -30,10 +60,49 @@ type MCPClient interface {
 	ListServers() []*server.Server
 	ListTools() []*protocol.Tool
 	GetTool(name string) (*protocol.Tool, error)
-	ExecuteTool(ctx context.Context, toolName string, args map[string]interface{}) (*protocol.CallToolResult, error)
+	ExecuteTool(ctx context.Context, toolName string, args map[string]interface{}, opts ...ExecuteOption) (*protocol.CallToolResult, error)
+}
+
+// ExecuteOption adjusts a single ExecuteTool call.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct {
+	dryRun bool
 }
 
+// DryRun makes ExecuteTool resolve the tool, validate the arguments and check
+// that its server is running, then return without calling the tool. The
+// result describes the call that would have been made; its Meta holds
+// "dryRun", "server", "tool" and "arguments".
+func DryRun() ExecuteOption {
+	return func(o *executeOptions) {
+		o.dryRun = true
+	}
+}
+
+// Client keys tools by their qualified name ("server/tool"), so servers
+// exposing identically named tools do not overwrite each other.
 type Client struct {
+	// ToolConflicts controls how tool name clashes between servers are
+	// handled. Defaults to ConflictNamespace.
+	ToolConflicts ToolConflictPolicy
+
+	// Logger receives diagnostics such as skipped tools. Defaults to
+	// discarding them.
+	Logger protocol.Logger
+
+	// SkipArgumentValidation sends tool arguments as given. By default
+	// ExecuteTool checks them against the tool's input schema first and
+	// fails with ErrInvalidArguments without contacting the server.
+	SkipArgumentValidation bool
+
+	// AuditHook, when set, receives an AuditEvent for every tool call
+	// ExecuteTool makes, whether it succeeds or not. Calls rejected before
+	// being sent, and dry runs, are not reported. The hook runs synchronously
+	// on the calling goroutine before ExecuteTool returns, so it must be
+	// quick; hand events off to another goroutine for slow sinks.
+	AuditHook func(AuditEvent)
+
 	manager     *server.Manager
 	tools       map[string]*protocol.Tool
 	toolSources map[string]string
@@ -87,23 +156,70 @@ func (c *Client) AddServer(config server.ServerConfig) error {
 		return err
 	}
 
-	return c.importToolsFromServer(srv)
+	if err := c.importToolsFromServer(srv); err != nil {
+		if shutdownErr := c.manager.ShutdownServer(context.Background(), srv.Name); shutdownErr != nil {
+			return fmt.Errorf("%w (shutdown failed: %v)", err, shutdownErr)
+		}
+		return err
+	}
+
+	return nil
 }
 
+// importToolsFromServer registers the tools of srv. Conflicts are checked
+// before anything is registered, so a rejected server leaves no tools behind.
 func (c *Client) importToolsFromServer(srv *server.Server) error {
-	for _, protocolTool := range srv.Tools {
-		tool := &protocol.Tool{
+	var tools []*protocol.Tool
+
+	for _, protocolTool := range srv.GetTools() {
+		key := tool.QualifiedName(srv.Name, protocolTool.Name)
+		if owner, exists := c.toolSources[key]; exists && owner != srv.Name {
+			return fmt.Errorf("%w: %s from server %s clashes with a tool of server %s", ErrToolConflict, key, srv.Name, owner)
+		}
+
+		if owner := c.bareNameOwner(protocolTool.Name, srv.Name); owner != "" {
+			switch c.ToolConflicts {
+			case ConflictError:
+				return fmt.Errorf("%w: tool %s from server %s is already provided by server %s", ErrToolConflict, protocolTool.Name, srv.Name, owner)
+			case ConflictSkip:
+				c.logger().Warn("skipping tool already provided by another server", "tool", protocolTool.Name, "server", srv.Name, "owner", owner)
+				continue
+			}
+		}
+
+		tools = append(tools, &protocol.Tool{
 			Name:        protocolTool.Name,
 			Description: protocolTool.Description,
 			InputSchema: protocolTool.InputSchema,
-		}
+		})
+	}
 
-		c.tools[tool.Name] = tool
-		c.toolSources[tool.Name] = srv.Name
+	for _, t := range tools {
+		key := tool.QualifiedName(srv.Name, t.Name)
+		c.tools[key] = t
+		c.toolSources[key] = srv.Name
 	}
 	return nil
 }
 
+func (c *Client) logger() protocol.Logger {
+	if c.Logger == nil {
+		return protocol.NopLogger{}
+	}
+	return c.Logger
+}
+
+// bareNameOwner returns the server, other than serverName, that already
+// provides a tool called name.
+func (c *Client) bareNameOwner(name, serverName string) string {
+	for key, t := range c.tools {
+		if t.Name == name && c.toolSources[key] != serverName {
+			return c.toolSources[key]
+		}
+	}
+	return ""
+}
+
 func (c *Client) RemoveServer(serverName string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -143,6 +259,7 @@ func (c *Client) GetServer(serverName string) (*server.Server, error) {
 	return c.manager.GetServer(serverName)
 }
 
+// ListServers returns the managed servers sorted by name.
 func (c *Client) ListServers() []*server.Server {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -164,6 +281,8 @@ func (c *Client) ListServers() []*server.Server {
 	return servers
 }
 
+// ListTools returns every tool sorted by name. Tools of the same name from
+// different servers are ordered by server.
 func (c *Client) ListTools() []*protocol.Tool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -173,8 +292,48 @@ func (c *Client) ListTools() []*protocol.Tool {
 	}
 
 	tools := make([]*protocol.Tool, 0, len(c.tools))
-	for _, tool := range c.tools {
-		tools = append(tools, tool)
+	for _, key := range tool.SortedKeys(c.tools) {
+		tools = append(tools, c.tools[key])
+	}
+	return tools
+}
+
+// ListToolsWithSource is ListTools with each tool's server, for callers that
+// group tools by server. The order is the same as ListTools.
+func (c *Client) ListToolsWithSource() []ToolWithSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.initialized {
+		return nil
+	}
+
+	tools := make([]ToolWithSource, 0, len(c.tools))
+	for _, key := range tool.SortedKeys(c.tools) {
+		tools = append(tools, ToolWithSource{
+			Tool:          c.tools[key],
+			Server:        c.toolSources[key],
+			QualifiedName: key,
+		})
+	}
+	return tools
+}
+
+// SearchTools returns the tools whose name or description contains query,
+// ignoring case, ordered like ListTools. An empty query matches every tool.
+func (c *Client) SearchTools(query string) []*protocol.Tool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.initialized {
+		return nil
+	}
+
+	var tools []*protocol.Tool
+	for _, key := range tool.SortedKeys(c.tools) {
+		if t := c.tools[key]; tool.MatchesQuery(t, query) {
+			tools = append(tools, t)
+		}
 	}
 	return tools
 }
@@ -187,52 +346,102 @@ func (c *Client) GetTool(name string) (*protocol.Tool, error) {
 		return nil, ErrNotInitialized
 	}
 
-	tool, exists := c.tools[name]
+	t, _, err := c.resolveTool(name)
+	return t, err
+}
+
+// ResolveTool returns the tool and the name of the server providing it. The
+// name may be qualified ("server/tool") or bare, in which case exactly one
+// server must provide a tool with that name.
+func (c *Client) ResolveTool(name string) (*protocol.Tool, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.initialized {
+		return nil, "", ErrNotInitialized
+	}
+
+	return c.resolveTool(name)
+}
+
+func (c *Client) resolveTool(name string) (*protocol.Tool, string, error) {
+	key, err := tool.Resolve(c.tools, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	serverName, exists := c.toolSources[key]
 	if !exists {
-		return nil, ErrToolNotFound
+		return nil, "", fmt.Errorf("no server found for tool: %s", name)
 	}
 
-	return tool, nil
+	return c.tools[key], serverName, nil
 }
 
 func (c *Client) getToolServer(name string) (*server.Server, error) {
-	serverName, exists := c.toolSources[name]
-	if !exists {
-		return nil, ErrToolNotFound
+	_, serverName, err := c.resolveTool(name)
+	if err != nil {
+		return nil, err
 	}
 
 	return c.manager.GetServer(serverName)
 }
 
-func (c *Client) ExecuteTool(ctx context.Context, toolName string, args map[string]interface{}) (*protocol.CallToolResult, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+func (c *Client) ExecuteTool(ctx context.Context, toolName string, args map[string]interface{}, opts ...ExecuteOption) (*protocol.CallToolResult, error) {
+	var options executeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
+	c.mu.RLock()
 	if !c.initialized {
+		c.mu.RUnlock()
 		return nil, ErrNotInitialized
 	}
+	t, serverName, err := c.resolveTool(toolName)
+	validate := !c.SkipArgumentValidation
+	auditHook := c.AuditHook
+	c.mu.RUnlock()
 
-	_, exists := c.tools[toolName]
-	if !exists {
-		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
+	if err != nil {
+		return nil, err
 	}
 
-	serverName, exists := c.toolSources[toolName]
-	if !exists {
-		return nil, fmt.Errorf("no server found for tool: %s", toolName)
+	if validate {
+		if err := t.ValidateArguments(args); err != nil {
+			return nil, fmt.Errorf("%w for %s: %w", ErrInvalidArguments, toolName, err)
+		}
 	}
 
-	srv, err := c.manager.GetServer(serverName)
-	if err != nil {
-		return nil, err
+	if options.dryRun {
+		return c.dryRun(serverName, t, args)
 	}
 
-	call := &protocol.ToolCall{
-		Name:      toolName,
-		Arguments: args,
+	start := time.Now()
+	result, err := c.callTool(ctx, serverName, t.Name, args)
+
+	if auditHook != nil {
+		auditHook(AuditEvent{
+			Tool:      t.Name,
+			Server:    serverName,
+			Arguments: sanitizeArguments(t, args),
+			Start:     start,
+			Duration:  time.Since(start),
+			IsError:   err != nil || result.IsError,
+			Err:       err,
+		})
 	}
 
-	result, err := srv.Client.CallTool(ctx, call.Name, call.Arguments)
+	return result, err
+}
+
+// callTool makes the call without holding c.mu, so RemoveServer and Shutdown
+// do not wait for it. The manager looks the server up again, so once it has
+// been removed calls fail with server.ErrServerNotFound, and calls still in
+// flight fail when its client disconnects. Servers only know their own,
+// unqualified tool names.
+func (c *Client) callTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (*protocol.CallToolResult, error) {
+	result, err := c.manager.CallTool(ctx, serverName, toolName, args)
 	if err != nil {
 		return nil, err
 	}
@@ -241,37 +450,12 @@ func (c *Client) ExecuteTool(ctx context.Context, toolName string, args map[stri
 	var isError bool
 
 	if m, ok := result.(map[string]interface{}); ok {
-		if val, ok := m["isError"].(bool); ok {
-			isError = val
-		}
-
-		if contentArray, ok := m["content"].([]interface{}); ok {
-			for _, item := range contentArray {
-				if contentMap, ok := item.(map[string]interface{}); ok {
-					typeVal, hasType := contentMap["type"].(string)
-					if !hasType {
-						continue
-					}
-
-					switch typeVal {
-					case string(protocol.ContentTypeText):
-						if textVal, ok := contentMap["text"].(string); ok {
-							content = append(content, protocol.TextContent{
-								Type: string(protocol.ContentTypeText),
-								Text: textVal,
-							})
-						}
-					default:
-						if textVal, ok := contentMap["text"].(string); ok {
-							content = append(content, protocol.TextContent{
-								Type: string(protocol.ContentTypeText),
-								Text: textVal,
-							})
-						}
-					}
-				}
-			}
+		decoded, err := protocol.DecodeCallToolResult(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result of tool %s: %w", toolName, err)
 		}
+		content = decoded.Content
+		isError = decoded.IsError
 	}
 
 	if len(content) == 0 {
@@ -288,3 +472,36 @@ func (c *Client) ExecuteTool(ctx context.Context, toolName string, args map[stri
 		IsError: isError,
 	}, nil
 }
+
+// dryRun describes the call ExecuteTool would make, with sensitive arguments
+// redacted.
+func (c *Client) dryRun(serverName string, t *protocol.Tool, args map[string]interface{}) (*protocol.CallToolResult, error) {
+	srv, err := c.manager.GetServer(serverName)
+	if err != nil {
+		return nil, err
+	}
+	if !srv.IsRunning() {
+		return nil, fmt.Errorf("server %s is not running", serverName)
+	}
+
+	args = t.RedactArguments(args)
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	return &protocol.CallToolResult{
+		Content: []protocol.Content{
+			protocol.TextContent{
+				Type: string(protocol.ContentTypeText),
+				Text: fmt.Sprintf("Dry run: would call tool %s on server %s with arguments %s", t.Name, serverName, arguments),
+			},
+		},
+		Meta: map[string]interface{}{
+			"dryRun":    true,
+			"server":    serverName,
+			"tool":      t.Name,
+			"arguments": args,
+		},
+	}, nil
+}
@@ -3,7 +3,10 @@ package mcp
 import (
 	"context"
 	"go-mcp/pkg/mcp/protocol"
+	"go-mcp/pkg/mcp/server"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +56,27 @@ func TestClient(t *testing.T) {
 		assert.True(t, toolNames["tool2"])
 	})
 
+	t.Run("SearchTools", func(t *testing.T) {
+		client := setupClient(t)
+
+		client.tools["server1/read-file"] = &protocol.Tool{Name: "read-file", Description: "Read a file"}
+		client.tools["server2/fetch"] = &protocol.Tool{Name: "fetch", Description: "Download a URL"}
+		client.toolSources["server1/read-file"] = "server1"
+		client.toolSources["server2/fetch"] = "server2"
+
+		assert.Len(t, client.SearchTools(""), 2)
+
+		tools := client.SearchTools("FILE")
+		require.Len(t, tools, 1)
+		assert.Equal(t, "read-file", tools[0].Name)
+
+		tools = client.SearchTools("url")
+		require.Len(t, tools, 1)
+		assert.Equal(t, "fetch", tools[0].Name)
+
+		assert.Empty(t, client.SearchTools("nothing"))
+	})
+
 	t.Run("GetTool", func(t *testing.T) {
 		client := setupClient(t)
 
@@ -64,13 +88,417 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, "tool1", tool.Name)
 
 		_, err = client.GetTool("non-existent")
-		assert.Equal(t, ErrToolNotFound, err)
+		assert.ErrorIs(t, err, ErrToolNotFound)
+	})
+
+	t.Run("Namespacing", func(t *testing.T) {
+		client := setupClient(t)
+
+		for _, name := range []string{"server1", "server2"} {
+			err := client.importToolsFromServer(&server.Server{
+				Name:  name,
+				Tools: []protocol.Tool{{Name: "echo"}, {Name: name + "-only"}},
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, client.ListTools(), 4, "identically named tools must not overwrite each other")
+
+		tool, serverName, err := client.ResolveTool("server2/echo")
+		require.NoError(t, err)
+		assert.Equal(t, "echo", tool.Name)
+		assert.Equal(t, "server2", serverName)
+
+		_, serverName, err = client.ResolveTool("server1-only")
+		require.NoError(t, err)
+		assert.Equal(t, "server1", serverName)
+
+		_, _, err = client.ResolveTool("echo")
+		assert.ErrorIs(t, err, ErrAmbiguousTool)
+
+		// Routing fails on the unknown server rather than the tool lookup.
+		_, err = client.ExecuteTool(ctx, "server1/echo", nil)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrToolNotFound)
+
+		client.unregisterToolsFromServer("server1")
+		_, serverName, err = client.ResolveTool("echo")
+		require.NoError(t, err)
+		assert.Equal(t, "server2", serverName)
+	})
+}
+
+func TestClientToolConflicts(t *testing.T) {
+	first := &server.Server{Name: "server1", Tools: []protocol.Tool{{Name: "echo"}}}
+	second := &server.Server{Name: "server2", Tools: []protocol.Tool{{Name: "echo"}, {Name: "reverse"}}}
+
+	t.Run("Namespace keeps both tools", func(t *testing.T) {
+		client := setupClient(t)
+
+		require.NoError(t, client.importToolsFromServer(first))
+		require.NoError(t, client.importToolsFromServer(second))
+		assert.Len(t, client.ListTools(), 3)
+	})
+
+	t.Run("Error rejects the whole server", func(t *testing.T) {
+		client := setupClient(t)
+		client.ToolConflicts = ConflictError
+
+		require.NoError(t, client.importToolsFromServer(first))
+		err := client.importToolsFromServer(second)
+		assert.ErrorIs(t, err, ErrToolConflict)
+		assert.Contains(t, err.Error(), "server1")
+
+		// Nothing from the rejected server is registered.
+		assert.Len(t, client.ListTools(), 1)
+		_, err = client.GetTool("reverse")
+		assert.ErrorIs(t, err, ErrToolNotFound)
+	})
+
+	t.Run("Skip keeps the existing tool", func(t *testing.T) {
+		client := setupClient(t)
+		client.ToolConflicts = ConflictSkip
+
+		require.NoError(t, client.importToolsFromServer(first))
+		require.NoError(t, client.importToolsFromServer(second))
+		assert.Len(t, client.ListTools(), 2)
+
+		_, serverName, err := client.ResolveTool("echo")
+		require.NoError(t, err)
+		assert.Equal(t, "server1", serverName)
+
+		_, serverName, err = client.ResolveTool("reverse")
+		require.NoError(t, err)
+		assert.Equal(t, "server2", serverName)
+	})
+
+	t.Run("Qualified name clashes always fail", func(t *testing.T) {
+		client := setupClient(t)
+
+		require.NoError(t, client.importToolsFromServer(&server.Server{Name: "a", Tools: []protocol.Tool{{Name: "b/c"}}}))
+		err := client.importToolsFromServer(&server.Server{Name: "a/b", Tools: []protocol.Tool{{Name: "c"}}})
+		assert.ErrorIs(t, err, ErrToolConflict)
 	})
 }
 
+func TestClientListToolsWithSource(t *testing.T) {
+	client := setupClient(t)
+
+	require.NoError(t, client.importToolsFromServer(&server.Server{Name: "server1", Tools: []protocol.Tool{{Name: "echo"}}}))
+	require.NoError(t, client.importToolsFromServer(&server.Server{Name: "server2", Tools: []protocol.Tool{{Name: "echo"}, {Name: "reverse"}}}))
+
+	byServer := make(map[string][]string)
+	for _, entry := range client.ListToolsWithSource() {
+		byServer[entry.Server] = append(byServer[entry.Server], entry.Tool.Name)
+		assert.Equal(t, entry.Server+"/"+entry.Tool.Name, entry.QualifiedName)
+
+		tool, err := client.GetTool(entry.QualifiedName)
+		require.NoError(t, err)
+		assert.Same(t, entry.Tool, tool)
+	}
+
+	assert.Equal(t, []string{"echo"}, byServer["server1"])
+	assert.ElementsMatch(t, []string{"echo", "reverse"}, byServer["server2"])
+
+	assert.Nil(t, NewClient().ListToolsWithSource(), "nothing is listed before Initialize")
+}
+
+func TestClientListOrdering(t *testing.T) {
+	client := setupClient(t)
+
+	require.NoError(t, client.importToolsFromServer(&server.Server{Name: "server2", Tools: []protocol.Tool{{Name: "reverse"}, {Name: "echo"}}}))
+	require.NoError(t, client.importToolsFromServer(&server.Server{Name: "server1", Tools: []protocol.Tool{{Name: "upper"}, {Name: "echo"}}}))
+
+	for i := 0; i < 10; i++ {
+		var names []string
+		for _, entry := range client.ListToolsWithSource() {
+			names = append(names, entry.QualifiedName)
+		}
+		assert.Equal(t, []string{"server1/echo", "server2/echo", "server2/reverse", "server1/upper"}, names)
+
+		var tools []string
+		for _, tool := range client.ListTools() {
+			tools = append(tools, tool.Name)
+		}
+		assert.Equal(t, []string{"echo", "echo", "reverse", "upper"}, tools)
+	}
+}
+
 func setupClient(t *testing.T) *Client {
 	client := NewClient()
 	err := client.Initialize(context.Background())
 	require.NoError(t, err)
 	return client
 }
+
+// serveTools answers the handshake, lists an "echo", a "hang", a "greet" and a
+// "login" tool, and answers calls to all but hang, which are never answered.
+func serveTools(transport *protocol.InMemoryTransport) {
+	for {
+		req, err := transport.ReceiveRequest()
+		if err != nil {
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			result = map[string]interface{}{
+				"protocolVersion": req.Params["protocolVersion"],
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+			}
+		case "mcp.list_tools":
+			result = map[string]interface{}{
+				"tools": []interface{}{
+					map[string]interface{}{"name": "echo", "inputSchema": map[string]interface{}{"type": "object"}},
+					map[string]interface{}{"name": "hang", "inputSchema": map[string]interface{}{"type": "object"}},
+					map[string]interface{}{"name": "greet", "input_schema": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+						"required":   []interface{}{"name"},
+					}},
+					map[string]interface{}{"name": "login", "input_schema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"user":     map[string]interface{}{"type": "string"},
+							"password": map[string]interface{}{"type": "string", "x-sensitive": true},
+						},
+					}},
+				},
+			}
+		case "echo", "greet", "login":
+			result = map[string]interface{}{
+				"content": []interface{}{map[string]interface{}{"type": "text", "text": "ok"}},
+			}
+		default:
+			continue
+		}
+
+		if err := transport.SendResponse(protocol.NewResponse(req.ID, result)); err != nil {
+			return
+		}
+	}
+}
+
+func setupServedClient(t *testing.T) *Client {
+	client := setupClient(t)
+	client.manager = server.NewManager(server.WithTransportFactory(func(config server.ServerConfig) (protocol.Transport, error) {
+		clientEnd, serverEnd := protocol.NewInMemoryPair()
+		serverEnd.Start()
+		go serveTools(serverEnd)
+		return clientEnd, nil
+	}))
+	t.Cleanup(func() { client.Shutdown(context.Background()) })
+	return client
+}
+
+func TestClientExecuteToolDuringRemoval(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrent calls and removals", func(t *testing.T) {
+		client := setupServedClient(t)
+
+		var wg sync.WaitGroup
+		for round := 0; round < 5; round++ {
+			require.NoError(t, client.AddServer(server.ServerConfig{Name: "server1", Command: "fake"}))
+
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						result, err := client.ExecuteTool(ctx, "echo", nil)
+						if err == nil {
+							assert.Equal(t, "ok", result.Content[0].(protocol.TextContent).Text)
+						}
+					}
+				}()
+			}
+
+			require.NoError(t, client.RemoveServer("server1"))
+			wg.Wait()
+
+			_, err := client.ExecuteTool(ctx, "server1/echo", nil)
+			assert.ErrorIs(t, err, ErrToolNotFound, "no calls after removal")
+		}
+	})
+
+	t.Run("removal does not wait for calls in flight", func(t *testing.T) {
+		client := setupServedClient(t)
+		require.NoError(t, client.AddServer(server.ServerConfig{Name: "server1", Command: "fake"}))
+
+		called := make(chan error, 1)
+		go func() {
+			_, err := client.ExecuteTool(ctx, "hang", nil)
+			called <- err
+		}()
+
+		// Give the call time to be sent before removing its server.
+		time.Sleep(20 * time.Millisecond)
+
+		removed := make(chan error, 1)
+		go func() { removed <- client.RemoveServer("server1") }()
+
+		select {
+		case err := <-removed:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("RemoveServer blocked on a call in flight")
+		}
+
+		select {
+		case err := <-called:
+			assert.Error(t, err, "the call fails once its server is gone")
+		case <-time.After(5 * time.Second):
+			t.Fatal("the call in flight never returned")
+		}
+	})
+}
+
+func TestClientExecuteToolValidatesArguments(t *testing.T) {
+	ctx := context.Background()
+
+	client := setupServedClient(t)
+	require.NoError(t, client.AddServer(server.ServerConfig{Name: "server1", Command: "fake"}))
+
+	t.Run("accepts valid arguments", func(t *testing.T) {
+		_, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{"name": "Ada"})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects a missing required argument", func(t *testing.T) {
+		_, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrInvalidArguments)
+		assert.ErrorContains(t, err, "missing required field: name")
+	})
+
+	t.Run("rejects an argument of the wrong type", func(t *testing.T) {
+		_, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{"name": 42})
+		assert.ErrorIs(t, err, ErrInvalidArguments)
+		assert.ErrorContains(t, err, "invalid argument name")
+	})
+
+	t.Run("can be skipped", func(t *testing.T) {
+		client.SkipArgumentValidation = true
+		defer func() { client.SkipArgumentValidation = false }()
+
+		result, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result.Content[0].(protocol.TextContent).Text)
+	})
+}
+
+func TestClientExecuteToolDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	client := setupServedClient(t)
+	require.NoError(t, client.AddServer(server.ServerConfig{Name: "server1", Command: "fake"}))
+
+	t.Run("describes the call without making it", func(t *testing.T) {
+		// The server never answers "hang", so a real call would block.
+		result, err := client.ExecuteTool(ctx, "server1/hang", map[string]interface{}{"n": 1}, DryRun())
+		require.NoError(t, err)
+
+		assert.Equal(t, `Dry run: would call tool hang on server server1 with arguments {"n":1}`, result.Text())
+		assert.Equal(t, map[string]interface{}{
+			"dryRun":    true,
+			"server":    "server1",
+			"tool":      "hang",
+			"arguments": map[string]interface{}{"n": 1},
+		}, result.Meta)
+	})
+
+	t.Run("redacts sensitive arguments", func(t *testing.T) {
+		result, err := client.ExecuteTool(ctx, "login", map[string]interface{}{"password": "hunter2"}, DryRun())
+		require.NoError(t, err)
+
+		assert.NotContains(t, result.Text(), "hunter2")
+		assert.Equal(t, map[string]interface{}{"password": protocol.RedactedValue}, result.Meta["arguments"])
+	})
+
+	t.Run("still runs every check", func(t *testing.T) {
+		_, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{}, DryRun())
+		assert.ErrorIs(t, err, ErrInvalidArguments)
+
+		_, err = client.ExecuteTool(ctx, "missing", nil, DryRun())
+		assert.ErrorIs(t, err, ErrToolNotFound)
+
+		srv, err := client.GetServer("server1")
+		require.NoError(t, err)
+		srv.Client.Disconnect()
+
+		_, err = client.ExecuteTool(ctx, "echo", nil, DryRun())
+		assert.ErrorContains(t, err, "not running")
+	})
+}
+
+func TestClientAuditHook(t *testing.T) {
+	ctx := context.Background()
+
+	client := setupServedClient(t)
+	require.NoError(t, client.AddServer(server.ServerConfig{Name: "server1", Command: "fake"}))
+
+	var events []AuditEvent
+	client.AuditHook = func(event AuditEvent) { events = append(events, event) }
+
+	t.Run("records a successful call", func(t *testing.T) {
+		events = nil
+		args := map[string]interface{}{"name": "Ada"}
+
+		_, err := client.ExecuteTool(ctx, "greet", args)
+		require.NoError(t, err)
+
+		require.Len(t, events, 1)
+		event := events[0]
+		assert.Equal(t, "greet", event.Tool)
+		assert.Equal(t, "server1", event.Server)
+		assert.Equal(t, args, event.Arguments)
+		assert.False(t, event.IsError)
+		assert.NoError(t, event.Err)
+		assert.False(t, event.Start.IsZero())
+
+		event.Arguments["name"] = "Grace"
+		assert.Equal(t, "Ada", args["name"], "the event holds a copy of the arguments")
+	})
+
+	t.Run("records a failed call", func(t *testing.T) {
+		events = nil
+		callCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.ExecuteTool(callCtx, "hang", nil)
+		require.Error(t, err)
+
+		require.Len(t, events, 1)
+		event := events[0]
+		assert.Equal(t, "hang", event.Tool)
+		assert.True(t, event.IsError)
+		assert.ErrorIs(t, event.Err, context.DeadlineExceeded)
+		assert.GreaterOrEqual(t, event.Duration, 20*time.Millisecond)
+	})
+
+	t.Run("redacts sensitive arguments", func(t *testing.T) {
+		events = nil
+		args := map[string]interface{}{"user": "ada", "password": "hunter2"}
+
+		_, err := client.ExecuteTool(ctx, "login", args)
+		require.NoError(t, err)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, map[string]interface{}{"user": "ada", "password": protocol.RedactedValue}, events[0].Arguments)
+		assert.Equal(t, "hunter2", args["password"], "the call still sends the secret")
+	})
+
+	t.Run("skips calls that are never sent", func(t *testing.T) {
+		events = nil
+
+		_, err := client.ExecuteTool(ctx, "greet", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrInvalidArguments)
+
+		_, err = client.ExecuteTool(ctx, "echo", nil, DryRun())
+		require.NoError(t, err)
+
+		assert.Empty(t, events)
+	})
+}
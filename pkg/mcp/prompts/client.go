@@ -0,0 +1,94 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+var ErrPromptsNotSupported = protocol.ErrPromptsNotSupported
+
+// Requester is the part of protocol.Client the prompts API needs.
+type Requester interface {
+	Request(ctx context.Context, method string, params map[string]interface{}) (interface{}, error)
+	GetServerCapabilities() *protocol.ServerCapabilities
+}
+
+// Client talks to a server's prompts over an existing protocol connection.
+type Client struct {
+	requester Requester
+}
+
+func NewClient(requester Requester) *Client {
+	return &Client{requester: requester}
+}
+
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	if err := c.checkSupported(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.requester.Request(ctx, "prompts/list", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Prompts []Prompt `json:"prompts"`
+	}
+	if err := decode(result, &list); err != nil {
+		return nil, fmt.Errorf("invalid prompts/list response format: %w", err)
+	}
+
+	return list.Prompts, nil
+}
+
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error) {
+	if err := c.checkSupported(); err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{"name": name}
+	if len(args) > 0 {
+		params["arguments"] = args
+	}
+
+	result, err := c.requester.Request(ctx, "prompts/get", params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Messages are decoded through PromptMessage.UnmarshalJSON, which picks
+	// the concrete content type.
+	var prompt GetPromptResult
+	if err := decode(result, &prompt); err != nil {
+		return nil, fmt.Errorf("invalid prompts/get response format: %w", err)
+	}
+
+	return &prompt, nil
+}
+
+// checkSupported defers to the requester's own capability check when it has
+// one, so that WithCapabilityCheck(false) on a protocol.Client applies here
+// too.
+func (c *Client) checkSupported() error {
+	if checker, ok := c.requester.(interface{ Supports(method string) error }); ok {
+		return checker.Supports("prompts/list")
+	}
+
+	capabilities := c.requester.GetServerCapabilities()
+	if capabilities == nil || capabilities.Prompts == nil {
+		return ErrPromptsNotSupported
+	}
+	return nil
+}
+
+func decode(result interface{}, v interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,98 @@
+package prompts_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go-mcp/pkg/mcp/prompts"
+	"go-mcp/pkg/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRequester struct {
+	capabilities *protocol.ServerCapabilities
+	results      map[string]string
+	lastParams   map[string]interface{}
+}
+
+func (f *fakeRequester) Request(ctx context.Context, method string, params map[string]interface{}) (interface{}, error) {
+	f.lastParams = params
+
+	raw, ok := f.results[method]
+	if !ok {
+		return nil, &protocol.JSONRPCError{Code: protocol.ErrMethodNotFound, Message: "method not found"}
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (f *fakeRequester) GetServerCapabilities() *protocol.ServerCapabilities {
+	return f.capabilities
+}
+
+func TestPromptsClient(t *testing.T) {
+	ctx := context.Background()
+
+	requester := &fakeRequester{
+		capabilities: &protocol.ServerCapabilities{Prompts: &protocol.PromptsCapability{}},
+		results: map[string]string{
+			"prompts/list": `{
+				"prompts": [
+					{"name": "greet", "description": "Say hello", "arguments": [{"name": "who", "required": true}]}
+				]
+			}`,
+			"prompts/get": `{
+				"description": "Say hello",
+				"messages": [
+					{"role": "user", "content": {"type": "text", "text": "Hello, Ada"}},
+					{"role": "assistant", "content": {"type": "image", "data": "aGk=", "mimeType": "image/png"}}
+				]
+			}`,
+		},
+	}
+
+	t.Run("lists prompts", func(t *testing.T) {
+		client := prompts.NewClient(requester)
+
+		list, err := client.ListPrompts(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "greet", list[0].Name)
+		assert.True(t, list[0].Arguments[0].Required)
+	})
+
+	t.Run("gets a prompt with typed content", func(t *testing.T) {
+		client := prompts.NewClient(requester)
+
+		result, err := client.GetPrompt(ctx, "greet", map[string]string{"who": "Ada"})
+		require.NoError(t, err)
+		assert.Equal(t, "greet", requester.lastParams["name"])
+		assert.Equal(t, map[string]string{"who": "Ada"}, requester.lastParams["arguments"])
+
+		require.Len(t, result.Messages, 2)
+		text, ok := result.Messages[0].Content.(protocol.TextContent)
+		require.True(t, ok)
+		assert.Equal(t, "Hello, Ada", text.Text)
+
+		image, ok := result.Messages[1].Content.(protocol.ImageContent)
+		require.True(t, ok)
+		assert.Equal(t, "image/png", image.MimeType)
+	})
+
+	t.Run("requires the prompts capability", func(t *testing.T) {
+		client := prompts.NewClient(&fakeRequester{capabilities: &protocol.ServerCapabilities{}})
+
+		_, err := client.ListPrompts(ctx)
+		assert.ErrorIs(t, err, prompts.ErrPromptsNotSupported)
+
+		_, err = client.GetPrompt(ctx, "greet", nil)
+		assert.ErrorIs(t, err, prompts.ErrPromptsNotSupported)
+	})
+}
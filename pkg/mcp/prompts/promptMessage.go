@@ -3,7 +3,9 @@ package prompts
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"go-mcp/pkg/mcp/protocol"
 )
@@ -13,17 +15,80 @@ type PromptMessage struct {
 	Content protocol.Content `json:"content"`
 }
 
+// TemplateEngine selects how Prompt.Execute renders Template.
+type TemplateEngine string
+
+const (
+	// EnginePlaceholder replaces {name} with the value of argument name. It
+	// is the default.
+	EnginePlaceholder TemplateEngine = "placeholder"
+	// EngineTextTemplate renders Template with text/template, with the
+	// arguments available as {{.name}}.
+	EngineTextTemplate TemplateEngine = "text/template"
+)
+
 type Prompt struct {
 	Name        string           `json:"name"`
 	Description string           `json:"description,omitempty"`
 	Arguments   []PromptArgument `json:"arguments,omitempty"`
 	Template    string
+	Engine      TemplateEngine `json:"-"`
+	// Messages, when set, is rendered by ExecuteMessages instead of Template.
+	// Arguments are substituted into text content only; images and other
+	// content are passed through unchanged.
+	Messages []PromptMessage `json:"-"`
 }
 
+// ArgumentType constrains the string value given for a prompt argument.
+type ArgumentType string
+
+const (
+	ArgumentTypeString  ArgumentType = "string"
+	ArgumentTypeNumber  ArgumentType = "number"
+	ArgumentTypeInteger ArgumentType = "integer"
+	ArgumentTypeBoolean ArgumentType = "boolean"
+)
+
 type PromptArgument struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Required    bool   `json:"required,omitempty"`
+	// Type defaults to a plain string when empty.
+	Type ArgumentType `json:"type,omitempty"`
+	// Enum, when set, lists the only accepted values.
+	Enum []string `json:"enum,omitempty"`
+}
+
+// Validate checks value against the argument's type and enum.
+func (a PromptArgument) Validate(value string) error {
+	switch a.Type {
+	case "", ArgumentTypeString:
+	case ArgumentTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("argument %s: expected a number, got %q", a.Name, value)
+		}
+	case ArgumentTypeInteger:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("argument %s: expected an integer, got %q", a.Name, value)
+		}
+	case ArgumentTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("argument %s: expected a boolean, got %q", a.Name, value)
+		}
+	default:
+		return fmt.Errorf("argument %s: unknown type %s", a.Name, a.Type)
+	}
+
+	if len(a.Enum) > 0 {
+		for _, allowed := range a.Enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("argument %s: value %q not in enum %v", a.Name, value, a.Enum)
+	}
+
+	return nil
 }
 
 type GetPromptRequest struct {
@@ -40,28 +105,110 @@ type GetPromptResult struct {
 }
 
 func (p *Prompt) Execute(args map[string]string) (string, error) {
-	for _, arg := range p.Arguments {
-		if arg.Required {
-			if _, ok := args[arg.Name]; !ok {
-				return "", fmt.Errorf("missing required argument: %s", arg.Name)
+	if err := p.ValidateArguments(args); err != nil {
+		return "", err
+	}
+
+	return p.render(p.Template, args)
+}
+
+// ExecuteMessages renders the prompt as messages. With Messages set, each text
+// part is rendered like Template and every other part is copied as is.
+// Otherwise the result is a single user message holding the rendered
+// Template.
+func (p *Prompt) ExecuteMessages(args map[string]string) ([]PromptMessage, error) {
+	if err := p.ValidateArguments(args); err != nil {
+		return nil, err
+	}
+
+	if len(p.Messages) == 0 {
+		text, err := p.render(p.Template, args)
+		if err != nil {
+			return nil, err
+		}
+		return []PromptMessage{{
+			Role:    protocol.RoleUser,
+			Content: protocol.TextContent{Type: string(protocol.ContentTypeText), Text: text},
+		}}, nil
+	}
+
+	messages := make([]PromptMessage, len(p.Messages))
+	for i, message := range p.Messages {
+		switch content := message.Content.(type) {
+		case protocol.TextContent:
+			text, err := p.render(content.Text, args)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+			content.Text = text
+			message.Content = content
+		case *protocol.TextContent:
+			if content != nil {
+				text, err := p.render(content.Text, args)
+				if err != nil {
+					return nil, fmt.Errorf("message %d: %w", i, err)
+				}
+				rendered := *content
+				rendered.Text = text
+				message.Content = rendered
 			}
 		}
+		messages[i] = message
 	}
 
-	result := p.Template
-	for name, value := range args {
-		result = strings.ReplaceAll(result, "{"+name+"}", value)
+	return messages, nil
+}
+
+// render substitutes args into text with the prompt's engine.
+func (p *Prompt) render(text string, args map[string]string) (string, error) {
+	switch p.Engine {
+	case "", EnginePlaceholder:
+		result := text
+		for name, value := range args {
+			result = strings.ReplaceAll(result, "{"+name+"}", value)
+		}
+		return result, nil
+	case EngineTextTemplate:
+		return p.executeTextTemplate(text, args)
+	default:
+		return "", fmt.Errorf("unknown template engine: %s", p.Engine)
 	}
+}
 
-	return result, nil
+// executeTextTemplate renders text with text/template. Declared arguments
+// that were not provided render as empty strings; referencing an argument the
+// prompt does not declare is an error.
+func (p *Prompt) executeTextTemplate(text string, args map[string]string) (string, error) {
+	tmpl, err := template.New(p.Name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template for prompt %s: %w", p.Name, err)
+	}
+
+	data := make(map[string]string, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		data[arg.Name] = args[arg.Name]
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", p.Name, err)
+	}
+
+	return out.String(), nil
 }
 
 func (p *Prompt) ValidateArguments(args map[string]string) error {
 	for _, arg := range p.Arguments {
-		if arg.Required {
-			if _, ok := args[arg.Name]; !ok {
+		value, ok := args[arg.Name]
+		if !ok {
+			if arg.Required {
 				return fmt.Errorf("missing required argument: %s", arg.Name)
 			}
+			continue
+		}
+
+		if err := arg.Validate(value); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -103,6 +250,12 @@ func (pm *PromptMessage) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		pm.Content = imageContent
+	case protocol.ContentTypeAudio:
+		var audioContent protocol.AudioContent
+		if err := json.Unmarshal(aux.Content, &audioContent); err != nil {
+			return err
+		}
+		pm.Content = audioContent
 	case protocol.ContentTypeResource:
 		var resourceContent protocol.EmbeddedResource
 		if err := json.Unmarshal(aux.Content, &resourceContent); err != nil {
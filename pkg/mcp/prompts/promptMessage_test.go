@@ -85,6 +85,32 @@ func TestPromptMessage(t *testing.T) {
 }
 
 func TestPrompt(t *testing.T) {
+	t.Run("round-trips audio content", func(t *testing.T) {
+		msg := prompts.PromptMessage{
+			Role: protocol.RoleAssistant,
+			Content: protocol.AudioContent{
+				Type:     protocol.ContentTypeAudio,
+				Data:     "UklGRg==",
+				MimeType: "audio/wav",
+			},
+		}
+
+		data, err := json.Marshal(msg)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"role": "assistant",
+			"content": {
+				"type": "audio",
+				"data": "UklGRg==",
+				"mimeType": "audio/wav"
+			}
+		}`, string(data))
+
+		var decoded prompts.PromptMessage
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, msg, decoded)
+	})
+
 	t.Run("validates required arguments", func(t *testing.T) {
 		prompt := prompts.Prompt{
 			Name: "greet",
@@ -123,6 +149,115 @@ func TestPrompt(t *testing.T) {
 	})
 }
 
+func TestTypedArguments(t *testing.T) {
+	prompt := prompts.Prompt{
+		Name: "summarize",
+		Arguments: []prompts.PromptArgument{
+			{Name: "text", Required: true},
+			{Name: "words", Type: prompts.ArgumentTypeInteger},
+			{Name: "temperature", Type: prompts.ArgumentTypeNumber},
+			{Name: "bullets", Type: prompts.ArgumentTypeBoolean},
+			{Name: "tone", Enum: []string{"formal", "casual"}},
+		},
+		Template: "Summarize in {words} words: {text}",
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		wantErr string
+	}{
+		{"valid values", map[string]string{"text": "x", "words": "50", "temperature": "0.7", "bullets": "true", "tone": "formal"}, ""},
+		{"optional arguments may be omitted", map[string]string{"text": "x"}, ""},
+		{"untyped arguments accept anything", map[string]string{"text": "42"}, ""},
+		{"non-numeric number", map[string]string{"text": "x", "temperature": "warm"}, `argument temperature: expected a number, got "warm"`},
+		{"fractional integer", map[string]string{"text": "x", "words": "2.5"}, `argument words: expected an integer, got "2.5"`},
+		{"invalid boolean", map[string]string{"text": "x", "bullets": "maybe"}, `argument bullets: expected a boolean, got "maybe"`},
+		{"value outside enum", map[string]string{"text": "x", "tone": "angry"}, `argument tone: value "angry" not in enum [formal casual]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := prompt.ValidateArguments(tt.args)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Execute validates types", func(t *testing.T) {
+		_, err := prompt.Execute(map[string]string{"text": "x", "words": "many"})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown types are rejected", func(t *testing.T) {
+		arg := prompts.PromptArgument{Name: "when", Type: "date"}
+		assert.EqualError(t, arg.Validate("today"), "argument when: unknown type date")
+	})
+}
+
+func TestTextTemplateEngine(t *testing.T) {
+	prompt := prompts.Prompt{
+		Name: "review",
+		Arguments: []prompts.PromptArgument{
+			{Name: "language", Required: true},
+			{Name: "focus"},
+		},
+		Template: "Review this {{.language}} code.{{if .focus}} Focus on {{.focus}}.{{end}}",
+		Engine:   prompts.EngineTextTemplate,
+	}
+
+	t.Run("renders conditionals", func(t *testing.T) {
+		result, err := prompt.Execute(map[string]string{"language": "Go", "focus": "errors"})
+		require.NoError(t, err)
+		assert.Equal(t, "Review this Go code. Focus on errors.", result)
+
+		result, err = prompt.Execute(map[string]string{"language": "Go"})
+		require.NoError(t, err)
+		assert.Equal(t, "Review this Go code.", result)
+	})
+
+	t.Run("still checks required arguments", func(t *testing.T) {
+		_, err := prompt.Execute(map[string]string{"focus": "errors"})
+		assert.ErrorContains(t, err, "missing required argument: language")
+	})
+
+	t.Run("rejects undeclared arguments", func(t *testing.T) {
+		broken := prompt
+		broken.Template = "Hello {{.who}}"
+
+		_, err := broken.Execute(map[string]string{"language": "Go", "who": "Ada"})
+		assert.ErrorContains(t, err, "who")
+	})
+
+	t.Run("does not substitute overlapping names", func(t *testing.T) {
+		overlapping := prompts.Prompt{
+			Name:      "overlap",
+			Arguments: []prompts.PromptArgument{{Name: "a"}, {Name: "ab"}},
+			Template:  "{{.a}}-{{.ab}}",
+			Engine:    prompts.EngineTextTemplate,
+		}
+
+		result, err := overlapping.Execute(map[string]string{"a": "1", "ab": "2"})
+		require.NoError(t, err)
+		assert.Equal(t, "1-2", result)
+	})
+
+	t.Run("reports parse errors and unknown engines", func(t *testing.T) {
+		broken := prompt
+		broken.Template = "{{if}}"
+		_, err := broken.Execute(map[string]string{"language": "Go"})
+		assert.ErrorContains(t, err, "invalid template")
+
+		broken = prompt
+		broken.Engine = "mustache"
+		_, err = broken.Execute(map[string]string{"language": "Go"})
+		assert.ErrorContains(t, err, "unknown template engine")
+	})
+}
+
 func TestMessageLifecycle(t *testing.T) {
 	t.Run("full prompt execution flow", func(t *testing.T) {
 
@@ -168,3 +303,71 @@ func TestMessageLifecycle(t *testing.T) {
 			decoded.Content.(protocol.TextContent).Text)
 	})
 }
+
+func TestExecuteMessages(t *testing.T) {
+	image := protocol.ImageContent{Type: protocol.ContentTypeImage, Data: "e25hbWV9", MimeType: "image/png"}
+	prompt := &prompts.Prompt{
+		Name:      "describe",
+		Arguments: []prompts.PromptArgument{{Name: "name", Required: true}},
+		Messages: []prompts.PromptMessage{
+			{Role: protocol.RoleUser, Content: protocol.TextContent{Type: "text", Text: "Describe {name}:"}},
+			{Role: protocol.RoleUser, Content: image},
+			{Role: protocol.RoleAssistant, Content: &protocol.TextContent{Type: "text", Text: "{name} shows"}},
+		},
+	}
+
+	t.Run("substitutes into text parts only", func(t *testing.T) {
+		messages, err := prompt.ExecuteMessages(map[string]string{"name": "the chart"})
+		require.NoError(t, err)
+		require.Len(t, messages, 3)
+
+		assert.Equal(t, "Describe the chart:", messages[0].Content.(protocol.TextContent).Text)
+		assert.Equal(t, image, messages[1].Content)
+		assert.Equal(t, protocol.RoleAssistant, messages[2].Role)
+		assert.Equal(t, "the chart shows", messages[2].Content.(protocol.TextContent).Text)
+
+		// The prompt itself is left untouched for the next execution.
+		assert.Equal(t, "Describe {name}:", prompt.Messages[0].Content.(protocol.TextContent).Text)
+		assert.Equal(t, "{name} shows", prompt.Messages[2].Content.(*protocol.TextContent).Text)
+	})
+
+	t.Run("validates arguments", func(t *testing.T) {
+		_, err := prompt.ExecuteMessages(map[string]string{})
+		assert.ErrorContains(t, err, "missing required argument: name")
+	})
+
+	t.Run("uses the prompt's engine", func(t *testing.T) {
+		templated := *prompt
+		templated.Engine = prompts.EngineTextTemplate
+		templated.Messages = []prompts.PromptMessage{
+			{Role: protocol.RoleUser, Content: protocol.TextContent{Type: "text", Text: "Describe {{.name}}"}},
+		}
+
+		messages, err := templated.ExecuteMessages(map[string]string{"name": "the chart"})
+		require.NoError(t, err)
+		assert.Equal(t, "Describe the chart", messages[0].Content.(protocol.TextContent).Text)
+	})
+
+	t.Run("falls back to Template", func(t *testing.T) {
+		plain := &prompts.Prompt{Name: "hello", Template: "Hello {name}"}
+
+		messages, err := plain.ExecuteMessages(map[string]string{"name": "Ada"})
+		require.NoError(t, err)
+		assert.Equal(t, []prompts.PromptMessage{{
+			Role:    protocol.RoleUser,
+			Content: protocol.TextContent{Type: "text", Text: "Hello Ada"},
+		}}, messages)
+	})
+
+	t.Run("survives a JSON round trip", func(t *testing.T) {
+		messages, err := prompt.ExecuteMessages(map[string]string{"name": "x"})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(prompts.GetPromptResult{Messages: messages})
+		require.NoError(t, err)
+
+		var decoded prompts.GetPromptResult
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, messages, decoded.Messages)
+	})
+}
@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// BindArguments decodes tool arguments into dest, a pointer to a struct with
+// json tags, so handlers can work with typed fields instead of asserting each
+// value. Arguments without a matching field are ignored. A value of the wrong
+// type is reported along with the argument it belongs to:
+//
+//	var in struct {
+//		Path  string `json:"path"`
+//		Count int    `json:"count"`
+//	}
+//	if err := protocol.BindArguments(args, &in); err != nil {
+//		return nil, err
+//	}
+func BindArguments(args map[string]interface{}, dest interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return fmt.Errorf("invalid argument %s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	return nil
+}
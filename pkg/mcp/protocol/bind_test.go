@@ -0,0 +1,54 @@
+package protocol_test
+
+import (
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindArguments(t *testing.T) {
+	type options struct {
+		Depth int `json:"depth"`
+	}
+	type input struct {
+		Path    string   `json:"path"`
+		Count   int      `json:"count"`
+		Tags    []string `json:"tags"`
+		Options options  `json:"options"`
+		Force   *bool    `json:"force,omitempty"`
+	}
+
+	t.Run("fills typed fields", func(t *testing.T) {
+		var in input
+		err := protocol.BindArguments(map[string]interface{}{
+			"path":    "/tmp/a",
+			"count":   3.0,
+			"tags":    []interface{}{"x", "y"},
+			"options": map[string]interface{}{"depth": 2},
+			"unknown": true,
+		}, &in)
+		require.NoError(t, err)
+		assert.Equal(t, input{Path: "/tmp/a", Count: 3, Tags: []string{"x", "y"}, Options: options{Depth: 2}}, in)
+	})
+
+	t.Run("accepts no arguments", func(t *testing.T) {
+		var in input
+		require.NoError(t, protocol.BindArguments(nil, &in))
+		assert.Equal(t, input{}, in)
+	})
+
+	t.Run("names the mismatched argument", func(t *testing.T) {
+		var in input
+		err := protocol.BindArguments(map[string]interface{}{"count": "three"}, &in)
+		assert.EqualError(t, err, "invalid argument count: expected int, got string")
+
+		err = protocol.BindArguments(map[string]interface{}{"options": map[string]interface{}{"depth": 1.5}}, &in)
+		assert.EqualError(t, err, "invalid argument options.depth: expected int, got number 1.5")
+	})
+
+	t.Run("rejects a non-pointer destination", func(t *testing.T) {
+		assert.Error(t, protocol.BindArguments(map[string]interface{}{}, input{}))
+	})
+}
@@ -0,0 +1,97 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCapabilityNotSupported is wrapped by the errors returned for methods the
+// server did not advertise the capability for.
+var ErrCapabilityNotSupported = errors.New("server does not support capability")
+
+var (
+	ErrToolsNotSupported         = fmt.Errorf("%w: tools", ErrCapabilityNotSupported)
+	ErrResourcesNotSupported     = fmt.Errorf("%w: resources", ErrCapabilityNotSupported)
+	ErrSubscriptionsNotSupported = fmt.Errorf("%w: resources.subscribe", ErrCapabilityNotSupported)
+	ErrPromptsNotSupported       = fmt.Errorf("%w: prompts", ErrCapabilityNotSupported)
+	ErrLoggingNotSupported       = fmt.Errorf("%w: logging", ErrCapabilityNotSupported)
+	ErrCompletionsNotSupported   = fmt.Errorf("%w: completions", ErrCapabilityNotSupported)
+)
+
+// WithCapabilityCheck turns the check made by Supports before every request
+// on or off. It is on by default; turn it off for servers that support more
+// than they advertise.
+func WithCapabilityCheck(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.skipCapabilityCheck = !enabled
+	}
+}
+
+type capabilityRequirement struct {
+	supported func(capabilities *ServerCapabilities) bool
+	err       error
+}
+
+var (
+	requiresTools = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Tools != nil },
+		err:       ErrToolsNotSupported,
+	}
+	requiresResources = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Resources != nil },
+		err:       ErrResourcesNotSupported,
+	}
+	requiresSubscriptions = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Resources != nil && c.Resources.Subscribe },
+		err:       ErrSubscriptionsNotSupported,
+	}
+	requiresPrompts = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Prompts != nil },
+		err:       ErrPromptsNotSupported,
+	}
+	requiresLogging = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Logging != nil },
+		err:       ErrLoggingNotSupported,
+	}
+	requiresCompletions = capabilityRequirement{
+		supported: func(c *ServerCapabilities) bool { return c.Completions != nil },
+		err:       ErrCompletionsNotSupported,
+	}
+)
+
+// methodCapabilities maps the methods tied to a server capability to it.
+var methodCapabilities = map[string]capabilityRequirement{
+	"mcp.list_tools":           requiresTools,
+	"tools/list":               requiresTools,
+	"mcp.list_resources":       requiresResources,
+	"resources/list":           requiresResources,
+	"resources/templates/list": requiresResources,
+	"resources/read":           requiresResources,
+	"resources/subscribe":      requiresSubscriptions,
+	"resources/unsubscribe":    requiresSubscriptions,
+	"prompts/list":             requiresPrompts,
+	"prompts/get":              requiresPrompts,
+	"logging/setLevel":         requiresLogging,
+	"completion/complete":      requiresCompletions,
+}
+
+// Supports reports whether the server advertised the capability method
+// needs, returning one of the errors wrapping ErrCapabilityNotSupported if it
+// did not. Methods not tied to a capability are always supported, as is
+// everything before the handshake or with WithCapabilityCheck(false).
+func (c *Client) Supports(method string) error {
+	requirement, exists := methodCapabilities[method]
+	if !exists {
+		return nil
+	}
+
+	c.mutex.RLock()
+	capabilities, skip := c.capabilities, c.skipCapabilityCheck
+	c.mutex.RUnlock()
+
+	if skip || capabilities == nil || requirement.supported(capabilities) {
+		return nil
+	}
+
+	return requirement.err
+}
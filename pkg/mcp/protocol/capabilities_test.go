@@ -0,0 +1,157 @@
+package protocol_test
+
+import (
+	"context"
+	"go-mcp/pkg/mcp/prompts"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCapabilityCheck(t *testing.T) {
+	ctx := context.Background()
+
+	// connect returns a client of a server advertising capabilities, along
+	// with the methods the server has received since the handshake.
+	connect := func(t *testing.T, capabilities map[string]interface{}, opts ...protocol.ClientOption) (*protocol.Client, func() []string) {
+		var mutex sync.Mutex
+		var received []string
+		connected := false
+
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch req.Method {
+			case "initialize":
+				result := initializeResult(req)
+				result["capabilities"] = capabilities
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, result)}
+			case "notifications/initialized":
+				return nil
+			}
+
+			if connected {
+				received = append(received, req.Method)
+			}
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{
+				"tools": []interface{}{}, "resources": []interface{}{}, "resourceTemplates": []interface{}{},
+			})}
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"}, opts...)
+		require.NoError(t, client.Connect(transport))
+		t.Cleanup(func() { client.Disconnect() })
+
+		mutex.Lock()
+		connected = true
+		mutex.Unlock()
+
+		return client, func() []string {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return append([]string(nil), received...)
+		}
+	}
+
+	cases := []struct {
+		name       string
+		capability map[string]interface{}
+		call       func(client *protocol.Client) error
+		want       error
+	}{
+		{
+			name:       "tools",
+			capability: map[string]interface{}{"tools": map[string]interface{}{}},
+			call: func(client *protocol.Client) error {
+				_, err := client.ListTools(ctx)
+				return err
+			},
+			want: protocol.ErrToolsNotSupported,
+		},
+		{
+			name:       "resources",
+			capability: map[string]interface{}{"resources": map[string]interface{}{}},
+			call: func(client *protocol.Client) error {
+				_, err := client.ListResourceTemplates(ctx)
+				return err
+			},
+			want: protocol.ErrResourcesNotSupported,
+		},
+		{
+			name:       "resource subscriptions",
+			capability: map[string]interface{}{"resources": map[string]interface{}{"subscribe": true}},
+			call: func(client *protocol.Client) error {
+				_, err := client.Request(ctx, "resources/subscribe", map[string]interface{}{"uri": "file:///a"})
+				return err
+			},
+			want: protocol.ErrSubscriptionsNotSupported,
+		},
+		{
+			name:       "prompts",
+			capability: map[string]interface{}{"prompts": map[string]interface{}{}},
+			call: func(client *protocol.Client) error {
+				_, err := prompts.NewClient(client).GetPrompt(ctx, "greet", nil)
+				return err
+			},
+			want: protocol.ErrPromptsNotSupported,
+		},
+		{
+			name:       "logging",
+			capability: map[string]interface{}{"logging": map[string]interface{}{}},
+			call: func(client *protocol.Client) error {
+				return client.SetLogLevel(ctx, protocol.LoggingLevelInfo)
+			},
+			want: protocol.ErrLoggingNotSupported,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("fails without sending when not advertised", func(t *testing.T) {
+				client, received := connect(t, map[string]interface{}{})
+
+				err := tc.call(client)
+				assert.ErrorIs(t, err, tc.want)
+				assert.ErrorIs(t, err, protocol.ErrCapabilityNotSupported)
+				assert.Empty(t, received())
+			})
+
+			t.Run("is sent when advertised", func(t *testing.T) {
+				client, received := connect(t, tc.capability)
+
+				tc.call(client)
+				assert.Len(t, received(), 1)
+			})
+
+			t.Run("is sent when the check is off", func(t *testing.T) {
+				client, received := connect(t, map[string]interface{}{}, protocol.WithCapabilityCheck(false))
+
+				err := tc.call(client)
+				assert.NotErrorIs(t, err, protocol.ErrCapabilityNotSupported)
+				assert.Len(t, received(), 1)
+			})
+		})
+	}
+
+	t.Run("methods without a capability are not checked", func(t *testing.T) {
+		client, received := connect(t, map[string]interface{}{})
+
+		_, err := client.CallTool(ctx, "echo", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo"}, received())
+	})
+
+	t.Run("tools named like a capability method are not checked", func(t *testing.T) {
+		client, received := connect(t, map[string]interface{}{"tools": map[string]interface{}{}})
+
+		_, err := client.CallTool(ctx, "resources/read", nil)
+		require.NoError(t, err)
+		_, err = client.CallTool(ctx, "prompts/get", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"resources/read", "prompts/get"}, received())
+	})
+}
@@ -6,18 +6,29 @@ import (
 	"fmt"
 	"sync"
 	"time"
+)
 
-	"github.com/google/uuid"
+var (
+	// ErrNotConnected is returned by requests made before Connect or after
+	// Disconnect.
+	ErrNotConnected = errors.New("client not connected")
+	// ErrTimeout is returned when a request gets no response before its
+	// deadline. Such errors also match context.DeadlineExceeded.
+	ErrTimeout = errors.New("request timed out")
 )
 
 type MCPClient interface {
 	Connect(transport Transport) error
 
+	ConnectWithContext(ctx context.Context, transport Transport) error
+
 	ListTools(ctx context.Context) ([]Tool, error)
 
 	ListResources(ctx context.Context) ([]Resource, error)
 
-	CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error)
+	ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error)
+
+	CallTool(ctx context.Context, name string, params map[string]interface{}, opts ...CallOption) (interface{}, error)
 
 	GetServerCapabilities() *ServerCapabilities
 
@@ -30,154 +41,371 @@ type MCPClient interface {
 
 type Client struct {
 	transport       Transport
+	dispatcher      *dispatcher
 	clientInfo      ClientInfo
 	capabilities    *ServerCapabilities
 	mutex           sync.RWMutex
 	protocolVersion string
+	notifications   *notificationRouter
+	timeout         time.Duration
+	retryAttempts   int
+	retryBackoff    BackoffFunc
+	logger          Logger
+	roots           []Root
+	ids             IDGenerator
+	resources       *resourceCache
+
+	skipCapabilityCheck bool
+
+	// Reconnect state: the transport of the last successful Start, the
+	// optional factory replacing it, and a count of completed connects.
+	lastTransport    Transport
+	transportFactory func() (Transport, error)
+	connections      uint64
+	reconnectMutex   sync.Mutex
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithDefaultTimeout bounds every request whose context has no deadline of its
+// own. A deadline set on the context always takes precedence, whether it is
+// shorter or longer than d. It also replaces the 10s limit used for the
+// handshake and capability discovery in Connect.
+func WithDefaultTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
 }
 
-func NewClient(clientInfo ClientInfo) *Client {
-	return &Client{
+func NewClient(clientInfo ClientInfo, opts ...ClientOption) *Client {
+	c := &Client{
 		clientInfo:      clientInfo,
-		protocolVersion: "1.0",
+		protocolVersion: LatestProtocolVersion,
+		notifications:   newNotificationRouter(),
+		logger:          NopLogger{},
+		ids:             NewUUIDGenerator(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// withTimeout applies the configured default timeout to ctx unless it already
+// carries a deadline.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, c.timeout)
 }
 
+// withConnectTimeout bounds the handshake and discovery done by Connect
+// unless ctx already has a deadline.
+func (c *Client) withConnectTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+
+	timeout := defaultTimeout
+	if c.timeout > 0 {
+		timeout = c.timeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Connect starts transport and performs the MCP handshake, bounded by the
+// default connect timeout.
 func (c *Client) Connect(transport Transport) error {
+	return c.ConnectWithContext(context.Background(), transport)
+}
+
+// ConnectWithContext is like Connect, but the handshake and initial discovery
+// are abandoned, and the transport closed, when ctx is done. Without a
+// deadline on ctx the default connect timeout still applies.
+func (c *Client) ConnectWithContext(ctx context.Context, transport Transport) error {
+	// Starting a server process only to kill it again is wasted work.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
 
 	if c.transport != nil && c.transport.IsConnected() {
+		c.mutex.Unlock()
 		return errors.New("client already connected")
 	}
 
 	if err := transport.Start(); err != nil {
+		c.mutex.Unlock()
 		return fmt.Errorf("failed to start transport: %w", err)
 	}
 
 	c.transport = transport
+	c.lastTransport = transport
+	c.dispatcher = newDispatcher(transport, c.logger, c.notifications.dispatch, func(ctx context.Context, request *JSONRPCResponse) {
+		c.answerRequest(ctx, transport, request)
+	})
+	c.mutex.Unlock()
+
+	// The lock is released before talking to the server so that the request
+	// helpers below can take it themselves.
+	ctx, cancel := c.withConnectTimeout(ctx)
+	defer cancel()
 
-	if err := c.performHandshake(); err != nil {
-		c.transport.Close()
-		c.transport = nil
+	if err := c.performHandshake(ctx); err != nil {
+		c.closeTransport()
 		return err
 	}
 
-	c.capabilities = &ServerCapabilities{
-		Tools:     &ToolsCapability{ListChanged: true},
-		Resources: &ResourcesCapability{ListChanged: true},
+	if err := c.discoverCapabilities(ctx); err != nil {
+		c.closeTransport()
+		return err
 	}
 
-	if err := c.discoverCapabilities(); err != nil {
-		c.transport.Close()
-		c.transport = nil
-		return err
+	c.mutex.Lock()
+	c.connections++
+	c.mutex.Unlock()
+
+	// Resources may have changed while the client was away.
+	if c.resources != nil {
+		c.resources.clear()
 	}
 
 	return nil
 }
 
-func (c *Client) performHandshake() error {
-	handshakeParams := map[string]interface{}{
-		"version": c.protocolVersion,
-		"client": map[string]interface{}{
-			"name":    c.clientInfo.Name,
-			"version": c.clientInfo.Version,
-		},
-	}
+func (c *Client) closeTransport() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	requestID := uuid.New().String()
-	request := NewRequest(requestID, "mcp.handshake", handshakeParams)
+	if c.transport != nil {
+		c.transport.Close()
+	}
+	c.transport = nil
+	c.dispatcher = nil
+}
 
-	if err := c.transport.Send(request); err != nil {
-		return fmt.Errorf("handshake request failed: %w", err)
+// Request sends an arbitrary method and returns its decoded result. A JSON-RPC
+// error response is returned as a *JSONRPCError.
+func (c *Client) Request(ctx context.Context, method string, params map[string]interface{}) (interface{}, error) {
+	if err := c.Supports(method); err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
 	}
 
-	response, err := c.transport.Receive()
+	response, err := c.call(ctx, method, params)
 	if err != nil {
-		return fmt.Errorf("handshake response failed: %w", err)
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
 	}
 
 	if response.Error != nil {
-		return fmt.Errorf("handshake error: %s (code: %d)",
-			response.Error.Message, response.Error.Code)
+		return nil, response.Error
 	}
 
-	result, ok := response.Result.(map[string]interface{})
-	if !ok {
-		return errors.New("invalid handshake response format")
-	}
+	return response.Result, nil
+}
 
-	version, ok := result["version"].(string)
-	if !ok {
-		return errors.New("missing protocol version in handshake response")
+// Notify sends a notification. Servers never answer notifications, so it
+// returns as soon as the message has been written.
+func (c *Client) Notify(ctx context.Context, method string, params map[string]interface{}) error {
+	c.mutex.RLock()
+	transport := c.transport
+	c.mutex.RUnlock()
+
+	if transport == nil || !transport.IsConnected() {
+		return ErrNotConnected
 	}
 
-	if version != c.protocolVersion {
-		return fmt.Errorf("incompatible protocol version: got %s, expected %s",
-			version, c.protocolVersion)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := transport.SendWithContext(ctx, NewNotification(method, params)); err != nil {
+		return fmt.Errorf("%s notification failed: %w", method, err)
 	}
 
 	return nil
 }
 
-func (c *Client) discoverCapabilities() error {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+// call sends a request and waits for the response carrying the same ID. It is
+// safe for concurrent use; ctx bounds how long the caller waits. It does not
+// check the server's capabilities, since tool calls use the tool name as the
+// method; the methods sending a fixed method check it with Supports first.
+func (c *Client) call(ctx context.Context, method string, params map[string]interface{}) (*JSONRPCResponse, error) {
+	return c.callStream(ctx, method, params, nil)
+}
+
+// callStream is call for a request the server may answer in several frames;
+// see dispatcher.CallStream.
+func (c *Client) callStream(ctx context.Context, method string, params map[string]interface{}, onPartial func(partial *JSONRPCResponse)) (*JSONRPCResponse, error) {
+	c.mutex.RLock()
+	transport := c.transport
+	dispatcher := c.dispatcher
+	c.mutex.RUnlock()
+
+	if transport == nil || dispatcher == nil || !transport.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	request := NewRequest(c.ids.NextID(), method, params)
+
+	ctx, cancel := c.withTimeout(ctx)
 	defer cancel()
 
-	_, err := c.ListTools(ctx)
+	response, err := dispatcher.CallStream(ctx, request, onPartial)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return response, err
+}
+
+// clientCapabilities describes what this client offers the server, based on
+// the handlers registered so far.
+func (c *Client) clientCapabilities() ClientCapabilities {
+	var capabilities ClientCapabilities
+
+	if _, exists := c.notifications.requestHandler("sampling/createMessage"); exists {
+		capabilities.Sampling = &struct{}{}
+	}
+
+	if _, exists := c.notifications.requestHandler("roots/list"); exists {
+		capabilities.Roots = &RootsCapability{ListChanged: true}
+	}
+
+	if _, exists := c.notifications.requestHandler("elicitation/create"); exists {
+		capabilities.Elicitation = &struct{}{}
+	}
+
+	return capabilities
+}
+
+func (c *Client) performHandshake(ctx context.Context) error {
+	initParams, err := toParams(InitializeParams{
+		ProtocolVersion: c.protocolVersion,
+		Capabilities:    c.clientCapabilities(),
+		ClientInfo: Implementation{
+			Name:    c.clientInfo.Name,
+			Version: c.clientInfo.Version,
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to discover tools: %w", err)
+		return fmt.Errorf("failed to encode initialize params: %w", err)
 	}
 
-	_, err = c.ListResources(ctx)
+	response, err := c.call(ctx, "initialize", initParams)
 	if err != nil {
-		fmt.Printf("Warning: failed to discover resources: %v\n", err)
+		return fmt.Errorf("initialize request failed: %w", err)
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("initialize error: %w", response.Error)
+	}
+
+	var result InitializeResult
+	if err := decodeResult(response.Result, &result); err != nil {
+		return fmt.Errorf("invalid initialize response format: %w", err)
 	}
 
+	if result.ProtocolVersion == "" {
+		return errors.New("missing protocol version in initialize response")
+	}
+
+	if !isSupportedProtocolVersion(result.ProtocolVersion) {
+		return fmt.Errorf("incompatible protocol version: got %s, expected one of %v",
+			result.ProtocolVersion, SupportedProtocolVersions)
+	}
+
+	c.logger.Debug("initialized", "server", result.ServerInfo.Name, "protocolVersion", result.ProtocolVersion)
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.capabilities = &result.Capabilities
+	c.mutex.Unlock()
 
-	c.capabilities.Tools = &ToolsCapability{ListChanged: true}
-	c.capabilities.Resources = &ResourcesCapability{ListChanged: true}
+	if err := c.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+func isSupportedProtocolVersion(version string) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) discoverCapabilities(ctx context.Context) error {
 	c.mutex.RLock()
-	transport := c.transport
+	capabilities := c.capabilities
 	c.mutex.RUnlock()
 
-	if transport == nil || !transport.IsConnected() {
-		return nil, errors.New("client not connected")
+	if capabilities == nil {
+		return nil
 	}
 
-	requestID := uuid.New().String()
-	request := NewRequest(requestID, "mcp.list_tools", map[string]interface{}{})
+	if capabilities.Tools != nil {
+		if _, err := c.ListTools(ctx); err != nil {
+			return fmt.Errorf("failed to discover tools: %w", err)
+		}
+	}
 
-	if err := transport.SendWithContext(ctx, request); err != nil {
-		return nil, fmt.Errorf("list_tools request failed: %w", err)
+	if capabilities.Resources != nil {
+		if _, err := c.ListResources(ctx); err != nil {
+			c.logger.Warn("failed to discover resources", "error", err)
+		}
 	}
 
-	response, err := transport.Receive()
+	return nil
+}
+
+// ListTools returns the first page of tools. Use ListAllTools to follow
+// pagination cursors.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	tools, _, err := c.ListToolsPage(ctx, "")
+	return tools, err
+}
+
+// ListAllTools follows nextCursor until the server reports no further pages.
+func (c *Client) ListAllTools(ctx context.Context) ([]Tool, error) {
+	var all []Tool
+
+	err := paginate(func(cursor Cursor) (Cursor, error) {
+		tools, next, err := c.ListToolsPage(ctx, cursor)
+		all = append(all, tools...)
+		return next, err
+	})
+
+	return all, err
+}
+
+// ListToolsPage returns one page of tools starting at cursor (empty for the
+// first page) along with the cursor for the next page, if any.
+func (c *Client) ListToolsPage(ctx context.Context, cursor Cursor) ([]Tool, Cursor, error) {
+	response, err := c.callWithRetry(ctx, "mcp.list_tools", cursorParams(cursor))
 	if err != nil {
-		return nil, fmt.Errorf("list_tools response failed: %w", err)
+		return nil, "", fmt.Errorf("list_tools request failed: %w", err)
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("list_tools error: %s (code: %d)",
-			response.Error.Message, response.Error.Code)
+		return nil, "", fmt.Errorf("list_tools error: %w", response.Error)
 	}
 
 	result, ok := response.Result.(map[string]interface{})
 	if !ok {
-		return nil, errors.New("invalid list_tools response format")
+		return nil, "", errors.New("invalid list_tools response format")
 	}
 
 	toolsData, ok := result["tools"].([]interface{})
 	if !ok {
-		return nil, errors.New("invalid or missing tools array in response")
+		return nil, "", errors.New("invalid or missing tools array in response")
 	}
 
 	tools := make([]Tool, 0, len(toolsData))
@@ -189,52 +417,95 @@ func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
 
 		name, _ := toolMap["name"].(string)
 		description, _ := toolMap["description"].(string)
-		inputSchema := toolMap["input_schema"]
+		inputSchema, _ := toolMap["input_schema"].(map[string]interface{})
 
 		tools = append(tools, Tool{
 			Name:        name,
 			Description: description,
-			InputSchema: inputSchema.(map[string]interface{}),
+			InputSchema: inputSchema,
 		})
 	}
 
-	return tools, nil
+	nextCursor, _ := result["nextCursor"].(string)
+
+	return tools, Cursor(nextCursor), nil
 }
 
+// ListResources returns the first page of resources. Use ListAllResources to
+// follow pagination cursors.
 func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
-	c.mutex.RLock()
-	transport := c.transport
-	c.mutex.RUnlock()
+	resources, _, err := c.ListResourcesPage(ctx, "")
+	return resources, err
+}
 
-	if transport == nil || !transport.IsConnected() {
-		return nil, errors.New("client not connected")
+// ListAllResources follows nextCursor until the server reports no further
+// pages.
+func (c *Client) ListAllResources(ctx context.Context) ([]Resource, error) {
+	var all []Resource
+
+	err := paginate(func(cursor Cursor) (Cursor, error) {
+		resources, next, err := c.ListResourcesPage(ctx, cursor)
+		all = append(all, resources...)
+		return next, err
+	})
+
+	return all, err
+}
+
+// ListResourceTemplates returns every resource template the server exposes,
+// following nextCursor across pages.
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	var all []ResourceTemplate
+
+	err := paginate(func(cursor Cursor) (Cursor, error) {
+		templates, next, err := c.ListResourceTemplatesPage(ctx, cursor)
+		all = append(all, templates...)
+		return next, err
+	})
+
+	return all, err
+}
+
+// ListResourceTemplatesPage returns one page of resource templates starting at
+// cursor along with the cursor for the next page, if any.
+func (c *Client) ListResourceTemplatesPage(ctx context.Context, cursor Cursor) ([]ResourceTemplate, Cursor, error) {
+	response, err := c.callWithRetry(ctx, "resources/templates/list", cursorParams(cursor))
+	if err != nil {
+		return nil, "", fmt.Errorf("resources/templates/list request failed: %w", err)
 	}
 
-	requestID := uuid.New().String()
-	request := NewRequest(requestID, "mcp.list_resources", map[string]interface{}{})
+	if response.Error != nil {
+		return nil, "", fmt.Errorf("resources/templates/list error: %w", response.Error)
+	}
 
-	if err := transport.SendWithContext(ctx, request); err != nil {
-		return nil, fmt.Errorf("list_resources request failed: %w", err)
+	var result ListResourceTemplatesResponse
+	if err := decodeResult(response.Result, &result); err != nil {
+		return nil, "", fmt.Errorf("invalid resources/templates/list response format: %w", err)
 	}
 
-	response, err := transport.Receive()
+	return result.ResourceTemplates, result.NextCursor, nil
+}
+
+// ListResourcesPage returns one page of resources starting at cursor (empty
+// for the first page) along with the cursor for the next page, if any.
+func (c *Client) ListResourcesPage(ctx context.Context, cursor Cursor) ([]Resource, Cursor, error) {
+	response, err := c.callWithRetry(ctx, "mcp.list_resources", cursorParams(cursor))
 	if err != nil {
-		return nil, fmt.Errorf("list_resources response failed: %w", err)
+		return nil, "", fmt.Errorf("list_resources request failed: %w", err)
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("list_resources error: %s (code: %d)",
-			response.Error.Message, response.Error.Code)
+		return nil, "", fmt.Errorf("list_resources error: %w", response.Error)
 	}
 
 	result, ok := response.Result.(map[string]interface{})
 	if !ok {
-		return nil, errors.New("invalid list_resources response format")
+		return nil, "", errors.New("invalid list_resources response format")
 	}
 
 	resourcesData, ok := result["resources"].([]interface{})
 	if !ok {
-		return nil, errors.New("invalid or missing resources array in response")
+		return nil, "", errors.New("invalid or missing resources array in response")
 	}
 
 	resources := make([]Resource, 0, len(resourcesData))
@@ -257,38 +528,204 @@ func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
 		})
 	}
 
-	return resources, nil
+	nextCursor, _ := result["nextCursor"].(string)
+
+	return resources, Cursor(nextCursor), nil
 }
 
-func (c *Client) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
-	c.mutex.RLock()
-	transport := c.transport
-	c.mutex.RUnlock()
+func cursorParams(cursor Cursor) map[string]interface{} {
+	params := map[string]interface{}{}
+	if cursor != "" {
+		params["cursor"] = string(cursor)
+	}
+	return params
+}
 
-	if transport == nil || !transport.IsConnected() {
-		return nil, errors.New("client not connected")
+// paginate calls fetch with each successive cursor until it returns an empty
+// one. A server that hands back a cursor it already sent would loop forever,
+// so that is treated as an error.
+func paginate(fetch func(cursor Cursor) (Cursor, error)) error {
+	seen := make(map[Cursor]bool)
+	var cursor Cursor
+
+	for {
+		next, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		if seen[next] {
+			return fmt.Errorf("server returned repeated cursor %q", next)
+		}
+		seen[next] = true
+		cursor = next
 	}
+}
 
-	requestID := uuid.New().String()
-	request := NewRequest(requestID, name, params)
+// CallOption adjusts a single CallTool call.
+type CallOption func(*callOptions)
 
-	if err := transport.SendWithContext(ctx, request); err != nil {
-		return nil, fmt.Errorf("tool call request failed: %w", err)
+type callOptions struct {
+	timeout time.Duration
+	meta    map[string]interface{}
+}
+
+// WithTimeout bounds one call to d. It only ever shortens the deadline: a
+// shorter deadline already on the context still applies. Either way the
+// client's default timeout is not added on top.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMeta attaches meta to the call's params under "_meta", for example a
+// trace ID. Keys are merged with any "_meta" already in the params and with
+// earlier WithMeta options; later values win.
+func WithMeta(meta map[string]interface{}) CallOption {
+	return func(o *callOptions) {
+		if o.meta == nil {
+			o.meta = make(map[string]interface{}, len(meta))
+		}
+		for k, v := range meta {
+			o.meta[k] = v
+		}
+	}
+}
+
+// withMeta returns a copy of params with meta merged into its "_meta" entry.
+func withMeta(params, meta map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(params)+1)
+	for k, v := range params {
+		merged[k] = v
+	}
+
+	combined := make(map[string]interface{}, len(meta))
+	if existing, ok := params["_meta"].(map[string]interface{}); ok {
+		for k, v := range existing {
+			combined[k] = v
+		}
+	}
+	for k, v := range meta {
+		combined[k] = v
+	}
+	merged["_meta"] = combined
+
+	return merged
+}
+
+// CallTool calls a tool and returns its result. A server streaming partial
+// results for the call is waited on until its final result; use
+// CallToolStream to see the partial ones.
+func (c *Client) CallTool(ctx context.Context, name string, params map[string]interface{}, opts ...CallOption) (interface{}, error) {
+	return c.callTool(ctx, name, params, nil, opts...)
+}
+
+func (c *Client) callTool(ctx context.Context, name string, params map[string]interface{}, onPartial func(partial *JSONRPCResponse), opts ...CallOption) (interface{}, error) {
+	var options callOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	if len(options.meta) > 0 {
+		params = withMeta(params, options.meta)
 	}
 
-	response, err := transport.Receive()
+	response, err := c.callStream(ctx, name, params, onPartial)
 	if err != nil {
-		return nil, fmt.Errorf("tool call response failed: %w", err)
+		return nil, fmt.Errorf("tool call request failed: %w", err)
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("tool call error: %s (code: %d)",
-			response.Error.Message, response.Error.Code)
+		return nil, fmt.Errorf("tool call error: %w", response.Error)
 	}
 
 	return response.Result, nil
 }
 
+// CallToolWithProgress calls a tool with a fresh progress token in
+// _meta.progressToken and invokes onProgress for every notifications/progress
+// the server sends for it until the call returns. total is zero when the server
+// does not report one.
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, params map[string]interface{}, onProgress ProgressFunc, opts ...CallOption) (interface{}, error) {
+	token := c.ids.NextID().String()
+
+	c.notifications.addProgress(token, onProgress)
+	defer c.notifications.removeProgress(token)
+
+	// The full slice expression keeps append from writing into spare capacity
+	// of the caller's slice.
+	opts = append(opts[:len(opts):len(opts)], WithMeta(map[string]interface{}{"progressToken": token}))
+	return c.CallTool(ctx, name, params, opts...)
+}
+
+// BatchResult is the outcome of one call within CallToolsBatch.
+type BatchResult struct {
+	Call   ToolCall
+	Result interface{}
+	Err    error
+}
+
+// CallToolsBatch sends all calls in a single JSON-RPC batch and returns their
+// results keyed by request ID. A failing call does not fail the whole batch;
+// its error is reported in the matching BatchResult.
+func (c *Client) CallToolsBatch(ctx context.Context, calls []ToolCall) (map[string]BatchResult, error) {
+	if len(calls) == 0 {
+		return map[string]BatchResult{}, nil
+	}
+
+	c.mutex.RLock()
+	transport := c.transport
+	dispatcher := c.dispatcher
+	c.mutex.RUnlock()
+
+	if transport == nil || dispatcher == nil || !transport.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	requests := make([]*JSONRPCRequest, len(calls))
+	callsByID := make(map[RequestID]ToolCall, len(calls))
+	for i, call := range calls {
+		requestID := c.ids.NextID()
+		requests[i] = NewRequest(requestID, call.Name, call.Arguments)
+		callsByID[requestID] = call
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	responses, err := dispatcher.CallBatch(ctx, requests)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tool call batch failed: %w", err)
+	}
+
+	results := make(map[string]BatchResult, len(responses))
+	for id, response := range responses {
+		result := BatchResult{Call: callsByID[id]}
+		if response.Error != nil {
+			result.Err = fmt.Errorf("tool call error: %w", response.Error)
+		} else {
+			result.Result = response.Result
+		}
+		results[id.String()] = result
+	}
+
+	return results, nil
+}
+
 func (c *Client) GetServerCapabilities() *ServerCapabilities {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
@@ -297,39 +734,40 @@ func (c *Client) GetServerCapabilities() *ServerCapabilities {
 		return nil
 	}
 
-	return &ServerCapabilities{
-		Tools:     c.capabilities.Tools,
-		Resources: c.capabilities.Resources,
-	}
+	capabilities := *c.capabilities
+	return &capabilities
 }
 
 func (c *Client) HealthCheck(ctx context.Context) error {
-	c.mutex.RLock()
-	transport := c.transport
-	c.mutex.RUnlock()
+	response, err := c.callWithRetry(ctx, "ping", nil)
+	if err != nil {
+		return fmt.Errorf("health check request failed: %w", err)
+	}
 
-	if transport == nil || !transport.IsConnected() {
-		return errors.New("client not connected")
+	if response.Error != nil {
+		return fmt.Errorf("health check error: %w", response.Error)
 	}
 
-	requestID := uuid.New().String()
-	request := NewRequest(requestID, "mcp.ping", map[string]interface{}{})
+	return nil
+}
 
-	if err := transport.SendWithContext(ctx, request); err != nil {
-		return fmt.Errorf("health check request failed: %w", err)
-	}
+// Ping sends a single ping and returns the round-trip time. Unlike
+// HealthCheck it is never retried, so the duration reflects one exchange.
+func (c *Client) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
 
-	response, err := transport.Receive()
+	response, err := c.call(ctx, "ping", nil)
 	if err != nil {
-		return fmt.Errorf("health check response failed: %w", err)
+		return 0, fmt.Errorf("ping request failed: %w", err)
 	}
 
+	rtt := time.Since(start)
+
 	if response.Error != nil {
-		return fmt.Errorf("health check error: %s (code: %d)",
-			response.Error.Message, response.Error.Code)
+		return 0, response.Error
 	}
 
-	return nil
+	return rtt, nil
 }
 
 func (c *Client) Disconnect() error {
@@ -342,6 +780,7 @@ func (c *Client) Disconnect() error {
 
 	err := c.transport.Close()
 	c.transport = nil
+	c.dispatcher = nil
 	return err
 }
 
@@ -352,4 +791,15 @@ func (c *Client) IsConnected() bool {
 	return c.transport != nil && c.transport.IsConnected()
 }
 
+// defaultTimeout bounds Connect when no WithDefaultTimeout option is given.
 const defaultTimeout = 10 * time.Second
+
+// LatestProtocolVersion is the MCP revision the client asks for in initialize.
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions lists the revisions a server may answer with.
+var SupportedProtocolVersions = []string{
+	"2024-11-05",
+	"2025-03-26",
+	"2025-06-18",
+}
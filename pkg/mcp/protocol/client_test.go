@@ -0,0 +1,682 @@
+package protocol_test
+
+import (
+	"context"
+	"errors"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedTransport hands every sent request to a handler and queues whatever
+// responses it returns, letting tests control response ordering.
+type scriptedTransport struct {
+	handler   func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse
+	responses chan *protocol.JSONRPCResponse
+	closed    chan struct{}
+	once      sync.Once
+	mutex     sync.Mutex
+	connected bool
+}
+
+func newScriptedTransport(handler func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse) *scriptedTransport {
+	return &scriptedTransport{
+		handler:   handler,
+		responses: make(chan *protocol.JSONRPCResponse, 16),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (t *scriptedTransport) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.connected = true
+	return nil
+}
+
+func (t *scriptedTransport) Send(req *protocol.JSONRPCRequest) error {
+	for _, resp := range t.handler(req) {
+		t.responses <- resp
+	}
+	return nil
+}
+
+func (t *scriptedTransport) SendWithContext(ctx context.Context, req *protocol.JSONRPCRequest) error {
+	return t.Send(req)
+}
+
+func (t *scriptedTransport) Receive() (*protocol.JSONRPCResponse, error) {
+	select {
+	case resp := <-t.responses:
+		return resp, nil
+	case <-t.closed:
+		return nil, errors.New("transport closed")
+	}
+}
+
+func (t *scriptedTransport) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.connected = false
+	t.once.Do(func() { close(t.closed) })
+	return nil
+}
+
+func (t *scriptedTransport) IsConnected() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.connected
+}
+
+func initializeResult(req *protocol.JSONRPCRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion": req.Params["protocolVersion"],
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{"name": "fake", "version": "1.0"},
+	}
+}
+
+func handshakeHandler(next func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse) func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+	return func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		switch req.Method {
+		case "initialize":
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, initializeResult(req))}
+		case "notifications/initialized":
+			return nil
+		case "mcp.list_tools":
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})}
+		case "mcp.list_resources":
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"resources": []interface{}{}})}
+		}
+		return next(req)
+	}
+}
+
+func TestClientCorrelatesResponses(t *testing.T) {
+	t.Run("delivers out-of-order responses to the right caller", func(t *testing.T) {
+		var mutex sync.Mutex
+		var held []*protocol.JSONRPCRequest
+
+		transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			held = append(held, req)
+			if len(held) < 2 {
+				return nil
+			}
+
+			// Answer both calls in reverse order of arrival.
+			return []*protocol.JSONRPCResponse{
+				protocol.NewResponse(held[1].ID, map[string]interface{}{"tool": held[1].Method}),
+				protocol.NewResponse(held[0].ID, map[string]interface{}{"tool": held[0].Method}),
+			}
+		}))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		results := make(map[string]interface{})
+		var resultsMutex sync.Mutex
+
+		for _, name := range []string{"first", "second"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				result, err := client.CallTool(ctx, name, nil)
+				assert.NoError(t, err)
+
+				resultsMutex.Lock()
+				results[name] = result
+				resultsMutex.Unlock()
+			}(name)
+		}
+		wg.Wait()
+
+		assert.Equal(t, map[string]interface{}{"tool": "first"}, results["first"])
+		assert.Equal(t, map[string]interface{}{"tool": "second"}, results["second"])
+	})
+
+	t.Run("times out requests that never get a response", func(t *testing.T) {
+		transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return nil
+		}))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.CallTool(ctx, "silent", nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestClientInitialize(t *testing.T) {
+	t.Run("sends initialize followed by the initialized notification", func(t *testing.T) {
+		var mutex sync.Mutex
+		var methods []string
+		var initParams map[string]interface{}
+
+		transport := newScriptedTransport(handshakeHandler(nil))
+		inner := transport.handler
+		transport.handler = func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			mutex.Lock()
+			methods = append(methods, req.Method)
+			if req.Method == "initialize" {
+				initParams = req.Params
+			}
+			if req.Method == "notifications/initialized" {
+				assert.True(t, req.ID.IsZero())
+			}
+			mutex.Unlock()
+			return inner(req)
+		}
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test-client", Version: "2.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		require.GreaterOrEqual(t, len(methods), 2)
+		assert.Equal(t, []string{"initialize", "notifications/initialized"}, methods[:2])
+		assert.Equal(t, protocol.LatestProtocolVersion, initParams["protocolVersion"])
+		assert.Equal(t, map[string]interface{}{"name": "test-client", "version": "2.0"}, initParams["clientInfo"])
+	})
+
+	t.Run("rejects an unsupported protocol version", func(t *testing.T) {
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{
+				"protocolVersion": "1999-01-01",
+				"capabilities":    map[string]interface{}{},
+				"serverInfo":      map[string]interface{}{"name": "old", "version": "0.1"},
+			})}
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		err := client.Connect(transport)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "incompatible protocol version")
+		assert.False(t, client.IsConnected())
+	})
+
+	t.Run("gives up when the context ends first", func(t *testing.T) {
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		err := client.ConnectWithContext(ctx, transport)
+		assert.ErrorIs(t, err, protocol.ErrTimeout)
+		assert.False(t, client.IsConnected())
+		assert.False(t, transport.IsConnected())
+	})
+
+	t.Run("does not start the transport for a cancelled context", func(t *testing.T) {
+		transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return nil
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		assert.ErrorIs(t, client.ConnectWithContext(ctx, transport), context.Canceled)
+		assert.False(t, transport.IsConnected())
+
+		require.NoError(t, client.ConnectWithContext(context.Background(), transport))
+		assert.True(t, client.IsConnected())
+		client.Disconnect()
+	})
+}
+
+func TestClientCapabilities(t *testing.T) {
+	t.Run("reports only what the server advertises", func(t *testing.T) {
+		var listed []string
+		var mutex sync.Mutex
+
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			mutex.Lock()
+			listed = append(listed, req.Method)
+			mutex.Unlock()
+
+			switch req.Method {
+			case "initialize":
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{
+					"protocolVersion": protocol.LatestProtocolVersion,
+					"capabilities": map[string]interface{}{
+						"tools": map[string]interface{}{"listChanged": true},
+					},
+					"serverInfo": map[string]interface{}{"name": "fake", "version": "1.0"},
+				})}
+			case "mcp.list_tools":
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})}
+			}
+			return nil
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		capabilities := client.GetServerCapabilities()
+		require.NotNil(t, capabilities)
+		require.NotNil(t, capabilities.Tools)
+		assert.True(t, capabilities.Tools.ListChanged)
+		assert.Nil(t, capabilities.Prompts)
+		assert.Nil(t, capabilities.Resources)
+		assert.Nil(t, capabilities.Logging)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.NotContains(t, listed, "mcp.list_resources")
+	})
+}
+
+func TestClientCallToolsBatch(t *testing.T) {
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		if req.Method == "broken" {
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInternalError, "boom", nil)}
+		}
+		return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, req.Params)}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := client.CallToolsBatch(ctx, []protocol.ToolCall{
+		{Name: "echo", Arguments: map[string]interface{}{"text": "hi"}},
+		{Name: "broken"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for id, result := range results {
+		assert.NotEmpty(t, id)
+		switch result.Call.Name {
+		case "echo":
+			assert.NoError(t, result.Err)
+			assert.Equal(t, map[string]interface{}{"text": "hi"}, result.Result)
+		case "broken":
+			assert.Error(t, result.Err)
+		}
+	}
+}
+
+func TestClientPagination(t *testing.T) {
+	newClient := func(t *testing.T, pages map[string]map[string]interface{}) *protocol.Client {
+		handshake := handshakeHandler(nil)
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			if req.Method != "mcp.list_tools" {
+				return handshake(req)
+			}
+			cursor, _ := req.Params["cursor"].(string)
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, pages[cursor])}
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		t.Cleanup(func() { client.Disconnect() })
+		return client
+	}
+
+	tool := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "input_schema": map[string]interface{}{"type": "object"}}
+	}
+
+	t.Run("follows cursors until the last page", func(t *testing.T) {
+		client := newClient(t, map[string]map[string]interface{}{
+			"":   {"tools": []interface{}{tool("a"), tool("b")}, "nextCursor": "p2"},
+			"p2": {"tools": []interface{}{tool("c")}, "nextCursor": "p3"},
+			"p3": {"tools": []interface{}{tool("d")}},
+		})
+
+		first, next, err := client.ListToolsPage(context.Background(), "")
+		require.NoError(t, err)
+		assert.Len(t, first, 2)
+		assert.Equal(t, protocol.Cursor("p2"), next)
+
+		all, err := client.ListAllTools(context.Background())
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(all))
+		for _, tool := range all {
+			names = append(names, tool.Name)
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+	})
+
+	t.Run("stops when the server repeats a cursor", func(t *testing.T) {
+		client := newClient(t, map[string]map[string]interface{}{
+			"":     {"tools": []interface{}{tool("a")}, "nextCursor": "loop"},
+			"loop": {"tools": []interface{}{tool("b")}, "nextCursor": "loop"},
+		})
+
+		_, err := client.ListAllTools(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "repeated cursor")
+	})
+}
+
+func TestClientListResourceTemplates(t *testing.T) {
+	pages := map[string]map[string]interface{}{
+		"": {
+			"resourceTemplates": []interface{}{
+				map[string]interface{}{"uriTemplate": "file:///{path}", "name": "files", "mimeType": "text/plain"},
+			},
+			"nextCursor": "p2",
+		},
+		"p2": {
+			"resourceTemplates": []interface{}{
+				map[string]interface{}{"uriTemplate": "db://{table}/{id}", "name": "rows", "description": "Table rows"},
+			},
+		},
+	}
+
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		if req.Method != "resources/templates/list" {
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrMethodNotFound, "method not found", nil)}
+		}
+		cursor, _ := req.Params["cursor"].(string)
+		return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, pages[cursor])}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	templates, err := client.ListResourceTemplates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.ResourceTemplate{
+		{URITemplate: "file:///{path}", Name: "files", MimeType: "text/plain"},
+		{URITemplate: "db://{table}/{id}", Name: "rows", Description: "Table rows"},
+	}, templates)
+}
+
+func TestClientProgress(t *testing.T) {
+	progressNotification := func(token interface{}, progress, total float64) *protocol.JSONRPCResponse {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: protocol.JSONRPCVersion,
+			Method:  "notifications/progress",
+			Params: map[string]interface{}{
+				"progressToken": token,
+				"progress":      progress,
+				"total":         total,
+			},
+		}
+	}
+
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		meta, _ := req.Params["_meta"].(map[string]interface{})
+		token := meta["progressToken"]
+
+		return []*protocol.JSONRPCResponse{
+			progressNotification("someone-else", 99, 100),
+			progressNotification(token, 1, 2),
+			progressNotification(token, 2, 2),
+			protocol.NewResponse(req.ID, map[string]interface{}{"done": true}),
+		}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	var updates [][2]float64
+	result, err := client.CallToolWithProgress(context.Background(), "slow", map[string]interface{}{"n": 1.0},
+		func(progress, total float64) {
+			updates = append(updates, [2]float64{progress, total})
+		})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"done": true}, result)
+	assert.Equal(t, [][2]float64{{1, 2}, {2, 2}}, updates)
+}
+
+func TestClientMeta(t *testing.T) {
+	// The server echoes the trace ID it receives back in the result's _meta.
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		meta, _ := req.Params["_meta"].(map[string]interface{})
+		return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{
+			"content": []interface{}{map[string]interface{}{"type": "text", "text": "ok"}},
+			"_meta":   meta,
+			"args":    req.Params,
+		})}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	t.Run("round-trips a trace ID", func(t *testing.T) {
+		params := map[string]interface{}{"n": 1.0}
+		result, err := client.CallTool(context.Background(), "echo", params,
+			protocol.WithMeta(map[string]interface{}{"traceId": "abc123"}))
+		require.NoError(t, err)
+		assert.NotContains(t, params, "_meta", "caller's params must not be modified")
+
+		decoded, err := protocol.DecodeCallToolResult(result)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"traceId": "abc123"}, decoded.Meta)
+		assert.Equal(t, 1.0, result.(map[string]interface{})["args"].(map[string]interface{})["n"])
+	})
+
+	t.Run("merges with existing meta", func(t *testing.T) {
+		params := map[string]interface{}{"_meta": map[string]interface{}{"tenant": "acme"}}
+		result, err := client.CallToolWithProgress(context.Background(), "echo", params,
+			func(progress, total float64) {},
+			protocol.WithMeta(map[string]interface{}{"traceId": "abc123"}))
+		require.NoError(t, err)
+
+		decoded, err := protocol.DecodeCallToolResult(result)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", decoded.Meta["tenant"])
+		assert.Equal(t, "abc123", decoded.Meta["traceId"])
+		assert.NotEmpty(t, decoded.Meta["progressToken"])
+	})
+
+	t.Run("leaves the caller's options alone", func(t *testing.T) {
+		opts := make([]protocol.CallOption, 1, 2)
+		opts[0] = protocol.WithMeta(map[string]interface{}{"traceId": "abc123"})
+
+		_, err := client.CallToolWithProgress(context.Background(), "echo", nil, func(progress, total float64) {}, opts...)
+		require.NoError(t, err)
+		assert.Nil(t, opts[:2][1], "spare capacity must not be written to")
+	})
+
+	t.Run("omits meta when none is set", func(t *testing.T) {
+		result, err := client.CallTool(context.Background(), "echo", map[string]interface{}{})
+		require.NoError(t, err)
+
+		decoded, err := protocol.DecodeCallToolResult(result)
+		require.NoError(t, err)
+		assert.Nil(t, decoded.Meta)
+	})
+}
+
+func TestClientDefaultTimeout(t *testing.T) {
+	transport := serveInMemory(t, func(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		switch req.Method {
+		case "initialize":
+			return protocol.NewResponse(req.ID, initializeResult(req))
+		case "mcp.list_tools":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})
+		case "mcp.list_resources":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"resources": []interface{}{}})
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+			return protocol.NewResponse(req.ID, map[string]interface{}{"done": true})
+		}
+		return nil
+	})
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"}, protocol.WithDefaultTimeout(20*time.Millisecond))
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	t.Run("applies to contexts without a deadline", func(t *testing.T) {
+		_, err := client.CallTool(context.Background(), "slow", nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("explicit deadline wins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		result, err := client.CallTool(ctx, "slow", nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"done": true}, result)
+	})
+
+	t.Run("per-call timeout replaces the default", func(t *testing.T) {
+		result, err := client.CallTool(context.Background(), "slow", nil, protocol.WithTimeout(5*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"done": true}, result)
+	})
+
+	t.Run("per-call timeout shortens a longer deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := client.CallTool(ctx, "slow", nil, protocol.WithTimeout(20*time.Millisecond))
+		assert.ErrorIs(t, err, protocol.ErrTimeout)
+	})
+
+	t.Run("per-call timeout does not extend a shorter deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.CallTool(ctx, "slow", nil, protocol.WithTimeout(5*time.Second))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// recordingLogger keeps the messages logged at each level.
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages map[string][]string
+}
+
+func (l *recordingLogger) log(level, msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.messages == nil {
+		l.messages = make(map[string][]string)
+	}
+	l.messages[level] = append(l.messages[level], msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.log("debug", msg) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.log("info", msg) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.log("warn", msg) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.log("error", msg) }
+
+func TestClientLogger(t *testing.T) {
+	transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		switch req.Method {
+		case "initialize":
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, initializeResult(req))}
+		case "mcp.list_tools":
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})}
+		case "mcp.list_resources":
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInternalError, "boom", nil)}
+		}
+		return nil
+	})
+
+	logger := &recordingLogger{}
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"}, protocol.WithLogger(logger))
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	assert.Equal(t, []string{"failed to discover resources"}, logger.messages["warn"])
+	assert.Equal(t, []string{"initialized"}, logger.messages["debug"])
+}
+
+func TestClientPing(t *testing.T) {
+	const delay = 10 * time.Millisecond
+
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		if req.Method != "ping" {
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrMethodNotFound, "method not found", nil)}
+		}
+		time.Sleep(delay)
+		return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{})}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	rtt, err := client.Ping(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, rtt)
+	assert.GreaterOrEqual(t, rtt, delay)
+
+	require.NoError(t, client.HealthCheck(context.Background()))
+}
+
+func TestClientErrors(t *testing.T) {
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		switch req.Method {
+		case "missing":
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrMethodNotFound, "method not found", "missing")}
+		case "resources/templates/list":
+			return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInternalError, "boom", nil)}
+		}
+		return nil
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+
+	_, err := client.CallTool(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, protocol.ErrNotConnected)
+
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	t.Run("error responses keep their code and data", func(t *testing.T) {
+		_, err := client.CallTool(context.Background(), "missing", nil)
+
+		var rpcErr *protocol.JSONRPCError
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, protocol.ErrMethodNotFound, rpcErr.Code)
+		assert.Equal(t, "missing", rpcErr.Data)
+
+		_, err = client.ListResourceTemplates(context.Background())
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, protocol.ErrInternalError, rpcErr.Code)
+	})
+
+	t.Run("unanswered requests time out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.CallTool(ctx, "silent", nil)
+		assert.ErrorIs(t, err, protocol.ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
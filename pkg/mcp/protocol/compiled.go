@@ -0,0 +1,107 @@
+package protocol
+
+import "regexp"
+
+// Compile prepares t for validating many calls: it works out once, for every
+// schema within InputSchema, the required fields, enum members and compiled
+// pattern that ValidateArguments would otherwise look up again on each call.
+// Only compile a tool whose InputSchema is not modified afterwards, such as
+// one owned by a tool.Registry; Clone returns an uncompiled copy.
+func (t *Tool) Compile() {
+	if acceptsAnything(t.InputSchema) {
+		t.compiled = nil
+		return
+	}
+
+	t.compiled = compileSchema(t.InputSchema)
+}
+
+// compiledSchema mirrors one schema and, through properties and items, the
+// schemas nested in it, so validation can walk both trees side by side. A nil
+// *compiledSchema is valid and makes every lookup fall back to reading the
+// schema.
+type compiledSchema struct {
+	required []string
+
+	enum    interface{} // []interface{} when the schema's enum is a list
+	hasEnum bool
+
+	pattern    *regexp.Regexp
+	patternErr error
+
+	properties map[string]*compiledSchema
+	items      *compiledSchema
+}
+
+func compileSchema(schema map[string]interface{}) *compiledSchema {
+	compiled := &compiledSchema{required: requiredFields(schema)}
+
+	if enum, ok := schema["enum"]; ok {
+		compiled.hasEnum = true
+		compiled.enum = enum
+		if members, ok := enum.([]string); ok {
+			list := make([]interface{}, len(members))
+			for i, member := range members {
+				list[i] = member
+			}
+			compiled.enum = list
+		}
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		compiled.pattern, compiled.patternErr = regexp.Compile(pattern)
+	}
+
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		compiled.properties = make(map[string]*compiledSchema, len(props))
+		for name, prop := range props {
+			if propSchema, ok := prop.(map[string]interface{}); ok {
+				compiled.properties[name] = compileSchema(propSchema)
+			}
+		}
+	}
+
+	if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+		compiled.items = compileSchema(itemSchema)
+	}
+
+	return compiled
+}
+
+// property returns the compiled schema of the named property.
+func (c *compiledSchema) property(name string) *compiledSchema {
+	if c == nil {
+		return nil
+	}
+	return c.properties[name]
+}
+
+// item returns the compiled schema of the array items.
+func (c *compiledSchema) item() *compiledSchema {
+	if c == nil {
+		return nil
+	}
+	return c.items
+}
+
+func (c *compiledSchema) requiredIn(schema map[string]interface{}) []string {
+	if c != nil {
+		return c.required
+	}
+	return requiredFields(schema)
+}
+
+func (c *compiledSchema) enumIn(schema map[string]interface{}) (interface{}, bool) {
+	if c != nil {
+		return c.enum, c.hasEnum
+	}
+	enum, ok := schema["enum"]
+	return enum, ok
+}
+
+func (c *compiledSchema) patternFor(pattern string) (*regexp.Regexp, error) {
+	if c != nil {
+		return c.pattern, c.patternErr
+	}
+	return regexp.Compile(pattern)
+}
@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+)
+
+// CompletionRef identifies what an argument being completed belongs to: a
+// prompt, or a resource template. Build one with PromptRef or ResourceRef.
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// PromptRef refers to the arguments of the named prompt.
+func PromptRef(name string) CompletionRef {
+	return CompletionRef{Type: "ref/prompt", Name: name}
+}
+
+// ResourceRef refers to the variables of the resource template uriTemplate.
+func ResourceRef(uriTemplate string) CompletionRef {
+	return CompletionRef{Type: "ref/resource", URI: uriTemplate}
+}
+
+type completeParams struct {
+	Ref      CompletionRef      `json:"ref"`
+	Argument completionArgument `json:"argument"`
+}
+
+type completionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Complete asks the server for values of the argument argName of ref that
+// start with partialValue, as an editor would when autocompleting. The server
+// may return fewer suggestions than it has.
+func (c *Client) Complete(ctx context.Context, ref CompletionRef, argName, partialValue string) ([]string, error) {
+	params, err := toParams(completeParams{
+		Ref:      ref,
+		Argument: completionArgument{Name: argName, Value: partialValue},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid completion params: %w", err)
+	}
+
+	if err := c.Supports("completion/complete"); err != nil {
+		return nil, fmt.Errorf("completion/complete request failed: %w", err)
+	}
+
+	response, err := c.call(ctx, "completion/complete", params)
+	if err != nil {
+		return nil, fmt.Errorf("completion/complete request failed: %w", err)
+	}
+
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	var result struct {
+		Completion struct {
+			Values []string `json:"values"`
+		} `json:"completion"`
+	}
+	if err := decodeResult(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("invalid completion/complete result: %w", err)
+	}
+
+	return result.Completion.Values, nil
+}
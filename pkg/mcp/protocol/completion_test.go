@@ -0,0 +1,112 @@
+package protocol_test
+
+import (
+	"context"
+	"go-mcp/pkg/mcp/protocol"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientComplete(t *testing.T) {
+	// connect returns a client of a server advertising capabilities that
+	// answers completion/complete with the languages starting with the value
+	// typed, and records the params of each completion request.
+	connect := func(t *testing.T, capabilities map[string]interface{}) (*protocol.Client, <-chan map[string]interface{}) {
+		requests := make(chan map[string]interface{}, 4)
+
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			var result interface{}
+			switch req.Method {
+			case "initialize":
+				initialize := initializeResult(req)
+				initialize["capabilities"] = capabilities
+				result = initialize
+			case "mcp.list_tools":
+				result = map[string]interface{}{"tools": []interface{}{}}
+			case "completion/complete":
+				requests <- req.Params
+
+				argument, _ := req.Params["argument"].(map[string]interface{})
+				if argument["name"] == "broken" {
+					return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInvalidParams, "unknown argument", nil)}
+				}
+
+				values := []interface{}{}
+				for _, language := range []string{"go", "golang", "python"} {
+					if strings.HasPrefix(language, argument["value"].(string)) {
+						values = append(values, language)
+					}
+				}
+				result = map[string]interface{}{
+					"completion": map[string]interface{}{"values": values, "total": len(values), "hasMore": false},
+				}
+			default:
+				return nil
+			}
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, result)}
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		t.Cleanup(func() { client.Disconnect() })
+
+		return client, requests
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	supported := map[string]interface{}{"completions": map[string]interface{}{}, "tools": map[string]interface{}{}}
+
+	t.Run("completes prompt arguments", func(t *testing.T) {
+		client, requests := connect(t, supported)
+
+		values, err := client.Complete(ctx, protocol.PromptRef("code_review"), "language", "go")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "golang"}, values)
+
+		assert.Equal(t, map[string]interface{}{
+			"ref":      map[string]interface{}{"type": "ref/prompt", "name": "code_review"},
+			"argument": map[string]interface{}{"name": "language", "value": "go"},
+		}, <-requests)
+	})
+
+	t.Run("completes resource template variables", func(t *testing.T) {
+		client, requests := connect(t, supported)
+
+		values, err := client.Complete(ctx, protocol.ResourceRef("file:///{path}"), "path", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "golang", "python"}, values)
+
+		assert.Equal(t, map[string]interface{}{"type": "ref/resource", "uri": "file:///{path}"}, (<-requests)["ref"])
+	})
+
+	t.Run("returns no suggestions", func(t *testing.T) {
+		client, _ := connect(t, supported)
+
+		values, err := client.Complete(ctx, protocol.PromptRef("code_review"), "language", "rust")
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("reports server errors", func(t *testing.T) {
+		client, _ := connect(t, supported)
+
+		_, err := client.Complete(ctx, protocol.PromptRef("code_review"), "broken", "")
+		var rpcErr *protocol.JSONRPCError
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+	})
+
+	t.Run("requires the completions capability", func(t *testing.T) {
+		client, requests := connect(t, map[string]interface{}{"tools": map[string]interface{}{}})
+
+		_, err := client.Complete(ctx, protocol.PromptRef("code_review"), "language", "go")
+		assert.ErrorIs(t, err, protocol.ErrCompletionsNotSupported)
+		assert.Empty(t, requests, "the request is not sent")
+	})
+}
@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeContent turns the generically unmarshaled "content" array of a tool
+// result into concrete Content values, chosen by each item's "type".
+func DecodeContent(raw []interface{}) ([]Content, error) {
+	contents := make([]Content, 0, len(raw))
+
+	for i, item := range raw {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("content item %d: expected object, got %T", i, item)
+		}
+
+		contentType, ok := itemMap["type"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content item %d: content type not found or invalid", i)
+		}
+
+		var content Content
+		var err error
+		switch ContentType(contentType) {
+		case ContentTypeText:
+			var text TextContent
+			err = decodeResult(itemMap, &text)
+			content = text
+		case ContentTypeImage:
+			var image ImageContent
+			err = decodeResult(itemMap, &image)
+			content = image
+		case ContentTypeAudio:
+			var audio AudioContent
+			err = decodeResult(itemMap, &audio)
+			content = audio
+		case ContentTypeResource:
+			var resource EmbeddedResource
+			err = decodeResult(itemMap, &resource)
+			content = resource
+		default:
+			return nil, fmt.Errorf("content item %d: unknown content type: %s", i, contentType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("content item %d: %w", i, err)
+		}
+
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
+
+// UnmarshalJSON decodes each content item into its concrete type, chosen by
+// the item's "type" field.
+func (r *CallToolResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Content []interface{}          `json:"content"`
+		IsError bool                   `json:"isError"`
+		Meta    map[string]interface{} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	content, err := DecodeContent(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	r.Content = content
+	r.IsError = raw.IsError
+	r.Meta = raw.Meta
+	return nil
+}
+
+// DecodeCallToolResult converts a raw tools/call result into a CallToolResult.
+func DecodeCallToolResult(result interface{}) (*CallToolResult, error) {
+	if _, ok := result.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("invalid tool result format: %T", result)
+	}
+
+	var callResult CallToolResult
+	if err := decodeResult(result, &callResult); err != nil {
+		return nil, fmt.Errorf("invalid tool result format: %w", err)
+	}
+
+	return &callResult, nil
+}
+
+// Validate checks that every content item carries the fields its type
+// requires, so a server can catch a malformed result before sending it. All
+// problems found are reported together.
+func (r *CallToolResult) Validate() error {
+	var errs []error
+	for i, content := range r.Content {
+		for _, err := range validateContent(content) {
+			errs = append(errs, fmt.Errorf("content item %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Text returns the text of every TextContent item, in order and separated by
+// newlines. Other content is skipped.
+func (r *CallToolResult) Text() string {
+	var texts []string
+	for _, content := range r.Content {
+		switch text := content.(type) {
+		case TextContent:
+			texts = append(texts, text.Text)
+		case *TextContent:
+			if text != nil {
+				texts = append(texts, text.Text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ContentByType returns the content items of the given type, in order.
+func (r *CallToolResult) ContentByType(contentType ContentType) []Content {
+	var matches []Content
+	for _, content := range r.Content {
+		// GetType has a value receiver, so it cannot be called on a nil pointer.
+		v := reflect.ValueOf(content)
+		if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+			continue
+		}
+		if content.GetType() == contentType {
+			matches = append(matches, content)
+		}
+	}
+	return matches
+}
+
+func validateContent(content Content) []error {
+	// Content types have value receivers, so pointers to them are content
+	// too and validate like the values they point to.
+	v := reflect.ValueOf(content)
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return []error{errors.New("content is nil")}
+	}
+	if v.Kind() == reflect.Pointer {
+		if elem, ok := v.Elem().Interface().(Content); ok {
+			content = elem
+		}
+	}
+
+	var errs []error
+	checkType := func(got string) {
+		if got != string(content.GetType()) {
+			errs = append(errs, fmt.Errorf("type is %q, want %q", got, content.GetType()))
+		}
+	}
+	checkAnnotations := func(annotations *Annotation) {
+		if annotations != nil && (annotations.Priority < 0 || annotations.Priority > 1) {
+			errs = append(errs, fmt.Errorf("annotation priority %v is outside [0, 1]", annotations.Priority))
+		}
+	}
+	checkData := func(data, mimeType string) {
+		if data == "" {
+			errs = append(errs, errors.New("missing data"))
+		} else if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+			errs = append(errs, fmt.Errorf("data is not valid base64: %w", err))
+		}
+		if mimeType == "" {
+			errs = append(errs, errors.New("missing mimeType"))
+		}
+	}
+
+	switch c := content.(type) {
+	case TextContent:
+		checkType(c.Type)
+		if c.Text == "" {
+			errs = append(errs, errors.New("missing text"))
+		}
+		checkAnnotations(c.Annotations)
+	case ImageContent:
+		checkType(string(c.Type))
+		checkData(c.Data, c.MimeType)
+		checkAnnotations(c.Annotations)
+	case AudioContent:
+		checkType(string(c.Type))
+		checkData(c.Data, c.MimeType)
+		checkAnnotations(c.Annotations)
+	case EmbeddedResource:
+		checkType(string(c.Type))
+		if c.Resource.URI == "" {
+			errs = append(errs, errors.New("missing resource uri"))
+		}
+		checkAnnotations(c.Annotations)
+	default:
+		errs = append(errs, fmt.Errorf("unsupported content type %T", content))
+	}
+
+	return errs
+}
@@ -0,0 +1,39 @@
+package protocol
+
+// withDefaults returns args with every absent top-level property that declares
+// a "default" in schema filled in. args itself is left untouched, and defaults
+// are copied so a tool modifying its arguments cannot alter the schema.
+func withDefaults(schema map[string]interface{}, args map[string]interface{}) map[string]interface{} {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return args
+	}
+
+	var filled map[string]interface{}
+	for name, prop := range props {
+		propSchema, ok := prop.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		def, hasDefault := propSchema["default"]
+		if !hasDefault {
+			continue
+		}
+		if _, exists := args[name]; exists {
+			continue
+		}
+
+		if filled == nil {
+			filled = make(map[string]interface{}, len(args)+1)
+			for k, v := range args {
+				filled[k] = v
+			}
+		}
+		filled[name] = copyValue(def)
+	}
+
+	if filled == nil {
+		return args
+	}
+	return filled
+}
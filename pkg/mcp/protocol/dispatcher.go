@@ -0,0 +1,366 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrDispatcherClosed = errors.New("dispatcher closed")
+
+// cancelNotificationTimeout bounds how long we try to tell the server about a
+// cancelled request; the caller has already given up waiting.
+const cancelNotificationTimeout = time.Second
+
+// dispatcher owns the read side of a Transport. A single goroutine reads every
+// incoming response and hands it to the caller waiting on the matching ID, so
+// concurrent requests over one transport never receive each other's responses.
+type dispatcher struct {
+	transport      Transport
+	logger         Logger
+	onNotification func(notification *JSONRPCResponse)
+	onRequest      func(ctx context.Context, request *JSONRPCResponse)
+	pending        map[RequestID]chan *JSONRPCResponse
+	partials       map[RequestID]func(partial *JSONRPCResponse)
+	serving        map[RequestID]servedRequest
+	ctx            context.Context
+	stop           context.CancelFunc
+	mutex          sync.Mutex
+	done           chan struct{}
+	err            error
+}
+
+// servedRequest is a server request being handled. Its context ends when the
+// server cancels the request or the connection goes away.
+type servedRequest struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newDispatcher(transport Transport, logger Logger, onNotification func(notification *JSONRPCResponse), onRequest func(ctx context.Context, request *JSONRPCResponse)) *dispatcher {
+	ctx, stop := context.WithCancel(context.Background())
+	d := &dispatcher{
+		transport:      transport,
+		logger:         logger,
+		onNotification: onNotification,
+		onRequest:      onRequest,
+		pending:        make(map[RequestID]chan *JSONRPCResponse),
+		partials:       make(map[RequestID]func(partial *JSONRPCResponse)),
+		serving:        make(map[RequestID]servedRequest),
+		ctx:            ctx,
+		stop:           stop,
+		done:           make(chan struct{}),
+	}
+
+	go d.readLoop()
+
+	return d
+}
+
+func (d *dispatcher) readLoop() {
+	for {
+		response, err := d.transport.Receive()
+		if err != nil {
+			// A frame that is not a valid message says nothing about the
+			// connection; only the calls it was meant to answer are lost.
+			var frameErr *FrameError
+			if errors.As(err, &frameErr) {
+				d.reject(frameErr)
+				continue
+			}
+
+			d.shutdown(err)
+			// Nothing more can be read, so make sure the transport no longer
+			// claims to be connected.
+			if d.transport.IsConnected() {
+				d.transport.Close()
+			}
+			return
+		}
+
+		// Notifications run on the read goroutine, so handlers must not block.
+		if response.IsNotification() {
+			if response.Method == "notifications/cancelled" {
+				d.cancelServing(response.Params)
+			}
+			if d.onNotification != nil {
+				d.onNotification(response)
+			}
+			continue
+		}
+
+		// Server requests may take a while to answer (sampling waits on an
+		// LLM), so they must not hold up the read loop.
+		if response.IsRequest() {
+			if d.onRequest != nil {
+				request := d.serve(response.ID)
+				go func() {
+					defer d.finish(response.ID, request)
+					d.onRequest(request.ctx, response)
+				}()
+			}
+			continue
+		}
+
+		// Error responses to requests the server could not parse carry a null
+		// ID; there is no caller to hand them to.
+		if !response.IsResponse() || response.ID.IsZero() {
+			continue
+		}
+
+		// Partial results leave the request pending. Like notifications,
+		// they are handed over on the read goroutine, which keeps them in
+		// order with each other and ahead of the final response.
+		if response.IsPartial() {
+			d.mutex.Lock()
+			onPartial := d.partials[response.ID]
+			d.mutex.Unlock()
+
+			if onPartial != nil {
+				onPartial(response)
+			}
+			continue
+		}
+
+		// Responses nobody is waiting for (e.g. the caller already timed out)
+		// are dropped.
+		if ch, exists := d.take(response.ID); exists {
+			ch <- response
+		}
+	}
+}
+
+// serve records a server request as being handled and returns it.
+func (d *dispatcher) serve(id RequestID) servedRequest {
+	ctx, cancel := context.WithCancel(d.ctx)
+	request := servedRequest{ctx: ctx, cancel: cancel}
+
+	d.mutex.Lock()
+	d.serving[id] = request
+	d.mutex.Unlock()
+
+	return request
+}
+
+// finish forgets a server request once its handler returns, unless a later
+// request has reused the ID.
+func (d *dispatcher) finish(id RequestID, request servedRequest) {
+	request.cancel()
+
+	d.mutex.Lock()
+	if d.serving[id].ctx == request.ctx {
+		delete(d.serving, id)
+	}
+	d.mutex.Unlock()
+}
+
+// cancelServing ends the context of the server request a
+// notifications/cancelled names.
+func (d *dispatcher) cancelServing(params map[string]interface{}) {
+	var id RequestID
+	if err := decodeResult(params["requestId"], &id); err != nil || id.IsZero() {
+		return
+	}
+
+	d.mutex.Lock()
+	request, exists := d.serving[id]
+	d.mutex.Unlock()
+
+	if exists {
+		request.cancel()
+	}
+}
+
+// reject fails the pending calls an invalid frame was meant to answer with a
+// protocol error response.
+func (d *dispatcher) reject(frameErr *FrameError) {
+	d.logger.Warn("skipped invalid message", "error", frameErr.Err)
+
+	rpcErr := &JSONRPCError{Code: ErrProtocolError, Message: frameErr.Err.Error()}
+	errors.As(frameErr.Err, &rpcErr)
+
+	for _, id := range frameErr.IDs {
+		if ch, exists := d.take(id); exists {
+			ch <- &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: id, Error: rpcErr}
+		}
+	}
+}
+
+// take removes the call waiting for id and returns its channel.
+func (d *dispatcher) take(id RequestID) (chan *JSONRPCResponse, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	ch, exists := d.pending[id]
+	if exists {
+		delete(d.pending, id)
+	}
+	return ch, exists
+}
+
+func (d *dispatcher) shutdown(err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+
+	d.err = err
+	d.stop()
+	d.pending = make(map[RequestID]chan *JSONRPCResponse)
+	d.partials = make(map[RequestID]func(partial *JSONRPCResponse))
+	close(d.done)
+}
+
+func (d *dispatcher) Call(ctx context.Context, request *JSONRPCRequest) (*JSONRPCResponse, error) {
+	responses, err := d.CallBatch(ctx, []*JSONRPCRequest{request})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses[request.ID], nil
+}
+
+// CallStream is Call for a request the server may answer in several frames.
+// onPartial is invoked on the read goroutine for every partial result before
+// the final response, so it must not block. Without it, partial results are
+// dropped.
+func (d *dispatcher) CallStream(ctx context.Context, request *JSONRPCRequest, onPartial func(partial *JSONRPCResponse)) (*JSONRPCResponse, error) {
+	if onPartial == nil {
+		return d.Call(ctx, request)
+	}
+
+	d.mutex.Lock()
+	if _, exists := d.partials[request.ID]; exists {
+		d.mutex.Unlock()
+		return nil, fmt.Errorf("duplicate request ID: %s", request.ID)
+	}
+	d.partials[request.ID] = onPartial
+	d.mutex.Unlock()
+
+	defer func() {
+		d.mutex.Lock()
+		delete(d.partials, request.ID)
+		d.mutex.Unlock()
+	}()
+
+	return d.Call(ctx, request)
+}
+
+// CallBatch sends requests as one batch frame when the transport supports it,
+// falling back to individual sends otherwise, and waits for every response.
+// The returned map is keyed by request ID.
+func (d *dispatcher) CallBatch(ctx context.Context, requests []*JSONRPCRequest) (map[RequestID]*JSONRPCResponse, error) {
+	channels := make(map[RequestID]chan *JSONRPCResponse, len(requests))
+
+	d.mutex.Lock()
+	select {
+	case <-d.done:
+		d.mutex.Unlock()
+		return nil, d.closedError()
+	default:
+	}
+	for _, request := range requests {
+		_, pending := d.pending[request.ID]
+		_, duplicate := channels[request.ID]
+		if pending || duplicate {
+			for id := range channels {
+				delete(d.pending, id)
+			}
+			d.mutex.Unlock()
+			return nil, fmt.Errorf("duplicate request ID: %s", request.ID)
+		}
+
+		ch := make(chan *JSONRPCResponse, 1)
+		channels[request.ID] = ch
+		d.pending[request.ID] = ch
+	}
+	d.mutex.Unlock()
+
+	if err := d.send(ctx, requests); err != nil {
+		d.forget(requests...)
+		return nil, err
+	}
+
+	responses := make(map[RequestID]*JSONRPCResponse, len(requests))
+	for id, ch := range channels {
+		select {
+		case response := <-ch:
+			responses[id] = response
+		case <-ctx.Done():
+			d.forget(requests...)
+			d.cancel(requests, responses, ctx.Err())
+			return nil, ctx.Err()
+		case <-d.done:
+			select {
+			case response := <-ch:
+				responses[id] = response
+			default:
+				return nil, d.closedError()
+			}
+		}
+	}
+
+	return responses, nil
+}
+
+// cancel tells the server to stop working on every request that has not been
+// answered yet.
+func (d *dispatcher) cancel(requests []*JSONRPCRequest, answered map[RequestID]*JSONRPCResponse, reason error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotificationTimeout)
+	defer cancel()
+
+	for _, request := range requests {
+		if _, ok := answered[request.ID]; ok {
+			continue
+		}
+
+		// The spec forbids cancelling initialize.
+		if request.Method == "initialize" {
+			continue
+		}
+
+		notification := NewNotification("notifications/cancelled", map[string]interface{}{
+			"requestId": request.ID,
+			"reason":    reason.Error(),
+		})
+		if err := d.transport.SendWithContext(ctx, notification); err != nil {
+			return
+		}
+	}
+}
+
+func (d *dispatcher) send(ctx context.Context, requests []*JSONRPCRequest) error {
+	if batcher, ok := d.transport.(BatchSender); ok && len(requests) > 1 {
+		return batcher.SendBatch(requests)
+	}
+
+	for _, request := range requests {
+		if err := d.transport.SendWithContext(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *dispatcher) forget(requests ...*JSONRPCRequest) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, request := range requests {
+		delete(d.pending, request.ID)
+	}
+}
+
+func (d *dispatcher) closedError() error {
+	if d.err != nil {
+		return fmt.Errorf("%w: %v", ErrDispatcherClosed, d.err)
+	}
+	return ErrDispatcherClosed
+}
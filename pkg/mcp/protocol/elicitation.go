@@ -0,0 +1,88 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+)
+
+// ElicitationRequest is an elicitation/create request: the server asks the
+// client to collect input from the user.
+type ElicitationRequest struct {
+	// Message explains to the user what is being asked for.
+	Message string `json:"message"`
+	// RequestedSchema is a JSON schema of type object describing the fields
+	// to collect.
+	RequestedSchema map[string]interface{} `json:"requestedSchema"`
+}
+
+// ElicitationAction is how the user responded to an elicitation request.
+type ElicitationAction string
+
+const (
+	// ElicitationAccept submits Content to the server.
+	ElicitationAccept ElicitationAction = "accept"
+	// ElicitationDecline means the user explicitly refused.
+	ElicitationDecline ElicitationAction = "decline"
+	// ElicitationCancel means the user dismissed the request without
+	// choosing.
+	ElicitationCancel ElicitationAction = "cancel"
+)
+
+// ElicitationResult is the user's answer returned to the server. Content is
+// only sent with ElicitationAccept and must match the requested schema.
+type ElicitationResult struct {
+	Action  ElicitationAction      `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// ElicitationHandler collects the input a server asks for, typically by
+// prompting the user. ctx is done if the server withdraws the request or the
+// connection drops, so that a pending prompt can be dismissed.
+type ElicitationHandler func(ctx context.Context, request ElicitationRequest) (ElicitationResult, error)
+
+// SetElicitationHandler lets servers ask the user for input through this
+// client. The elicitation capability is advertised when the handler is set
+// before Connect. Passing nil removes the handler.
+func (c *Client) SetElicitationHandler(handler ElicitationHandler) {
+	if handler == nil {
+		c.notifications.setRequestHandler("elicitation/create", nil)
+		return
+	}
+
+	c.notifications.setRequestHandler("elicitation/create", func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		var request ElicitationRequest
+		if err := decodeResult(params, &request); err != nil {
+			return nil, &JSONRPCError{Code: ErrInvalidParams, Message: fmt.Sprintf("invalid elicitation params: %v", err)}
+		}
+
+		result, err := handler(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return result, checkElicitationResult(request, result)
+	})
+}
+
+// checkElicitationResult makes sure the handler answered with a known action
+// and, when accepting, with content matching the requested schema.
+func checkElicitationResult(request ElicitationRequest, result ElicitationResult) error {
+	switch result.Action {
+	case ElicitationAccept:
+	case ElicitationDecline, ElicitationCancel:
+		if result.Content != nil {
+			return fmt.Errorf("elicitation content sent with action %q", result.Action)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown elicitation action %q", result.Action)
+	}
+
+	if _, typed := request.RequestedSchema["type"]; !typed {
+		return nil
+	}
+	if err := ValidateType(request.RequestedSchema, result.Content); err != nil {
+		return fmt.Errorf("elicitation content does not match the requested schema: %w", err)
+	}
+	return nil
+}
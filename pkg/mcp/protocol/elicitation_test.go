@@ -0,0 +1,137 @@
+package protocol_test
+
+import (
+	"context"
+	"errors"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func elicitationParams() map[string]interface{} {
+	return map[string]interface{}{
+		"message": "Which repository should I use?",
+		"requestedSchema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"owner": map[string]interface{}{"type": "string", "description": "Repository owner"},
+				"name":  map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"owner", "name"},
+		},
+	}
+}
+
+func TestClientElicitation(t *testing.T) {
+	t.Run("answers elicitation/create with the handler", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+
+		var got protocol.ElicitationRequest
+		client.SetElicitationHandler(func(ctx context.Context, request protocol.ElicitationRequest) (protocol.ElicitationResult, error) {
+			got = request
+			return protocol.ElicitationResult{
+				Action:  protocol.ElicitationAccept,
+				Content: map[string]interface{}{"owner": "dgzlopes", "name": "go-mcp"},
+			}, nil
+		})
+
+		serverEnd, messages, initParams := connectWithServerEnd(t, client)
+
+		capabilities := (<-initParams)["capabilities"].(map[string]interface{})
+		assert.Contains(t, capabilities, "elicitation")
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("e1", "elicitation/create", elicitationParams())))
+
+		response := nextMessage(t, messages)
+		assert.Equal(t, protocol.StringID("e1"), response.ID)
+		require.Nil(t, response.Error)
+		assert.Equal(t, map[string]interface{}{
+			"action":  "accept",
+			"content": map[string]interface{}{"owner": "dgzlopes", "name": "go-mcp"},
+		}, response.Result)
+
+		assert.Equal(t, "Which repository should I use?", got.Message)
+		assert.Equal(t, "object", got.RequestedSchema["type"])
+		assert.Contains(t, got.RequestedSchema["properties"], "owner")
+	})
+
+	t.Run("sends declines without content", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		client.SetElicitationHandler(func(ctx context.Context, request protocol.ElicitationRequest) (protocol.ElicitationResult, error) {
+			return protocol.ElicitationResult{Action: protocol.ElicitationDecline}, nil
+		})
+
+		serverEnd, messages, _ := connectWithServerEnd(t, client)
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("e2", "elicitation/create", elicitationParams())))
+
+		response := nextMessage(t, messages)
+		require.Nil(t, response.Error)
+		assert.Equal(t, map[string]interface{}{"action": "decline"}, response.Result)
+	})
+
+	t.Run("reports invalid answers and handler errors", func(t *testing.T) {
+		tests := []struct {
+			id     string
+			result protocol.ElicitationResult
+			err    error
+			want   string
+		}{
+			{
+				id:     "e3",
+				result: protocol.ElicitationResult{Action: protocol.ElicitationAccept, Content: map[string]interface{}{"owner": "dgzlopes"}},
+				want:   "missing required field: name",
+			},
+			{
+				id:     "e4",
+				result: protocol.ElicitationResult{Action: protocol.ElicitationCancel, Content: map[string]interface{}{"owner": "dgzlopes"}},
+				want:   `elicitation content sent with action "cancel"`,
+			},
+			{
+				id:     "e5",
+				result: protocol.ElicitationResult{Action: "maybe"},
+				want:   `unknown elicitation action "maybe"`,
+			},
+			{
+				id:   "e6",
+				err:  errors.New("no terminal attached"),
+				want: "no terminal attached",
+			},
+		}
+
+		// Requests are sent one at a time, so the handler answers them in order.
+		answers := make(chan int, 1)
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		client.SetElicitationHandler(func(ctx context.Context, request protocol.ElicitationRequest) (protocol.ElicitationResult, error) {
+			tt := tests[<-answers]
+			return tt.result, tt.err
+		})
+
+		serverEnd, messages, _ := connectWithServerEnd(t, client)
+
+		for i, tt := range tests {
+			answers <- i
+			require.NoError(t, serverEnd.SendResponse(serverRequest(tt.id, "elicitation/create", elicitationParams())))
+
+			response := nextMessage(t, messages)
+			assert.Equal(t, protocol.StringID(tt.id), response.ID)
+			require.NotNil(t, response.Error)
+			assert.Contains(t, response.Error.Message, tt.want)
+		}
+	})
+
+	t.Run("without a handler", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		serverEnd, messages, initParams := connectWithServerEnd(t, client)
+
+		capabilities, _ := (<-initParams)["capabilities"].(map[string]interface{})
+		assert.NotContains(t, capabilities, "elicitation")
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("e7", "elicitation/create", elicitationParams())))
+		response := nextMessage(t, messages)
+		require.NotNil(t, response.Error)
+		assert.Equal(t, protocol.ErrMethodNotFound, response.Error.Code)
+	})
+}
@@ -0,0 +1,63 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoErrorData is returned when decoding the data of an error that carries
+// none.
+var ErrNoErrorData = errors.New("error has no data")
+
+// ValidationErrorData is the data servers commonly attach to ErrInvalidParams
+// errors, listing each argument that failed validation.
+type ValidationErrorData struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// FieldError is one validation failure: the argument, written as a path like
+// "config.retries", and what was wrong with it.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// DecodeData unmarshals the error's data into v, which should be a pointer
+// to a struct describing it.
+func (e *JSONRPCError) DecodeData(v interface{}) error {
+	if e.Data == nil {
+		return ErrNoErrorData
+	}
+	if err := decodeResult(e.Data, v); err != nil {
+		return fmt.Errorf("failed to decode error data: %w", err)
+	}
+	return nil
+}
+
+// DecodeData unmarshals the error's data into v, which should be a pointer
+// to a struct describing it.
+func (e *ErrorMessage) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 || string(e.Data) == "null" {
+		return ErrNoErrorData
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("failed to decode error data: %w", err)
+	}
+	return nil
+}
+
+// DecodeErrorData finds the *JSONRPCError in err's chain, as returned by
+// Client methods, and unmarshals its data into v. It returns the JSON-RPC
+// error so callers can also inspect its code, or nil along with an error if
+// err did not come from the peer.
+func DecodeErrorData(err error, v interface{}) (*JSONRPCError, error) {
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, fmt.Errorf("not a JSON-RPC error: %w", err)
+	}
+	if err := rpcErr.DecodeData(v); err != nil {
+		return rpcErr, err
+	}
+	return rpcErr, nil
+}
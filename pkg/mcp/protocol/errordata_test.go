@@ -0,0 +1,97 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+func TestDecodeErrorData(t *testing.T) {
+	validation := map[string]interface{}{
+		"errors": []interface{}{
+			map[string]interface{}{"field": "config.retries", "message": "must be positive"},
+			map[string]interface{}{"field": "name", "message": "is required"},
+		},
+	}
+
+	t.Run("decodes structured data from the server", func(t *testing.T) {
+		transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			switch req.Method {
+			case "validate":
+				return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInvalidParams, "invalid params", validation)}
+			case "plain":
+				return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInternalError, "boom", nil)}
+			}
+			return nil
+		}))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := client.CallTool(ctx, "validate", nil)
+		require.Error(t, err)
+
+		var data protocol.ValidationErrorData
+		rpcErr, err := protocol.DecodeErrorData(fmt.Errorf("calling tool: %w", err), &data)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+		assert.Equal(t, []protocol.FieldError{
+			{Field: "config.retries", Message: "must be positive"},
+			{Field: "name", Message: "is required"},
+		}, data.Errors)
+
+		_, err = client.CallTool(ctx, "plain", nil)
+		require.Error(t, err)
+
+		rpcErr, err = protocol.DecodeErrorData(err, &data)
+		assert.ErrorIs(t, err, protocol.ErrNoErrorData)
+		assert.Equal(t, protocol.ErrInternalError, rpcErr.Code)
+	})
+
+	t.Run("decodes into caller-provided types", func(t *testing.T) {
+		var hint struct {
+			RetryAfter int `json:"retryAfter"`
+		}
+		rpcErr := &protocol.JSONRPCError{Code: protocol.ErrServerError, Message: "busy", Data: map[string]interface{}{"retryAfter": 30.0}}
+
+		require.NoError(t, rpcErr.DecodeData(&hint))
+		assert.Equal(t, 30, hint.RetryAfter)
+	})
+
+	t.Run("reports data of the wrong shape", func(t *testing.T) {
+		rpcErr := &protocol.JSONRPCError{Code: protocol.ErrInvalidParams, Data: "not an object"}
+
+		var data protocol.ValidationErrorData
+		assert.ErrorContains(t, rpcErr.DecodeData(&data), "failed to decode error data")
+	})
+
+	t.Run("rejects errors that did not come from the peer", func(t *testing.T) {
+		var data protocol.ValidationErrorData
+		rpcErr, err := protocol.DecodeErrorData(errors.New("connection reset"), &data)
+		assert.Nil(t, rpcErr)
+		assert.ErrorContains(t, err, "not a JSON-RPC error")
+	})
+
+	t.Run("decodes raw error messages", func(t *testing.T) {
+		var response protocol.ResponseMessage
+		require.NoError(t, json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid","data":{"errors":[{"field":"name","message":"is required"}]}}}`), &response))
+
+		var data protocol.ValidationErrorData
+		require.NoError(t, response.Error.DecodeData(&data))
+		assert.Equal(t, []protocol.FieldError{{Field: "name", Message: "is required"}}, data.Errors)
+
+		assert.ErrorIs(t, (&protocol.ErrorMessage{}).DecodeData(&data), protocol.ErrNoErrorData)
+	})
+}
@@ -0,0 +1,94 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Framing selects how StdioTransport delimits the messages it writes.
+type Framing int
+
+const (
+	// FramingNewline writes each message as a single line of JSON. It is the
+	// default.
+	FramingNewline Framing = iota
+	// FramingContentLength writes each message after a
+	// "Content-Length: N\r\n\r\n" header, as LSP servers expect.
+	FramingContentLength
+)
+
+const contentLengthHeader = "content-length:"
+
+// splitFrames is a bufio.SplitFunc accepting both framings, so a server may
+// use either whatever the transport writes. A frame starting with a
+// Content-Length header extends over exactly that many bytes and may span
+// several lines, as pretty-printed JSON does; anything else ends at the next
+// newline.
+func splitFrames(data []byte, atEOF bool) (int, []byte, error) {
+	prefix := strings.ToLower(string(data[:min(len(data), len(contentLengthHeader))]))
+	if !strings.HasPrefix(contentLengthHeader, prefix) {
+		return bufio.ScanLines(data, atEOF)
+	}
+	if len(prefix) < len(contentLengthHeader) {
+		// Too short to tell yet, unless the line is already complete.
+		if atEOF || bytes.IndexByte(data, '\n') >= 0 {
+			return bufio.ScanLines(data, atEOF)
+		}
+		return 0, nil, nil
+	}
+
+	headerEnd, bodyStart := headerLength(data)
+	if headerEnd < 0 {
+		if atEOF {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, nil
+	}
+
+	length, err := parseContentLength(data[:headerEnd])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(data)-bodyStart < length {
+		if atEOF {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, nil
+	}
+
+	return bodyStart + length, data[bodyStart : bodyStart+length], nil
+}
+
+// headerLength finds the blank line ending a header block, returning where
+// the headers end and the body starts, or -1 if it has not arrived yet.
+func headerLength(data []byte) (int, int) {
+	if i := bytes.Index(data, []byte("\r\n\r\n")); i >= 0 {
+		return i, i + 4
+	}
+	if i := bytes.Index(data, []byte("\n\n")); i >= 0 {
+		return i, i + 2
+	}
+	return -1, -1
+}
+
+func parseContentLength(header []byte) (int, error) {
+	for _, line := range strings.Split(string(header), "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found || !strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+
+		length, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length < 0 {
+			return 0, fmt.Errorf("invalid Content-Length header: %q", line)
+		}
+		return length, nil
+	}
+
+	return 0, fmt.Errorf("missing Content-Length header")
+}
@@ -0,0 +1,272 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenSource returns a bearer token for the Authorization header, for example
+// from an OAuth token endpoint.
+type TokenSource func(ctx context.Context) (string, error)
+
+// HTTPOption configures an HTTPTransport.
+type HTTPOption func(*HTTPTransport)
+
+// WithHTTPClient sends requests through client instead of
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(t *HTTPTransport) {
+		t.client = client
+	}
+}
+
+// WithHeaders adds headers to every request.
+func WithHeaders(headers map[string]string) HTTPOption {
+	return func(t *HTTPTransport) {
+		for k, v := range headers {
+			t.headers[k] = v
+		}
+	}
+}
+
+// WithTokenSource authorizes requests with a bearer token from source. The
+// token is fetched before the first Send and reused until the server answers
+// 401 Unauthorized, at which point a fresh one is fetched and the request
+// retried once. It takes precedence over an Authorization header set with
+// WithHeaders.
+func WithTokenSource(source TokenSource) HTTPOption {
+	return func(t *HTTPTransport) {
+		t.tokenSource = source
+	}
+}
+
+// HTTPTransport posts each JSON-RPC message to a URL and delivers the JSON
+// the server answers with to Receive.
+type HTTPTransport struct {
+	url         string
+	client      *http.Client
+	headers     map[string]string
+	tokenSource TokenSource
+	token       string
+	tokenMutex  sync.Mutex
+	incoming    chan *JSONRPCResponse
+	closed      chan struct{}
+	connected   bool
+	mutex       sync.Mutex
+}
+
+// NewHTTPTransport connects to the MCP endpoint at url. Nothing is sent until
+// the first request.
+func NewHTTPTransport(url string, opts ...HTTPOption) *HTTPTransport {
+	t := &HTTPTransport{
+		url:      url,
+		client:   http.DefaultClient,
+		headers:  make(map[string]string),
+		incoming: make(chan *JSONRPCResponse, 16),
+		closed:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+func (t *HTTPTransport) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.connected {
+		return errors.New("transport already started")
+	}
+
+	select {
+	case <-t.closed:
+		t.closed = make(chan struct{})
+	default:
+	}
+
+	t.connected = true
+	return nil
+}
+
+func (t *HTTPTransport) Send(request *JSONRPCRequest) error {
+	return t.SendWithContext(context.Background(), request)
+}
+
+func (t *HTTPTransport) SendWithContext(ctx context.Context, request *JSONRPCRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return t.post(ctx, body)
+}
+
+func (t *HTTPTransport) SendResponse(response *JSONRPCResponse) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return t.post(context.Background(), body)
+}
+
+func (t *HTTPTransport) post(ctx context.Context, body []byte) error {
+	if !t.IsConnected() {
+		return fmt.Errorf("transport not connected")
+	}
+
+	resp, err := t.do(ctx, body, false)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && t.tokenSource != nil {
+		resp.Body.Close()
+		if resp, err = t.do(ctx, body, true); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	responses, err := decodeFrame(data)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	closed := t.closed
+	t.mutex.Unlock()
+
+	for _, response := range responses {
+		select {
+		case t.incoming <- response:
+		case <-closed:
+			return fmt.Errorf("transport closed")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// do sends one POST, fetching a new token first if refresh is set or none is
+// cached yet.
+func (t *HTTPTransport) do(ctx context.Context, body []byte, refresh bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	if t.tokenSource != nil {
+		token, err := t.bearerToken(ctx, refresh)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (t *HTTPTransport) bearerToken(ctx context.Context, refresh bool) (string, error) {
+	t.tokenMutex.Lock()
+	defer t.tokenMutex.Unlock()
+
+	if t.token != "" && !refresh {
+		return t.token, nil
+	}
+
+	token, err := t.tokenSource(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	t.token = token
+	return token, nil
+}
+
+// decodeFrame decodes a body holding a single message or a batch. An empty
+// body, as sent for notifications, holds none.
+func decodeFrame(data []byte) ([]*JSONRPCResponse, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(trimmed, "[") {
+		response, err := decodeMessage([]byte(trimmed), trimmed)
+		if err != nil {
+			return nil, err
+		}
+		return []*JSONRPCResponse{response}, nil
+	}
+
+	return decodeBatch([]byte(trimmed), trimmed)
+}
+
+func (t *HTTPTransport) Receive() (*JSONRPCResponse, error) {
+	t.mutex.Lock()
+	connected, closed := t.connected, t.closed
+	t.mutex.Unlock()
+
+	if !connected {
+		return nil, fmt.Errorf("transport not connected")
+	}
+
+	select {
+	case response := <-t.incoming:
+		return response, nil
+	case <-closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *HTTPTransport) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.connected {
+		return nil
+	}
+
+	t.connected = false
+	close(t.closed)
+	return nil
+}
+
+func (t *HTTPTransport) IsConnected() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.connected
+}
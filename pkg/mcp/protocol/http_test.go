@@ -0,0 +1,149 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-mcp/pkg/mcp/protocol"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer answers every request with its own params and rejects requests
+// not carrying the bearer token in valid.
+func echoServer(t *testing.T, valid *atomic.Value) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req protocol.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.ID.IsZero() {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		params := req.Params
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+		params["tenant"] = r.Header.Get("X-Tenant")
+		json.NewEncoder(w).Encode(protocol.NewResponse(req.ID, params))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHTTPTransportTokenSource(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("token-1")
+	server := echoServer(t, &valid)
+
+	var mutex sync.Mutex
+	var issued int
+	source := func(ctx context.Context) (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		issued++
+		return fmt.Sprintf("token-%d", issued), nil
+	}
+
+	transport := protocol.NewHTTPTransport(server.URL,
+		protocol.WithTokenSource(source),
+		protocol.WithHeaders(map[string]string{"X-Tenant": "acme"}))
+	require.NoError(t, transport.Start())
+	defer transport.Close()
+
+	send := func(id string) {
+		t.Helper()
+		require.NoError(t, transport.Send(protocol.NewRequest(protocol.StringID(id), "echo", map[string]interface{}{"n": id})))
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID(id), response.ID)
+		assert.Equal(t, map[string]interface{}{"n": id, "tenant": "acme"}, response.Result)
+	}
+
+	t.Run("reuses the cached token", func(t *testing.T) {
+		send("1")
+		send("2")
+		assert.Equal(t, 1, issued)
+	})
+
+	t.Run("refreshes the token on 401", func(t *testing.T) {
+		valid.Store("token-2")
+		send("3")
+		send("4")
+		assert.Equal(t, 2, issued)
+	})
+
+	t.Run("gives up when the fresh token is rejected too", func(t *testing.T) {
+		valid.Store("never")
+		err := transport.Send(protocol.NewRequest(protocol.StringID("5"), "echo", nil))
+		assert.ErrorContains(t, err, "401")
+	})
+
+	t.Run("reports token source failures", func(t *testing.T) {
+		failing := protocol.NewHTTPTransport(server.URL, protocol.WithTokenSource(func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("token endpoint down")
+		}))
+		require.NoError(t, failing.Start())
+		defer failing.Close()
+
+		err := failing.Send(protocol.NewRequest(protocol.StringID("6"), "echo", nil))
+		assert.ErrorContains(t, err, "token endpoint down")
+	})
+}
+
+func TestHTTPTransportClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req protocol.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		responses := handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"ok": true})}
+		})(&req)
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		json.NewEncoder(w).Encode(responses[0])
+	}))
+	defer server.Close()
+
+	transport := protocol.NewHTTPTransport(server.URL, protocol.WithTokenSource(func(ctx context.Context) (string, error) {
+		return "secret", nil
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	result, err := client.CallTool(context.Background(), "anything", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ok": true}, result)
+
+	require.NoError(t, client.Disconnect())
+	_, err = transport.Receive()
+	assert.Error(t, err)
+}
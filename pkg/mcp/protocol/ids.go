@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator produces the IDs of the requests a Client sends, and the tokens
+// it uses to match progress notifications. NextID is called concurrently and
+// must not repeat an ID while the client is connected.
+type IDGenerator interface {
+	NextID() RequestID
+}
+
+// WithIDGenerator replaces the default random UUIDs with IDs from generator,
+// for example NewSequentialIDGenerator to get a reproducible wire format in
+// tests.
+func WithIDGenerator(generator IDGenerator) ClientOption {
+	return func(c *Client) {
+		c.ids = generator
+	}
+}
+
+type uuidGenerator struct{}
+
+// NewUUIDGenerator returns the default generator, which uses a random UUID
+// string for every ID.
+func NewUUIDGenerator() IDGenerator {
+	return uuidGenerator{}
+}
+
+func (uuidGenerator) NextID() RequestID {
+	return StringID(uuid.New().String())
+}
+
+type sequentialGenerator struct {
+	last atomic.Int64
+}
+
+// NewSequentialIDGenerator returns a generator of numeric IDs counting up
+// from 1.
+func NewSequentialIDGenerator() IDGenerator {
+	return &sequentialGenerator{}
+}
+
+func (g *sequentialGenerator) NextID() RequestID {
+	return NumberID(g.last.Add(1))
+}
@@ -0,0 +1,89 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDGenerator(t *testing.T) {
+	t.Run("sequential IDs give a reproducible wire format", func(t *testing.T) {
+		var mutex sync.Mutex
+		var frames []string
+
+		transport := newScriptedTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			frame, err := json.Marshal(req)
+			require.NoError(t, err)
+
+			mutex.Lock()
+			frames = append(frames, string(frame))
+			mutex.Unlock()
+
+			return handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{})}
+			})(req)
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithIDGenerator(protocol.NewSequentialIDGenerator()))
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		mutex.Lock()
+		handshake := len(frames)
+		mutex.Unlock()
+
+		_, err := client.CallTool(context.Background(), "echo", map[string]interface{}{"n": 1})
+		require.NoError(t, err)
+		_, err = client.CallToolWithProgress(context.Background(), "echo", nil, func(progress, total float64) {})
+		require.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.Contains(t, frames[0], `"method":"initialize"`)
+		assert.Contains(t, frames[0], `"id":1}`)
+		assert.Equal(t, []string{
+			`{"jsonrpc":"2.0","method":"echo","params":{"n":1},"id":4}`,
+			`{"jsonrpc":"2.0","method":"echo","params":{"_meta":{"progressToken":"5"}},"id":6}`,
+		}, frames[handshake:])
+	})
+
+	t.Run("UUIDs are unique strings", func(t *testing.T) {
+		generator := protocol.NewUUIDGenerator()
+		first, second := generator.NextID(), generator.NextID()
+
+		assert.False(t, first.IsNumber())
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("sequential IDs are unique across goroutines", func(t *testing.T) {
+		generator := protocol.NewSequentialIDGenerator()
+
+		var mutex sync.Mutex
+		seen := make(map[protocol.RequestID]bool)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					id := generator.NextID()
+					mutex.Lock()
+					seen[id] = true
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, seen, 800)
+		assert.True(t, seen[protocol.NumberID(800)])
+	})
+}
@@ -0,0 +1,163 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// InMemoryTransport is one end of an in-process connection created by
+// NewInMemoryPair. Messages are marshaled to JSON on the way through, so the
+// client sees exactly what it would over stdio. The end used by a fake server
+// reads requests with ReceiveRequest and answers with SendResponse.
+type InMemoryTransport struct {
+	incoming  <-chan []byte
+	outgoing  chan<- []byte
+	closed    chan struct{}
+	closeOnce *sync.Once
+	connected bool
+	mutex     sync.Mutex
+}
+
+// NewInMemoryPair returns two connected transports. Whatever one end sends,
+// the other receives. Closing either end closes the connection for both.
+func NewInMemoryPair() (*InMemoryTransport, *InMemoryTransport) {
+	aToB := make(chan []byte, 16)
+	bToA := make(chan []byte, 16)
+	closed := make(chan struct{})
+	once := &sync.Once{}
+
+	a := &InMemoryTransport{
+		incoming:  bToA,
+		outgoing:  aToB,
+		closed:    closed,
+		closeOnce: once,
+	}
+	b := &InMemoryTransport{
+		incoming:  aToB,
+		outgoing:  bToA,
+		closed:    closed,
+		closeOnce: once,
+	}
+
+	return a, b
+}
+
+func (t *InMemoryTransport) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.connected {
+		return errors.New("transport already started")
+	}
+
+	select {
+	case <-t.closed:
+		return errors.New("transport closed")
+	default:
+	}
+
+	t.connected = true
+	return nil
+}
+
+func (t *InMemoryTransport) Send(request *JSONRPCRequest) error {
+	return t.SendWithContext(context.Background(), request)
+}
+
+func (t *InMemoryTransport) SendWithContext(ctx context.Context, request *JSONRPCRequest) error {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return t.writeFrame(ctx, requestJSON)
+}
+
+func (t *InMemoryTransport) SendResponse(response *JSONRPCResponse) error {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return t.writeFrame(context.Background(), responseJSON)
+}
+
+func (t *InMemoryTransport) Receive() (*JSONRPCResponse, error) {
+	frame, err := t.readFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMessage(frame, string(frame))
+}
+
+func (t *InMemoryTransport) ReceiveRequest() (*JSONRPCRequest, error) {
+	frame, err := t.readFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	var request JSONRPCRequest
+	if err := json.Unmarshal(frame, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w, raw request: %s", err, frame)
+	}
+
+	return &request, nil
+}
+
+func (t *InMemoryTransport) writeFrame(ctx context.Context, frame []byte) error {
+	if !t.IsConnected() {
+		return fmt.Errorf("transport not connected")
+	}
+
+	select {
+	case t.outgoing <- frame:
+		return nil
+	case <-t.closed:
+		return fmt.Errorf("transport closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *InMemoryTransport) readFrame() ([]byte, error) {
+	if !t.IsConnected() {
+		return nil, fmt.Errorf("transport not connected")
+	}
+
+	select {
+	case frame := <-t.incoming:
+		return frame, nil
+	case <-t.closed:
+		t.mutex.Lock()
+		t.connected = false
+		t.mutex.Unlock()
+		return nil, io.EOF
+	}
+}
+
+func (t *InMemoryTransport) Close() error {
+	t.mutex.Lock()
+	t.connected = false
+	t.mutex.Unlock()
+
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+func (t *InMemoryTransport) IsConnected() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	select {
+	case <-t.closed:
+		return false
+	default:
+	}
+
+	return t.connected
+}
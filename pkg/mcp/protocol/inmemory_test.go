@@ -0,0 +1,179 @@
+package protocol_test
+
+import (
+	"context"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveInMemory runs handle for every request arriving on the server end of an
+// in-memory pair and returns the client end. A nil response sends nothing.
+func serveInMemory(t *testing.T, handle func(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse) *protocol.InMemoryTransport {
+	t.Helper()
+
+	clientEnd, serverEnd := protocol.NewInMemoryPair()
+	require.NoError(t, serverEnd.Start())
+
+	go func() {
+		for {
+			req, err := serverEnd.ReceiveRequest()
+			if err != nil {
+				return
+			}
+			if resp := handle(req); resp != nil {
+				if err := serverEnd.SendResponse(resp); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	t.Cleanup(func() { serverEnd.Close() })
+
+	return clientEnd
+}
+
+func TestInMemoryTransport(t *testing.T) {
+	t.Run("connects a real client to a fake server", func(t *testing.T) {
+		transport := serveInMemory(t, func(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			switch req.Method {
+			case "initialize":
+				return protocol.NewResponse(req.ID, initializeResult(req))
+			case "notifications/initialized":
+				return nil
+			case "mcp.list_tools":
+				return protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})
+			case "mcp.list_resources":
+				return protocol.NewResponse(req.ID, map[string]interface{}{"resources": []interface{}{}})
+			case "echo":
+				return protocol.NewResponse(req.ID, req.Params)
+			}
+			return protocol.NewErrorResponse(req.ID, protocol.ErrMethodNotFound, "method not found", nil)
+		})
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hello"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"text": "hello"}, result)
+
+		_, err = client.CallTool(ctx, "missing", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("closing one end disconnects the other", func(t *testing.T) {
+		a, b := protocol.NewInMemoryPair()
+		require.NoError(t, a.Start())
+		require.NoError(t, b.Start())
+
+		require.NoError(t, a.Close())
+
+		assert.False(t, b.IsConnected())
+		_, err := b.Receive()
+		assert.Error(t, err)
+	})
+
+	t.Run("validates messages like the other transports", func(t *testing.T) {
+		a, b := protocol.NewInMemoryPair()
+		require.NoError(t, a.Start())
+		require.NoError(t, b.Start())
+		defer a.Close()
+
+		require.NoError(t, b.SendResponse(&protocol.JSONRPCResponse{JSONRPC: "1.0", ID: protocol.StringID("1"), Result: "ok"}))
+		_, err := a.Receive()
+		var frameErr *protocol.FrameError
+		require.ErrorAs(t, err, &frameErr)
+		assert.Equal(t, []protocol.RequestID{protocol.StringID("1")}, frameErr.IDs)
+		assert.True(t, a.IsConnected())
+
+		require.NoError(t, b.SendResponse(protocol.NewResponse(protocol.StringID("2"), "ok")))
+		response, err := a.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, "ok", response.Result)
+	})
+}
+
+func TestClientCancellation(t *testing.T) {
+	cancelled := make(chan map[string]interface{}, 1)
+	slowID := make(chan protocol.RequestID, 1)
+
+	transport := serveInMemory(t, func(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		switch req.Method {
+		case "initialize":
+			return protocol.NewResponse(req.ID, initializeResult(req))
+		case "mcp.list_tools":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})
+		case "mcp.list_resources":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"resources": []interface{}{}})
+		case "slow":
+			slowID <- req.ID
+			return nil
+		case "notifications/cancelled":
+			cancelled <- req.Params
+			return nil
+		}
+		return nil
+	})
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CallTool(ctx, "slow", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case params := <-cancelled:
+		assert.Equal(t, (<-slowID).String(), params["requestId"])
+		assert.NotEmpty(t, params["reason"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a notifications/cancelled message")
+	}
+}
+
+func TestClientNotify(t *testing.T) {
+	notifications := make(chan *protocol.JSONRPCRequest, 1)
+
+	transport := serveInMemory(t, func(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		switch req.Method {
+		case "initialize":
+			return protocol.NewResponse(req.ID, initializeResult(req))
+		case "mcp.list_tools":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})
+		case "mcp.list_resources":
+			return protocol.NewResponse(req.ID, map[string]interface{}{"resources": []interface{}{}})
+		case "notifications/custom":
+			notifications <- req
+		}
+		return nil
+	})
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	assert.Error(t, client.Notify(context.Background(), "notifications/custom", nil), "not connected yet")
+
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	// The fake server never answers; Notify must not wait for it.
+	require.NoError(t, client.Notify(context.Background(), "notifications/custom", map[string]interface{}{"n": 1.0}))
+
+	select {
+	case req := <-notifications:
+		assert.True(t, req.ID.IsZero())
+		assert.Equal(t, map[string]interface{}{"n": 1.0}, req.Params)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the notification to reach the server")
+	}
+}
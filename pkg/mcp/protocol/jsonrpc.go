@@ -1,8 +1,10 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 const JSONRPCVersion = "2.0"
@@ -18,27 +20,112 @@ const (
 	ErrProtocolError  = -32002 // Protocol error
 )
 
+// RequestID is a JSON-RPC request ID, which the spec allows to be a string or
+// an integer. The kind is kept so replies echo the ID exactly as received; a
+// string "1" and a number 1 are different IDs. The zero value means no ID;
+// an empty string is a valid ID of its own.
+type RequestID struct {
+	value   string
+	numeric bool
+	present bool
+}
+
+func StringID(id string) RequestID {
+	return RequestID{value: id, present: true}
+}
+
+func NumberID(id int64) RequestID {
+	return RequestID{value: strconv.FormatInt(id, 10), numeric: true, present: true}
+}
+
+func (id RequestID) IsZero() bool {
+	return !id.present
+}
+
+func (id RequestID) IsNumber() bool {
+	return id.numeric
+}
+
+// String returns the ID's text, without quotes for either kind.
+func (id RequestID) String() string {
+	return id.value
+}
+
+func (id RequestID) MarshalJSON() ([]byte, error) {
+	if id.IsZero() {
+		return []byte("null"), nil
+	}
+	if id.numeric {
+		return []byte(id.value), nil
+	}
+	return json.Marshal(id.value)
+}
+
+func (id *RequestID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*id = RequestID{}
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = StringID(s)
+	default:
+		n, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid request ID %s: must be a string or an integer", data)
+		}
+		*id = NumberID(n)
+	}
+
+	return nil
+}
+
 type JSONRPCRequest struct {
 	JSONRPC string                 `json:"jsonrpc"`
-	ID      string                 `json:"id"`
+	ID      RequestID              `json:"id"` // Zero for notifications
 	Method  string                 `json:"method"`
-	Params  map[string]interface{} `json:"params"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// MarshalJSON leaves out the ID of notifications.
+func (r JSONRPCRequest) MarshalJSON() ([]byte, error) {
+	type request JSONRPCRequest
+
+	var id *RequestID
+	if !r.ID.IsZero() {
+		id = &r.ID
+	}
+
+	return json.Marshal(struct {
+		request
+		ID *RequestID `json:"id,omitempty"`
+	}{request(r), id})
 }
 
+// JSONRPCResponse is any message read from the server. Notifications sent by
+// the server arrive here too; they carry a Method and Params and no ID.
 type JSONRPCResponse struct {
-	JSONRPC string        `json:"jsonrpc"`
-	ID      string        `json:"id"`
-	Result  interface{}   `json:"result,omitempty"`
-	Error   *JSONRPCError `json:"error,omitempty"`
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      RequestID              `json:"id"`
+	Result  interface{}            `json:"result,omitempty"`
+	Error   *JSONRPCError          `json:"error,omitempty"`
+	Method  string                 `json:"method,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
 }
 
+// JSONRPCError is an error response from the peer. Client methods wrap it
+// rather than flattening it, so errors.As recovers the code and data.
 type JSONRPCError struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
-func NewRequest(id string, method string, params map[string]interface{}) *JSONRPCRequest {
+func NewRequest(id RequestID, method string, params map[string]interface{}) *JSONRPCRequest {
 	return &JSONRPCRequest{
 		JSONRPC: JSONRPCVersion,
 		ID:      id,
@@ -47,7 +134,17 @@ func NewRequest(id string, method string, params map[string]interface{}) *JSONRP
 	}
 }
 
-func NewResponse(id string, result interface{}) *JSONRPCResponse {
+// NewNotification builds a request without an ID, which the peer must not
+// answer.
+func NewNotification(method string, params map[string]interface{}) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		Method:  method,
+		Params:  params,
+	}
+}
+
+func NewResponse(id RequestID, result interface{}) *JSONRPCResponse {
 	return &JSONRPCResponse{
 		JSONRPC: JSONRPCVersion,
 		ID:      id,
@@ -55,7 +152,7 @@ func NewResponse(id string, result interface{}) *JSONRPCResponse {
 	}
 }
 
-func NewErrorResponse(id string, code int, message string, data interface{}) *JSONRPCResponse {
+func NewErrorResponse(id RequestID, code int, message string, data interface{}) *JSONRPCResponse {
 	return &JSONRPCResponse{
 		JSONRPC: JSONRPCVersion,
 		ID:      id,
@@ -75,6 +172,187 @@ func FromJSON(data []byte, obj interface{}) error {
 	return json.Unmarshal(data, obj)
 }
 
+// toParams converts a typed params struct into the map form JSONRPCRequest
+// carries.
+func toParams(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// decodeResult re-decodes a generically unmarshaled result into a typed value.
+func decodeResult(result interface{}, v interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// MarshalJSON leaves out the ID of notifications. Responses always carry one,
+// null when the request's ID could not be determined.
+func (r JSONRPCResponse) MarshalJSON() ([]byte, error) {
+	type response JSONRPCResponse
+
+	var id *RequestID
+	if !r.ID.IsZero() || r.Method == "" {
+		id = &r.ID
+	}
+
+	return json.Marshal(struct {
+		response
+		ID *RequestID `json:"id,omitempty"`
+	}{response(r), id})
+}
+
+// messageShape records which members a message actually carries, so that a
+// "result": null still counts as a result.
+type messageShape struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// FrameError reports an incoming frame that was read in full but does not
+// hold a valid message. The connection itself is unaffected, so a reader
+// should skip the frame rather than give up on the transport. IDs lists the
+// responses the frame was meant to deliver, as far as they can be recovered,
+// so that the calls waiting for them can be failed.
+type FrameError struct {
+	IDs []RequestID
+	Err error
+}
+
+func (e *FrameError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// decodeMessage unmarshals one incoming message and checks the version and,
+// for responses, that exactly one of result and error is present. Violations
+// are reported as ErrProtocolError with the raw line as data. Every error is
+// a *FrameError.
+func decodeMessage(data []byte, line string) (*JSONRPCResponse, error) {
+	var response JSONRPCResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, frameError(fmt.Errorf("failed to unmarshal response: %w, raw response: %s", err, line), data)
+	}
+
+	var shape messageShape
+	if err := json.Unmarshal(data, &shape); err != nil {
+		return nil, frameError(fmt.Errorf("failed to unmarshal response: %w, raw response: %s", err, line), data)
+	}
+
+	if shape.JSONRPC != JSONRPCVersion {
+		return nil, frameError(protocolError(line, "unsupported JSON-RPC version %q", shape.JSONRPC), data)
+	}
+
+	if shape.Method == "" {
+		hasResult, hasError := len(shape.Result) > 0, len(shape.Error) > 0
+		if hasResult == hasError {
+			return nil, frameError(protocolError(line, "response must carry exactly one of result and error"), data)
+		}
+	}
+
+	return &response, nil
+}
+
+// decodeBatch decodes a frame holding a JSON array of messages. A batch with
+// any invalid entry is rejected as a whole, naming every response ID in it.
+func decodeBatch(data []byte, line string) ([]*JSONRPCResponse, error) {
+	var frames []json.RawMessage
+	if err := json.Unmarshal(data, &frames); err != nil {
+		return nil, &FrameError{Err: fmt.Errorf("failed to unmarshal batch response: %w, raw response: %s", err, line)}
+	}
+	if len(frames) == 0 {
+		return nil, &FrameError{Err: fmt.Errorf("empty batch response")}
+	}
+
+	responses := make([]*JSONRPCResponse, len(frames))
+	for i, frame := range frames {
+		response, err := decodeMessage(frame, line)
+		if err != nil {
+			rejected := &FrameError{Err: err}
+			for _, frame := range frames {
+				rejected.IDs = append(rejected.IDs, frameError(nil, frame).IDs...)
+			}
+			return nil, rejected
+		}
+		responses[i] = response
+	}
+
+	return responses, nil
+}
+
+// frameError wraps err, recovering the ID of the response data was meant to
+// be if there is one.
+func frameError(err error, data []byte) *FrameError {
+	var envelope struct {
+		ID     RequestID `json:"id"`
+		Method string    `json:"method"`
+	}
+
+	rejected := &FrameError{Err: err}
+	if json.Unmarshal(data, &envelope) == nil && envelope.Method == "" && !envelope.ID.IsZero() {
+		rejected.IDs = []RequestID{envelope.ID}
+	}
+	return rejected
+}
+
+func protocolError(line string, format string, args ...interface{}) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    ErrProtocolError,
+		Message: fmt.Sprintf(format, args...),
+		Data:    line,
+	}
+}
+
+func (r *JSONRPCResponse) IsNotification() bool {
+	return r.ID.IsZero() && r.Method != ""
+}
+
+// IsRequest reports whether the message is a request sent by the server, such
+// as sampling/createMessage, which the client must answer.
+func (r *JSONRPCResponse) IsRequest() bool {
+	return !r.ID.IsZero() && r.Method != ""
+}
+
+// IsResponse reports whether the message answers a request made by the
+// client. It peeks at Method rather than ID alone, since a server request
+// carries an ID too.
+func (r *JSONRPCResponse) IsResponse() bool {
+	return r.Method == ""
+}
+
+// IsPartial reports whether the message is an intermediate result of a
+// request the server answers in several frames. Such a result carries
+// _meta.partial set to true; the request is complete only once a result
+// without it, or an error, arrives for the same ID.
+func (r *JSONRPCResponse) IsPartial() bool {
+	if !r.IsResponse() || r.Error != nil {
+		return false
+	}
+
+	result, ok := r.Result.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	meta, _ := result["_meta"].(map[string]interface{})
+	partial, _ := meta["partial"].(bool)
+	return partial
+}
+
 func (e *JSONRPCError) Error() string {
 	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
 }
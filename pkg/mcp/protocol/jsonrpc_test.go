@@ -0,0 +1,109 @@
+package protocol_test
+
+import (
+	"encoding/json"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Run("round-trips strings and numbers", func(t *testing.T) {
+		for raw, want := range map[string]protocol.RequestID{
+			`""`:    protocol.StringID(""),
+			`"abc"`: protocol.StringID("abc"),
+			`"1"`:   protocol.StringID("1"),
+			`1`:     protocol.NumberID(1),
+			`0`:     protocol.NumberID(0),
+			`-42`:   protocol.NumberID(-42),
+		} {
+			var id protocol.RequestID
+			require.NoError(t, json.Unmarshal([]byte(raw), &id), raw)
+			assert.Equal(t, want, id, raw)
+
+			data, err := json.Marshal(id)
+			require.NoError(t, err)
+			assert.Equal(t, raw, string(data))
+		}
+	})
+
+	t.Run("distinguishes string and numeric IDs", func(t *testing.T) {
+		assert.NotEqual(t, protocol.StringID("1"), protocol.NumberID(1))
+		assert.Equal(t, protocol.StringID("1").String(), protocol.NumberID(1).String())
+		assert.False(t, protocol.NumberID(0).IsZero())
+		assert.False(t, protocol.StringID("").IsZero())
+		assert.True(t, protocol.RequestID{}.IsZero())
+	})
+
+	t.Run("rejects other JSON values", func(t *testing.T) {
+		var id protocol.RequestID
+		assert.Error(t, json.Unmarshal([]byte(`1.5`), &id))
+		assert.Error(t, json.Unmarshal([]byte(`{}`), &id))
+	})
+
+	t.Run("decodes numeric IDs in responses", func(t *testing.T) {
+		var response protocol.JSONRPCResponse
+		require.NoError(t, json.Unmarshal([]byte(`{"jsonrpc": "2.0", "id": 7, "result": {}}`), &response))
+		assert.Equal(t, protocol.NumberID(7), response.ID)
+		assert.False(t, response.IsNotification())
+	})
+
+	t.Run("omits the ID of notifications", func(t *testing.T) {
+		data, err := json.Marshal(protocol.NewNotification("notifications/initialized", nil))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "notifications/initialized"}`, string(data))
+
+		data, err = json.Marshal(&protocol.JSONRPCResponse{JSONRPC: "2.0", Method: "notifications/progress"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "notifications/progress"}`, string(data))
+	})
+
+	t.Run("keeps an empty string ID", func(t *testing.T) {
+		var request protocol.JSONRPCRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"jsonrpc": "2.0", "id": "", "method": "ping"}`), &request))
+		assert.Equal(t, protocol.StringID(""), request.ID)
+
+		data, err := json.Marshal(request)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "id": "", "method": "ping"}`, string(data))
+	})
+
+	t.Run("uses a null ID for unattributable errors", func(t *testing.T) {
+		data, err := json.Marshal(protocol.NewErrorResponse(protocol.RequestID{}, protocol.ErrParseError, "parse error", nil))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "id": null, "error": {"code": -32700, "message": "parse error"}}`, string(data))
+	})
+}
+
+func TestClientAnswersNumericIDs(t *testing.T) {
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	serverEnd, messages, _ := connectWithServerEnd(t, client)
+
+	require.NoError(t, serverEnd.SendResponse(&protocol.JSONRPCResponse{
+		JSONRPC: protocol.JSONRPCVersion,
+		ID:      protocol.NumberID(7),
+		Method:  "ping",
+	}))
+
+	response := nextMessage(t, messages)
+	assert.Equal(t, protocol.NumberID(7), response.ID)
+	assert.Nil(t, response.Error)
+}
+
+func TestResponseIsPartial(t *testing.T) {
+	for raw, want := range map[string]bool{
+		`{"jsonrpc": "2.0", "id": 1, "result": {"_meta": {"partial": true}}}`:                 true,
+		`{"jsonrpc": "2.0", "id": 1, "result": {"_meta": {"partial": false}}}`:                false,
+		`{"jsonrpc": "2.0", "id": 1, "result": {"_meta": {"partial": "true"}}}`:               false,
+		`{"jsonrpc": "2.0", "id": 1, "result": {}}`:                                           false,
+		`{"jsonrpc": "2.0", "id": 1, "result": "done"}`:                                       false,
+		`{"jsonrpc": "2.0", "id": 1, "error": {"code": -32603, "message": "crashed"}}`:        false,
+		`{"jsonrpc": "2.0", "method": "notifications/progress", "params": {"partial": true}}`: false,
+	} {
+		var response protocol.JSONRPCResponse
+		require.NoError(t, json.Unmarshal([]byte(raw), &response), raw)
+		assert.Equal(t, want, response.IsPartial(), raw)
+	}
+}
@@ -0,0 +1,51 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// LogMessageHandler receives log messages sent by the server. logger is the
+// optional name of the server component that logged; data is passed through
+// undecoded since servers may log any JSON value.
+type LogMessageHandler func(level LoggingLevel, logger string, data json.RawMessage)
+
+// SetLogLevel asks the server to send log messages at level and above.
+func (c *Client) SetLogLevel(ctx context.Context, level LoggingLevel) error {
+	capabilities := c.GetServerCapabilities()
+	if capabilities == nil {
+		return ErrNotConnected
+	}
+	if err := c.Supports("logging/setLevel"); err != nil {
+		return err
+	}
+
+	response, err := c.call(ctx, "logging/setLevel", map[string]interface{}{"level": string(level)})
+	if err != nil {
+		return fmt.Errorf("logging/setLevel request failed: %w", err)
+	}
+
+	if response.Error != nil {
+		return response.Error
+	}
+
+	return nil
+}
+
+// OnLogMessage registers handler for notifications/message. Like other
+// notification handlers it runs on the client's read goroutine.
+func (c *Client) OnLogMessage(handler LogMessageHandler) {
+	c.OnNotification("notifications/message", func(params map[string]interface{}) {
+		level, _ := params["level"].(string)
+		logger, _ := params["logger"].(string)
+
+		data, err := json.Marshal(params["data"])
+		if err != nil {
+			c.logger.Warn("failed to encode log message data", "error", err)
+			return
+		}
+
+		handler(LoggingLevel(level), logger, data)
+	})
+}
@@ -0,0 +1,103 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLogging(t *testing.T) {
+	newClient := func(t *testing.T, logging bool, onSetLevel func(req *protocol.JSONRPCRequest)) (*protocol.Client, *protocol.InMemoryTransport) {
+		clientEnd, serverEnd := protocol.NewInMemoryPair()
+		require.NoError(t, serverEnd.Start())
+		t.Cleanup(func() { serverEnd.Close() })
+
+		go func() {
+			for {
+				req, err := serverEnd.ReceiveRequest()
+				if err != nil {
+					return
+				}
+
+				var result interface{}
+				switch req.Method {
+				case "initialize":
+					init := initializeResult(req)
+					if logging {
+						init["capabilities"].(map[string]interface{})["logging"] = map[string]interface{}{}
+					}
+					result = init
+				case "mcp.list_tools":
+					result = map[string]interface{}{"tools": []interface{}{}}
+				case "mcp.list_resources":
+					result = map[string]interface{}{"resources": []interface{}{}}
+				case "logging/setLevel":
+					onSetLevel(req)
+					result = map[string]interface{}{}
+				default:
+					continue
+				}
+				serverEnd.SendResponse(protocol.NewResponse(req.ID, result))
+			}
+		}()
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(clientEnd))
+		t.Cleanup(func() { client.Disconnect() })
+
+		return client, serverEnd
+	}
+
+	t.Run("sets the level and receives messages", func(t *testing.T) {
+		levels := make(chan interface{}, 1)
+		client, serverEnd := newClient(t, true, func(req *protocol.JSONRPCRequest) {
+			levels <- req.Params["level"]
+		})
+
+		type message struct {
+			level  protocol.LoggingLevel
+			logger string
+			data   string
+		}
+		messages := make(chan message, 1)
+		client.OnLogMessage(func(level protocol.LoggingLevel, logger string, data json.RawMessage) {
+			messages <- message{level, logger, string(data)}
+		})
+
+		require.NoError(t, client.SetLogLevel(context.Background(), protocol.LoggingLevelWarning))
+		assert.Equal(t, "warning", <-levels)
+
+		require.NoError(t, serverEnd.SendResponse(&protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Method:  "notifications/message",
+			Params: map[string]interface{}{
+				"level":  "error",
+				"logger": "db",
+				"data":   map[string]interface{}{"error": "connection lost"},
+			},
+		}))
+
+		select {
+		case got := <-messages:
+			assert.Equal(t, protocol.LoggingLevelError, got.level)
+			assert.Equal(t, "db", got.logger)
+			assert.JSONEq(t, `{"error": "connection lost"}`, got.data)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a log message")
+		}
+	})
+
+	t.Run("requires the logging capability", func(t *testing.T) {
+		client, _ := newClient(t, false, func(*protocol.JSONRPCRequest) {
+			t.Error("setLevel must not be sent")
+		})
+
+		err := client.SetLogLevel(context.Background(), protocol.LoggingLevelInfo)
+		assert.ErrorIs(t, err, protocol.ErrLoggingNotSupported)
+	})
+}
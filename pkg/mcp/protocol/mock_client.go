@@ -2,7 +2,6 @@ package protocol
 
 import (
 	"context"
-	"fmt"
 	"sync"
 )
 
@@ -11,6 +10,7 @@ type MockClient struct {
 	capabilities   *ServerCapabilities
 	tools          []Tool
 	resources      []Resource
+	templates      []ResourceTemplate
 	callToolResult interface{}
 	callToolError  error
 	mutex          sync.RWMutex
@@ -26,6 +26,14 @@ func NewMockClient() *MockClient {
 }
 
 func (c *MockClient) Connect(transport Transport) error {
+	return c.ConnectWithContext(context.Background(), transport)
+}
+
+func (c *MockClient) ConnectWithContext(ctx context.Context, transport Transport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	c.connected = true
@@ -63,7 +71,7 @@ func (c *MockClient) SetTools(tools []Tool) {
 	c.tools = tools
 }
 
-func (c *MockClient) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+func (c *MockClient) CallTool(ctx context.Context, name string, args map[string]interface{}, opts ...CallOption) (interface{}, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	return c.callToolResult, c.callToolError
@@ -81,7 +89,7 @@ func (c *MockClient) ListResources(ctx context.Context) ([]Resource, error) {
 	defer c.mutex.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client is not connected")
+		return nil, ErrNotConnected
 	}
 
 	return c.resources, nil
@@ -93,11 +101,28 @@ func (c *MockClient) SetResources(resources []Resource) {
 	c.resources = resources
 }
 
+func (c *MockClient) ListResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	return c.templates, nil
+}
+
+func (c *MockClient) SetResourceTemplates(templates []ResourceTemplate) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.templates = templates
+}
+
 func (c *MockClient) HealthCheck(ctx context.Context) error {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	if !c.connected {
-		return fmt.Errorf("client is not connected")
+		return ErrNotConnected
 	}
 	return nil
 }
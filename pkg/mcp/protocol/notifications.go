@@ -0,0 +1,94 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+)
+
+type NotificationHandler func(params map[string]interface{})
+
+type ProgressFunc func(progress, total float64)
+
+// notificationRouter fans server notifications out to the handlers registered
+// for their method. Progress notifications are additionally matched to the
+// call that owns their token. It also holds the handlers for requests the
+// server sends to the client.
+type notificationRouter struct {
+	handlers map[string][]NotificationHandler
+	progress map[string]NotificationHandler
+	requests map[string]requestHandler
+	mutex    sync.RWMutex
+}
+
+func newNotificationRouter() *notificationRouter {
+	return &notificationRouter{
+		handlers: make(map[string][]NotificationHandler),
+		progress: make(map[string]NotificationHandler),
+		requests: make(map[string]requestHandler),
+	}
+}
+
+// OnNotification registers handler for notifications with the given method.
+// Handlers run on the client's read goroutine and must return quickly.
+func (c *Client) OnNotification(method string, handler NotificationHandler) {
+	c.notifications.mutex.Lock()
+	defer c.notifications.mutex.Unlock()
+
+	c.notifications.handlers[method] = append(c.notifications.handlers[method], handler)
+}
+
+func (r *notificationRouter) dispatch(notification *JSONRPCResponse) {
+	if notification.Method == "notifications/progress" {
+		r.dispatchProgress(notification.Params)
+	}
+
+	r.mutex.RLock()
+	handlers := append([]NotificationHandler(nil), r.handlers[notification.Method]...)
+	r.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(notification.Params)
+	}
+}
+
+func (r *notificationRouter) dispatchProgress(params map[string]interface{}) {
+	// Tokens may be strings or numbers on the wire; ours are always strings,
+	// but normalise so a server echoing a number doesn't break matching.
+	token := fmt.Sprint(params["progressToken"])
+
+	r.mutex.RLock()
+	handler, exists := r.progress[token]
+	r.mutex.RUnlock()
+
+	if exists {
+		handler(params)
+	}
+}
+
+func (r *notificationRouter) addProgress(token string, onProgress ProgressFunc) {
+	if onProgress == nil {
+		return
+	}
+
+	r.addProgressHandler(token, func(params map[string]interface{}) {
+		progress, _ := params["progress"].(float64)
+		total, _ := params["total"].(float64)
+		onProgress(progress, total)
+	})
+}
+
+// addProgressHandler routes the raw params of every progress notification
+// carrying token to handler.
+func (r *notificationRouter) addProgressHandler(token string, handler NotificationHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.progress[token] = handler
+}
+
+func (r *notificationRouter) removeProgress(token string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.progress, token)
+}
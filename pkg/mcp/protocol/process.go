@@ -0,0 +1,31 @@
+package protocol
+
+import "time"
+
+// ProcessLimits caps the resources of a server process started by
+// StdioTransport, for sandboxing servers that are not trusted. Zero fields
+// are left unlimited.
+//
+// Limits are best effort. On Unix they are applied with the shell's ulimit
+// just before the server is executed, and a limit the system refuses is
+// reported on the server's stderr and otherwise ignored. On other platforms
+// they have no effect.
+type ProcessLimits struct {
+	// MaxMemoryBytes caps the server's virtual memory (RLIMIT_AS), rounded
+	// down to whole KiB.
+	MaxMemoryBytes uint64
+	// MaxCPUTime caps the CPU time the server may use (RLIMIT_CPU), rounded
+	// up to whole seconds. The server is killed once it has used it up.
+	MaxCPUTime time.Duration
+}
+
+// SetProcessLimits applies limits to the server process from the next Start
+// on. It also starts the server in a process group of its own, which Close
+// terminates as a whole so that children the server spawned do not outlive
+// it. Process groups, like limits, are only supported on Unix.
+func (t *StdioTransport) SetProcessLimits(limits ProcessLimits) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.limits = &limits
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package protocol
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// limitedCommand ignores limits, which are only supported on Unix.
+func limitedCommand(limits ProcessLimits, command string, args []string) *exec.Cmd {
+	return exec.Command(command, args...)
+}
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateProcess(cmd *exec.Cmd, group bool) error {
+	return cmd.Process.Signal(syscall.SIGTERM)
+}
+
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,55 @@
+//go:build unix
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// limitedCommand runs command through sh, which sets the limits with ulimit
+// and then replaces itself with the server.
+func limitedCommand(limits ProcessLimits, command string, args []string) *exec.Cmd {
+	var script strings.Builder
+	if limits.MaxMemoryBytes > 0 {
+		fmt.Fprintf(&script, "ulimit -v %d; ", max(limits.MaxMemoryBytes/1024, 1))
+	}
+	if limits.MaxCPUTime > 0 {
+		seconds := (limits.MaxCPUTime + time.Second - 1) / time.Second
+		fmt.Fprintf(&script, "ulimit -t %d; ", seconds)
+	}
+	script.WriteString(`exec "$0" "$@"`)
+
+	return exec.Command("/bin/sh", append([]string{"-c", script.String(), command}, args...)...)
+}
+
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcess sends SIGTERM to the server, or to its whole process group.
+func terminateProcess(cmd *exec.Cmd, group bool) error {
+	if !group {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+
+	err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// killProcessGroup kills whatever is left of the server's process group once
+// the server itself has exited.
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,83 @@
+//go:build unix
+
+package protocol_test
+
+import (
+	"bytes"
+	"fmt"
+	"go-mcp/pkg/mcp/protocol"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdioTransportProcessLimits(t *testing.T) {
+	t.Run("applies the limits", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c",
+			`echo "{\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"memory\":\"$(ulimit -v)\",\"cpu\":\"$(ulimit -t)\"}}"; cat`)
+		transport.SetProcessLimits(protocol.ProcessLimits{
+			MaxMemoryBytes: 512 * 1024 * 1024,
+			MaxCPUTime:     1500 * time.Millisecond,
+		})
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"memory": "524288", "cpu": "2"}, response.Result)
+	})
+
+	t.Run("passes arguments through unchanged", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c", `echo "$1"`, "sh",
+			`{"jsonrpc":"2.0","id":"with space","result":{}}`)
+		transport.SetProcessLimits(protocol.ProcessLimits{MaxCPUTime: time.Second})
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID("with space"), response.ID)
+	})
+
+	t.Run("Close stops the whole process group", func(t *testing.T) {
+		// The server starts a child that ignores SIGTERM and would otherwise
+		// outlive it.
+		transport := protocol.NewStdioTransportCommand("sh", "-c",
+			`(trap '' TERM; exec sleep 30) & echo "{\"jsonrpc\":\"2.0\",\"id\":\"$!\",\"result\":{}}"; wait`)
+		transport.SetProcessLimits(protocol.ProcessLimits{})
+		require.NoError(t, transport.Start())
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		child, err := strconv.Atoi(response.ID.String())
+		require.NoError(t, err)
+
+		require.NoError(t, transport.Close())
+
+		assert.Eventually(t, func() bool {
+			return !processAlive(child)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+// processAlive reports whether pid is running. A zombie, which an init that
+// does not reap may leave behind, counts as gone.
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	// The state follows the command name, which is in parentheses.
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
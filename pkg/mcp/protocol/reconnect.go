@@ -0,0 +1,79 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WithTransportFactory makes Reconnect connect through a new transport from
+// factory instead of restarting the previous one. Transports that cannot be
+// started again once closed, such as InMemoryTransport, need one.
+func WithTransportFactory(factory func() (Transport, error)) ClientOption {
+	return func(c *Client) {
+		c.transportFactory = factory
+	}
+}
+
+// Reconnect closes the current connection, if any, and connects again with the
+// same client info and handlers, returning the capabilities the server
+// announces this time. It reuses the transport of the last Connect, which is
+// started again, unless WithTransportFactory supplies a new one.
+//
+// Concurrent calls are serialized, and a call that waited for another one to
+// reconnect returns its result rather than reconnecting a second time.
+func (c *Client) Reconnect(ctx context.Context) (*ServerCapabilities, error) {
+	c.mutex.RLock()
+	seen := c.connections
+	c.mutex.RUnlock()
+
+	c.reconnectMutex.Lock()
+	defer c.reconnectMutex.Unlock()
+
+	c.mutex.Lock()
+	if c.connections != seen && c.transport != nil && c.transport.IsConnected() {
+		c.mutex.Unlock()
+		return c.GetServerCapabilities(), nil
+	}
+
+	transport, dispatcher := c.transport, c.dispatcher
+	if transport == nil {
+		transport = c.lastTransport
+	}
+	c.transport = nil
+	c.dispatcher = nil
+	c.mutex.Unlock()
+
+	if transport == nil && c.transportFactory == nil {
+		return nil, errors.New("client has never connected")
+	}
+
+	if transport != nil {
+		transport.Close()
+	}
+
+	// The old read loop must be gone before a restarted transport is read
+	// again, or it could take responses meant for the new one.
+	if dispatcher != nil {
+		select {
+		case <-dispatcher.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if c.transportFactory != nil {
+		var err error
+		if transport, err = c.transportFactory(); err != nil {
+			return nil, fmt.Errorf("failed to create transport: %w", err)
+		}
+	}
+
+	if err := c.ConnectWithContext(ctx, transport); err != nil {
+		return nil, fmt.Errorf("reconnect failed: %w", err)
+	}
+
+	c.logger.Info("reconnected")
+
+	return c.GetServerCapabilities(), nil
+}
@@ -0,0 +1,142 @@
+package protocol_test
+
+import (
+	"context"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReconnect(t *testing.T) {
+	var handshakes atomic.Int32
+	var logging atomic.Bool
+
+	handshake := handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse { return nil })
+	handler := func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		if req.Method != "initialize" {
+			return handshake(req)
+		}
+		handshakes.Add(1)
+
+		result := initializeResult(req)
+		if logging.Load() {
+			result["capabilities"] = map[string]interface{}{"logging": map[string]interface{}{}}
+		}
+		return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, result)}
+	}
+
+	t.Run("restarts the previous transport", func(t *testing.T) {
+		handshakes.Store(0)
+		transport := newRestartableTransport(handler)
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		_, err := client.Reconnect(context.Background())
+		assert.Error(t, err, "nothing to reconnect yet")
+
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		// The connection drops and the server comes back with new capabilities.
+		transport.Close()
+		assert.False(t, client.IsConnected())
+		logging.Store(true)
+
+		capabilities, err := client.Reconnect(context.Background())
+		require.NoError(t, err)
+		assert.True(t, client.IsConnected())
+		assert.NotNil(t, capabilities.Logging)
+		assert.Equal(t, capabilities, client.GetServerCapabilities())
+		assert.Equal(t, int32(2), handshakes.Load())
+
+		// Reconnecting a live connection replaces it.
+		_, err = client.Reconnect(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), handshakes.Load())
+	})
+
+	t.Run("uses the transport factory", func(t *testing.T) {
+		handshakes.Store(0)
+
+		var created atomic.Int32
+		release := make(chan struct{})
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithTransportFactory(func() (protocol.Transport, error) {
+				// Hold the reconnect after the drop until every caller is waiting.
+				if created.Add(1) == 2 {
+					<-release
+				}
+				return newScriptedTransport(handler), nil
+			}))
+
+		_, err := client.Reconnect(context.Background())
+		require.NoError(t, err)
+		defer client.Disconnect()
+
+		// Concurrent reconnects after a drop connect only once.
+		client.Disconnect()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.Reconnect(context.Background())
+				assert.NoError(t, err)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.True(t, client.IsConnected())
+		assert.Equal(t, int32(2), created.Load())
+		assert.Equal(t, int32(2), handshakes.Load())
+	})
+}
+
+// restartableTransport is a scriptedTransport that can be started again after
+// Close, like a stdio transport relaunching its process.
+type restartableTransport struct {
+	handler func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse
+	mutex   sync.Mutex
+	current *scriptedTransport
+}
+
+func newRestartableTransport(handler func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse) *restartableTransport {
+	return &restartableTransport{handler: handler, current: newScriptedTransport(handler)}
+}
+
+func (t *restartableTransport) session() *scriptedTransport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.current
+}
+
+func (t *restartableTransport) Start() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.current.IsConnected() {
+		t.current = newScriptedTransport(t.handler)
+	}
+	return t.current.Start()
+}
+
+func (t *restartableTransport) Send(req *protocol.JSONRPCRequest) error {
+	return t.session().Send(req)
+}
+
+func (t *restartableTransport) SendWithContext(ctx context.Context, req *protocol.JSONRPCRequest) error {
+	return t.session().SendWithContext(ctx, req)
+}
+
+func (t *restartableTransport) Receive() (*protocol.JSONRPCResponse, error) {
+	return t.session().Receive()
+}
+
+func (t *restartableTransport) Close() error      { return t.session().Close() }
+func (t *restartableTransport) IsConnected() bool { return t.session().IsConnected() }
@@ -0,0 +1,90 @@
+package protocol
+
+import "errors"
+
+// RedactedValue replaces the value of a sensitive argument wherever arguments
+// are logged, audited or echoed in error messages.
+const RedactedValue = "***"
+
+// errRedactedValue stands in for a validation error about a sensitive value,
+// since most such errors quote the value itself.
+var errRedactedValue = errors.New("invalid value (redacted)")
+
+// isSensitiveSchema reports whether a property schema is marked with
+// "x-sensitive": true.
+func isSensitiveSchema(schema map[string]interface{}) bool {
+	sensitive, _ := schema["x-sensitive"].(bool)
+	return sensitive
+}
+
+// IsSensitive reports whether the top-level argument name holds a secret,
+// either because it is listed in SensitiveFields or because its property
+// schema sets "x-sensitive": true.
+func (t *Tool) IsSensitive(name string) bool {
+	for _, field := range t.SensitiveFields {
+		if field == name {
+			return true
+		}
+	}
+
+	props, _ := t.InputSchema["properties"].(map[string]interface{})
+	propSchema, _ := props[name].(map[string]interface{})
+	return isSensitiveSchema(propSchema)
+}
+
+// RedactArguments returns a copy of args with the value of every sensitive
+// argument, including properties of nested objects marked "x-sensitive",
+// replaced by RedactedValue. args itself is left untouched.
+func (t *Tool) RedactArguments(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+
+	props, _ := t.InputSchema["properties"].(map[string]interface{})
+
+	redacted := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if t.IsSensitive(name) {
+			redacted[name] = RedactedValue
+			continue
+		}
+		propSchema, _ := props[name].(map[string]interface{})
+		redacted[name] = redactValue(propSchema, value)
+	}
+
+	return redacted
+}
+
+// redactValue redacts the sensitive properties of value, an object or array
+// described by schema. Values that need no redaction are returned as is.
+func redactValue(schema map[string]interface{}, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		props, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			return value
+		}
+		redacted := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			propSchema, _ := props[name].(map[string]interface{})
+			if isSensitiveSchema(propSchema) {
+				redacted[name] = RedactedValue
+				continue
+			}
+			redacted[name] = redactValue(propSchema, item)
+		}
+		return redacted
+	case []interface{}:
+		itemSchema, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return value
+		}
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactValue(itemSchema, item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,111 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+func sensitiveTool() *protocol.Tool {
+	return &protocol.Tool{
+		Name: "login",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"user":     map[string]interface{}{"type": "string"},
+				"password": map[string]interface{}{"type": "string", "minLength": 8, "x-sensitive": true},
+				"apiKey":   map[string]interface{}{"type": "string", "pattern": "^key-"},
+				"proxy": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"host":  map[string]interface{}{"type": "string"},
+						"token": map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b"}, "x-sensitive": true},
+					},
+				},
+			},
+		},
+		SensitiveFields: []string{"apiKey"},
+	}
+}
+
+func TestRedactArguments(t *testing.T) {
+	tool := sensitiveTool()
+
+	t.Run("marks fields from the schema and the tool", func(t *testing.T) {
+		assert.True(t, tool.IsSensitive("password"))
+		assert.True(t, tool.IsSensitive("apiKey"))
+		assert.False(t, tool.IsSensitive("user"))
+		assert.False(t, tool.IsSensitive("missing"))
+	})
+
+	t.Run("redacts sensitive values at any depth", func(t *testing.T) {
+		args := map[string]interface{}{
+			"user":     "ada",
+			"password": "hunter22",
+			"apiKey":   "key-123",
+			"proxy":    map[string]interface{}{"host": "example.com", "token": "a"},
+		}
+
+		redacted := tool.RedactArguments(args)
+
+		assert.Equal(t, map[string]interface{}{
+			"user":     "ada",
+			"password": protocol.RedactedValue,
+			"apiKey":   protocol.RedactedValue,
+			"proxy":    map[string]interface{}{"host": "example.com", "token": protocol.RedactedValue},
+		}, redacted)
+		assert.Equal(t, "hunter22", args["password"], "the arguments are left untouched")
+		assert.Equal(t, "a", args["proxy"].(map[string]interface{})["token"])
+	})
+
+	t.Run("handles nil arguments", func(t *testing.T) {
+		assert.Nil(t, tool.RedactArguments(nil))
+	})
+}
+
+func TestValidateArgumentsRedactsSensitiveValues(t *testing.T) {
+	tool := sensitiveTool()
+
+	tests := []struct {
+		name   string
+		args   map[string]interface{}
+		path   string
+		secret string
+	}{
+		{
+			name:   "schema marked field",
+			args:   map[string]interface{}{"password": "hunter2"},
+			path:   "password",
+			secret: "hunter2",
+		},
+		{
+			name:   "field listed on the tool",
+			args:   map[string]interface{}{"apiKey": "secret-123"},
+			path:   "apiKey",
+			secret: "secret-123",
+		},
+		{
+			name:   "nested field",
+			args:   map[string]interface{}{"proxy": map[string]interface{}{"token": "leaked"}},
+			path:   "proxy.token",
+			secret: "leaked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tool.ValidateArguments(tt.args)
+			require.Error(t, err)
+			assert.Equal(t, "invalid argument "+tt.path+": invalid value (redacted)", err.Error())
+			assert.NotContains(t, err.Error(), tt.secret)
+		})
+	}
+
+	t.Run("other fields keep their details", func(t *testing.T) {
+		err := tool.ValidateArguments(map[string]interface{}{"user": 42})
+		assert.EqualError(t, err, "invalid argument user: expected string, got int")
+	})
+}
@@ -0,0 +1,126 @@
+package protocol
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// WithResourceCache makes ReadResource reuse the result of an earlier read of
+// the same URI for up to ttl. At most maxEntries results are kept, evicting
+// the least recently used; zero means no limit. An entry is dropped when the
+// server sends notifications/resources/updated for its URI, and the whole
+// cache when the client connects again.
+func WithResourceCache(ttl time.Duration, maxEntries int) ClientOption {
+	return func(c *Client) {
+		c.resources = newResourceCache(ttl, maxEntries)
+		c.OnNotification("notifications/resources/updated", func(params map[string]interface{}) {
+			if uri, ok := params["uri"].(string); ok {
+				c.resources.invalidate(uri)
+			}
+		})
+	}
+}
+
+// resourceCache is an LRU cache of resources/read results keyed by URI.
+type resourceCache struct {
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Most recently used at the front
+	now        func() time.Time
+
+	// generation changes on every invalidation, so a read that was in flight
+	// meanwhile does not store what may be a stale result.
+	generation uint64
+
+	mutex sync.Mutex
+}
+
+type resourceEntry struct {
+	uri     string
+	result  *ReadResourceResult
+	expires time.Time
+}
+
+func newResourceCache(ttl time.Duration, maxEntries int) *resourceCache {
+	return &resourceCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (c *resourceCache) get(uri string) (*ReadResourceResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, exists := c.entries[uri]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*resourceEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.result, true
+}
+
+func (c *resourceCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// put stores result unless the cache was invalidated since generation was
+// read.
+func (c *resourceCache) put(uri string, result *ReadResourceResult, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, exists := c.entries[uri]; exists {
+		c.remove(element)
+	}
+
+	c.entries[uri] = c.order.PushFront(&resourceEntry{uri: uri, result: result, expires: c.now().Add(c.ttl)})
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *resourceCache) invalidate(uri string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	if element, exists := c.entries[uri]; exists {
+		c.remove(element)
+	}
+}
+
+func (c *resourceCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// remove must be called with c.mutex held.
+func (c *resourceCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*resourceEntry).uri)
+}
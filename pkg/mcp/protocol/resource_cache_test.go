@@ -0,0 +1,162 @@
+package protocol_test
+
+import (
+	"context"
+	"fmt"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReadResource(t *testing.T) {
+	// connect returns a client of a server that answers resources/read with
+	// the URI and the number of reads it has served so far, so a changed
+	// text means the read reached the server. Every read is counted in reads.
+	connect := func(t *testing.T, opts ...protocol.ClientOption) (*protocol.Client, *scriptedTransport, func() int) {
+		var mutex sync.Mutex
+		reads := 0
+
+		transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			if req.Method != "resources/read" {
+				return nil
+			}
+
+			mutex.Lock()
+			reads++
+			served := reads
+			mutex.Unlock()
+
+			uri := req.Params["uri"].(string)
+			if uri == "file:///missing" {
+				return []*protocol.JSONRPCResponse{protocol.NewErrorResponse(req.ID, protocol.ErrInvalidParams, "resource not found", nil)}
+			}
+
+			contents := []interface{}{
+				map[string]interface{}{"uri": uri, "mimeType": "text/plain", "text": fmt.Sprintf("%s #%d", uri, served)},
+			}
+			if uri == "file:///logo.png" {
+				contents = []interface{}{
+					map[string]interface{}{"uri": uri, "mimeType": "image/png", "blob": "iVBORw0KGgo="},
+				}
+			}
+			return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"contents": contents})}
+		}))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"}, opts...)
+		require.NoError(t, client.Connect(transport))
+		t.Cleanup(func() { client.Disconnect() })
+
+		return client, transport, func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return reads
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	text := func(t *testing.T, client *protocol.Client, uri string) string {
+		t.Helper()
+
+		result, err := client.ReadResource(ctx, uri)
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		contents, ok := result.Contents[0].(protocol.TextResourceContents)
+		require.True(t, ok, "expected text contents, got %T", result.Contents[0])
+		return contents.Text
+	}
+
+	t.Run("decodes text and blob contents", func(t *testing.T) {
+		client, _, _ := connect(t)
+
+		assert.Equal(t, "file:///notes.txt #1", text(t, client, "file:///notes.txt"))
+
+		result, err := client.ReadResource(ctx, "file:///logo.png")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{protocol.BlobResourceContents{
+			ResourceContents: protocol.ResourceContents{URI: "file:///logo.png", MimeType: "image/png"},
+			Blob:             "iVBORw0KGgo=",
+		}}, result.Contents)
+	})
+
+	t.Run("reads through without a cache", func(t *testing.T) {
+		client, _, reads := connect(t)
+
+		text(t, client, "file:///notes.txt")
+		assert.Equal(t, "file:///notes.txt #2", text(t, client, "file:///notes.txt"))
+		assert.Equal(t, 2, reads())
+	})
+
+	t.Run("serves repeated reads from the cache", func(t *testing.T) {
+		client, _, reads := connect(t, protocol.WithResourceCache(time.Minute, 0))
+
+		assert.Equal(t, "file:///notes.txt #1", text(t, client, "file:///notes.txt"))
+		assert.Equal(t, "file:///notes.txt #1", text(t, client, "file:///notes.txt"))
+		assert.Equal(t, 1, reads())
+
+		assert.Equal(t, "file:///todo.txt #2", text(t, client, "file:///todo.txt"))
+		assert.Equal(t, 2, reads())
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		client, _, reads := connect(t, protocol.WithResourceCache(time.Minute, 0))
+
+		for i := 0; i < 2; i++ {
+			_, err := client.ReadResource(ctx, "file:///missing")
+			var rpcErr *protocol.JSONRPCError
+			require.ErrorAs(t, err, &rpcErr)
+			assert.Equal(t, "resource not found", rpcErr.Message)
+		}
+		assert.Equal(t, 2, reads())
+	})
+
+	t.Run("drops an entry when the server reports an update", func(t *testing.T) {
+		client, transport, reads := connect(t, protocol.WithResourceCache(time.Minute, 0))
+
+		text(t, client, "file:///notes.txt")
+		text(t, client, "file:///todo.txt")
+
+		transport.responses <- &protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Method:  "notifications/resources/updated",
+			Params:  map[string]interface{}{"uri": "file:///notes.txt"},
+		}
+
+		// The notification is handled asynchronously, so poll until a read
+		// reaches the server.
+		assert.Eventually(t, func() bool {
+			return text(t, client, "file:///notes.txt") != "file:///notes.txt #1"
+		}, 5*time.Second, 10*time.Millisecond)
+
+		served := reads()
+		assert.Equal(t, "file:///todo.txt #2", text(t, client, "file:///todo.txt"))
+		assert.Equal(t, served, reads())
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		client, _, _ := connect(t, protocol.WithResourceCache(20*time.Millisecond, 0))
+
+		assert.Equal(t, "file:///notes.txt #1", text(t, client, "file:///notes.txt"))
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, "file:///notes.txt #2", text(t, client, "file:///notes.txt"))
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		client, _, reads := connect(t, protocol.WithResourceCache(time.Minute, 2))
+
+		text(t, client, "file:///a")
+		text(t, client, "file:///b")
+		text(t, client, "file:///a")
+		text(t, client, "file:///c")
+		assert.Equal(t, 3, reads())
+
+		text(t, client, "file:///a")
+		assert.Equal(t, 3, reads())
+		assert.Equal(t, "file:///b #4", text(t, client, "file:///b"))
+	})
+}
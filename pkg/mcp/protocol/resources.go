@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ReadResourceResult is the answer to resources/read. Each item of Contents
+// is a TextResourceContents or a BlobResourceContents.
+type ReadResourceResult struct {
+	Contents []interface{}
+}
+
+// ReadResource fetches the contents of the resource at uri. With
+// WithResourceCache, a result read earlier may be returned instead; it is
+// shared between callers, who must not modify it.
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var generation uint64
+	if c.resources != nil {
+		if result, hit := c.resources.get(uri); hit {
+			return result, nil
+		}
+		generation = c.resources.currentGeneration()
+	}
+
+	if err := c.Supports("resources/read"); err != nil {
+		return nil, fmt.Errorf("resources/read request failed: %w", err)
+	}
+
+	response, err := c.call(ctx, "resources/read", map[string]interface{}{"uri": uri})
+	if err != nil {
+		return nil, fmt.Errorf("resources/read request failed: %w", err)
+	}
+
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	result, err := decodeReadResourceResult(response.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.resources != nil {
+		c.resources.put(uri, result, generation)
+	}
+
+	return result, nil
+}
+
+func decodeReadResourceResult(raw interface{}) (*ReadResourceResult, error) {
+	resultMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid resources/read response format")
+	}
+
+	items, ok := resultMap["contents"].([]interface{})
+	if !ok {
+		return nil, errors.New("invalid or missing contents array in response")
+	}
+
+	result := &ReadResourceResult{Contents: make([]interface{}, 0, len(items))}
+	for i, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("resource contents item %d: expected an object, got %T", i, item)
+		}
+
+		var err error
+		if _, isBlob := itemMap["blob"]; isBlob {
+			var blob BlobResourceContents
+			err = decodeResult(itemMap, &blob)
+			result.Contents = append(result.Contents, blob)
+		} else {
+			var text TextResourceContents
+			err = decodeResult(itemMap, &text)
+			result.Contents = append(result.Contents, text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resource contents item %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
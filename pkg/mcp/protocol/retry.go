@@ -0,0 +1,78 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BackoffFunc returns how long to wait before retry number attempt, starting
+// at 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff doubles base after every attempt.
+func ExponentialBackoff(base time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return base << (attempt - 1)
+	}
+}
+
+// WithRetry retries ListTools, ListResources and HealthCheck up to
+// maxAttempts times in total when the request fails at the transport level.
+// Error responses from the server are never retried, and neither is CallTool,
+// since tool calls may have side effects.
+func WithRetry(maxAttempts int, backoff BackoffFunc) ClientOption {
+	return func(c *Client) {
+		c.retryAttempts = maxAttempts
+		c.retryBackoff = backoff
+	}
+}
+
+// callWithRetry is call with the client's retry policy applied, after checking
+// that the server supports method. A JSON-RPC error response counts as an
+// answer and is returned as is.
+func (c *Client) callWithRetry(ctx context.Context, method string, params map[string]interface{}) (*JSONRPCResponse, error) {
+	if err := c.Supports(method); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := c.call(ctx, method, params)
+		if err == nil || attempt >= c.retryAttempts || !isRetryable(ctx, err) {
+			return response, err
+		}
+
+		var wait time.Duration
+		if c.retryBackoff != nil {
+			wait = c.retryBackoff(attempt)
+		}
+
+		c.logger.Warn("retrying request", "method", method, "attempt", attempt, "wait", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	// Neither goes away by asking again.
+	if errors.Is(err, ErrCapabilityNotSupported) || errors.Is(err, ErrNotConnected) {
+		return false
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,177 @@
+package protocol_test
+
+import (
+	"context"
+	"errors"
+	"go-mcp/pkg/mcp/protocol"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyTransport fails the next failures sends with a transport error.
+type flakyTransport struct {
+	*scriptedTransport
+	mutex    sync.Mutex
+	failures int
+	sends    map[string]int
+}
+
+func newFlakyTransport(handler func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse) *flakyTransport {
+	return &flakyTransport{
+		scriptedTransport: newScriptedTransport(handler),
+		sends:             make(map[string]int),
+	}
+}
+
+func (t *flakyTransport) failNext(n int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failures = n
+}
+
+func (t *flakyTransport) sendCount(method string) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.sends[method]
+}
+
+func (t *flakyTransport) Send(req *protocol.JSONRPCRequest) error {
+	return t.SendWithContext(context.Background(), req)
+}
+
+func (t *flakyTransport) SendWithContext(ctx context.Context, req *protocol.JSONRPCRequest) error {
+	t.mutex.Lock()
+	t.sends[req.Method]++
+	fail := t.failures > 0
+	if fail {
+		t.failures--
+	}
+	t.mutex.Unlock()
+
+	if fail {
+		return errors.New("broken pipe")
+	}
+	return t.scriptedTransport.SendWithContext(ctx, req)
+}
+
+func TestClientRetry(t *testing.T) {
+	newClient := func(t *testing.T, attempts int) (*protocol.Client, *flakyTransport) {
+		transport := newFlakyTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return []*protocol.JSONRPCResponse{
+				protocol.NewErrorResponse(req.ID, protocol.ErrMethodNotFound, "method not found", nil),
+			}
+		}))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithRetry(attempts, protocol.ExponentialBackoff(time.Millisecond)))
+		require.NoError(t, client.Connect(transport))
+		t.Cleanup(func() { client.Disconnect() })
+
+		return client, transport
+	}
+
+	t.Run("retries transport failures", func(t *testing.T) {
+		client, transport := newClient(t, 3)
+		before := transport.sendCount("mcp.list_tools")
+
+		transport.failNext(2)
+		_, err := client.ListTools(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, before+3, transport.sendCount("mcp.list_tools"))
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		client, transport := newClient(t, 2)
+		before := transport.sendCount("mcp.list_resources")
+
+		transport.failNext(5)
+		_, err := client.ListResources(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, before+2, transport.sendCount("mcp.list_resources"))
+	})
+
+	t.Run("does not retry protocol errors", func(t *testing.T) {
+		client, transport := newClient(t, 3)
+
+		err := client.HealthCheck(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 1, transport.sendCount("ping"))
+	})
+
+	t.Run("does not retry tool calls", func(t *testing.T) {
+		client, transport := newClient(t, 3)
+
+		transport.failNext(1)
+		_, err := client.CallTool(context.Background(), "echo", nil)
+		assert.Error(t, err)
+		assert.Equal(t, 1, transport.sendCount("echo"))
+	})
+
+	t.Run("does not retry unsupported capabilities", func(t *testing.T) {
+		transport := newFlakyTransport(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			switch req.Method {
+			case "initialize":
+				result := initializeResult(req)
+				result["capabilities"] = map[string]interface{}{"tools": map[string]interface{}{}}
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, result)}
+			case "mcp.list_tools":
+				return []*protocol.JSONRPCResponse{protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})}
+			}
+			return nil
+		})
+		logger := &recordingLogger{}
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithRetry(3, func(int) time.Duration { return time.Hour }), protocol.WithLogger(logger))
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		_, err := client.ListResources(context.Background())
+		assert.ErrorIs(t, err, protocol.ErrResourcesNotSupported)
+		assert.Equal(t, 0, transport.sendCount("mcp.list_resources"))
+
+		logger.mutex.Lock()
+		defer logger.mutex.Unlock()
+		assert.Empty(t, logger.messages["warn"])
+	})
+
+	t.Run("does not retry once disconnected", func(t *testing.T) {
+		transport := newFlakyTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return nil
+		}))
+		logger := &recordingLogger{}
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithRetry(3, func(int) time.Duration { return time.Hour }), protocol.WithLogger(logger))
+		require.NoError(t, client.Connect(transport))
+		transport.Close()
+
+		_, err := client.ListTools(context.Background())
+		assert.ErrorIs(t, err, protocol.ErrNotConnected)
+
+		logger.mutex.Lock()
+		defer logger.mutex.Unlock()
+		assert.NotContains(t, logger.messages["warn"], "retrying request")
+	})
+
+	t.Run("respects the context deadline", func(t *testing.T) {
+		transport := newFlakyTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+			return nil
+		}))
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"},
+			protocol.WithRetry(100, func(int) time.Duration { return time.Hour }))
+		require.NoError(t, client.Connect(transport))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		transport.failNext(100)
+		start := time.Now()
+		_, err := client.ListTools(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
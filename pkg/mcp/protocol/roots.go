@@ -0,0 +1,26 @@
+package protocol
+
+import "context"
+
+// SetRoots sets the filesystem roots exposed to the server through roots/list.
+// Roots set before Connect are advertised in the roots capability; changing
+// them on a connected client sends notifications/roots/list_changed.
+func (c *Client) SetRoots(roots []Root) error {
+	c.mutex.Lock()
+	c.roots = append([]Root{}, roots...)
+	transport := c.transport
+	c.mutex.Unlock()
+
+	c.notifications.setRequestHandler("roots/list", func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		c.mutex.RLock()
+		defer c.mutex.RUnlock()
+
+		return map[string]interface{}{"roots": c.roots}, nil
+	})
+
+	if transport == nil || !transport.IsConnected() {
+		return nil
+	}
+
+	return c.Notify(context.Background(), "notifications/roots/list_changed", nil)
+}
@@ -0,0 +1,41 @@
+package protocol_test
+
+import (
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRoots(t *testing.T) {
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.SetRoots([]protocol.Root{{URI: "file:///workspace", Name: "workspace"}}))
+
+	serverEnd, messages, initParams := connectWithServerEnd(t, client)
+
+	capabilities := (<-initParams)["capabilities"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"listChanged": true}, capabilities["roots"])
+
+	listRoots := func(id string) []interface{} {
+		t.Helper()
+		require.NoError(t, serverEnd.SendResponse(serverRequest(id, "roots/list", nil)))
+
+		response := nextMessage(t, messages)
+		require.Equal(t, protocol.StringID(id), response.ID)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]interface{})["roots"].([]interface{})
+	}
+
+	roots := listRoots("r1")
+	require.Len(t, roots, 1)
+	assert.Equal(t, "file:///workspace", roots[0].(map[string]interface{})["uri"])
+
+	require.NoError(t, client.SetRoots([]protocol.Root{{URI: "file:///a"}, {URI: "file:///b"}}))
+
+	notification := nextMessage(t, messages)
+	assert.Equal(t, "notifications/roots/list_changed", notification.Method)
+	assert.True(t, notification.ID.IsZero())
+
+	assert.Len(t, listRoots("r2"), 2)
+}
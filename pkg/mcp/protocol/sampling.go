@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SamplingMessage is one turn of the conversation a server wants completed.
+type SamplingMessage struct {
+	Role    Role    `json:"role"`
+	Content Content `json:"content"`
+}
+
+func (m *SamplingMessage) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    Role        `json:"role"`
+		Content interface{} `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	contents, err := DecodeContent([]interface{}{raw.Content})
+	if err != nil {
+		return err
+	}
+
+	m.Role = raw.Role
+	m.Content = contents[0]
+	return nil
+}
+
+// SamplingParams are the parameters of a sampling/createMessage request.
+type SamplingParams struct {
+	Messages         []SamplingMessage      `json:"messages"`
+	ModelPreferences *ModelPreferences      `json:"modelPreferences,omitempty"`
+	SystemPrompt     string                 `json:"systemPrompt,omitempty"`
+	IncludeContext   string                 `json:"includeContext,omitempty"`
+	Temperature      *float64               `json:"temperature,omitempty"`
+	MaxTokens        int                    `json:"maxTokens"`
+	StopSequences    []string               `json:"stopSequences,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SamplingResult is the completion returned to the server.
+type SamplingResult struct {
+	Role       Role    `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+// SamplingHandler produces a completion for a server's sampling request,
+// typically by calling an LLM. It should give up once ctx is done, which
+// happens when the server cancels the request or the client disconnects.
+type SamplingHandler func(ctx context.Context, params SamplingParams) (SamplingResult, error)
+
+// SetSamplingHandler lets servers request completions through this client.
+// The sampling capability is advertised when the handler is set before
+// Connect. Passing nil removes the handler.
+func (c *Client) SetSamplingHandler(handler SamplingHandler) {
+	if handler == nil {
+		c.notifications.setRequestHandler("sampling/createMessage", nil)
+		return
+	}
+
+	c.notifications.setRequestHandler("sampling/createMessage", func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		var samplingParams SamplingParams
+		if err := decodeResult(params, &samplingParams); err != nil {
+			return nil, &JSONRPCError{Code: ErrInvalidParams, Message: fmt.Sprintf("invalid sampling params: %v", err)}
+		}
+
+		return handler(ctx, samplingParams)
+	})
+}
@@ -0,0 +1,156 @@
+package protocol_test
+
+import (
+	"context"
+	"errors"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectWithServerEnd connects a client to an in-memory fake server that
+// answers the handshake and forwards every other message it receives, in
+// either direction, to the returned channel.
+func connectWithServerEnd(t *testing.T, client *protocol.Client) (*protocol.InMemoryTransport, <-chan *protocol.JSONRPCResponse, <-chan map[string]interface{}) {
+	t.Helper()
+
+	clientEnd, serverEnd := protocol.NewInMemoryPair()
+	require.NoError(t, serverEnd.Start())
+	t.Cleanup(func() { serverEnd.Close() })
+
+	messages := make(chan *protocol.JSONRPCResponse, 16)
+	initParams := make(chan map[string]interface{}, 1)
+
+	go func() {
+		for {
+			// Receive decodes requests as well as responses, which lets the
+			// fake server see the client's answers to its own requests.
+			msg, err := serverEnd.Receive()
+			if err != nil {
+				return
+			}
+
+			var result interface{}
+			switch msg.Method {
+			case "notifications/initialized":
+				continue
+			case "initialize":
+				initParams <- msg.Params
+				result = initializeResult(&protocol.JSONRPCRequest{Params: msg.Params})
+			case "mcp.list_tools":
+				result = map[string]interface{}{"tools": []interface{}{}}
+			case "mcp.list_resources":
+				result = map[string]interface{}{"resources": []interface{}{}}
+			default:
+				messages <- msg
+				continue
+			}
+			serverEnd.SendResponse(protocol.NewResponse(msg.ID, result))
+		}
+	}()
+
+	require.NoError(t, client.Connect(clientEnd))
+	t.Cleanup(func() { client.Disconnect() })
+
+	return serverEnd, messages, initParams
+}
+
+func serverRequest(id, method string, params map[string]interface{}) *protocol.JSONRPCResponse {
+	return &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: protocol.StringID(id), Method: method, Params: params}
+}
+
+func nextMessage(t *testing.T, messages <-chan *protocol.JSONRPCResponse) *protocol.JSONRPCResponse {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message from the client")
+		return nil
+	}
+}
+
+func TestClientSampling(t *testing.T) {
+	t.Run("answers createMessage with the handler", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+
+		var got protocol.SamplingParams
+		client.SetSamplingHandler(func(ctx context.Context, params protocol.SamplingParams) (protocol.SamplingResult, error) {
+			got = params
+			return protocol.SamplingResult{
+				Role:       protocol.RoleAssistant,
+				Content:    protocol.TextContent{Type: string(protocol.ContentTypeText), Text: "Paris"},
+				Model:      "test-model",
+				StopReason: "endTurn",
+			}, nil
+		})
+
+		serverEnd, messages, initParams := connectWithServerEnd(t, client)
+
+		capabilities := (<-initParams)["capabilities"].(map[string]interface{})
+		assert.Contains(t, capabilities, "sampling")
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("s1", "sampling/createMessage", map[string]interface{}{
+			"messages": []interface{}{
+				map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": "Capital of France?"}},
+			},
+			"modelPreferences": map[string]interface{}{"hints": []interface{}{map[string]interface{}{"name": "claude"}}},
+			"maxTokens":        100,
+		})))
+
+		response := nextMessage(t, messages)
+		assert.Equal(t, protocol.StringID("s1"), response.ID)
+		require.Nil(t, response.Error)
+
+		result := response.Result.(map[string]interface{})
+		assert.Equal(t, "assistant", result["role"])
+		assert.Equal(t, "test-model", result["model"])
+		assert.Equal(t, "Paris", result["content"].(map[string]interface{})["text"])
+
+		require.Len(t, got.Messages, 1)
+		assert.Equal(t, "Capital of France?", got.Messages[0].Content.(protocol.TextContent).Text)
+		assert.Equal(t, "claude", got.ModelPreferences.Hints[0].Name)
+		assert.Equal(t, 100, got.MaxTokens)
+	})
+
+	t.Run("reports handler errors", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		client.SetSamplingHandler(func(ctx context.Context, params protocol.SamplingParams) (protocol.SamplingResult, error) {
+			return protocol.SamplingResult{}, errors.New("user rejected sampling")
+		})
+
+		serverEnd, messages, _ := connectWithServerEnd(t, client)
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("s2", "sampling/createMessage", map[string]interface{}{
+			"messages":  []interface{}{},
+			"maxTokens": 10,
+		})))
+
+		response := nextMessage(t, messages)
+		require.NotNil(t, response.Error)
+		assert.Equal(t, "user rejected sampling", response.Error.Message)
+	})
+
+	t.Run("without a handler", func(t *testing.T) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		serverEnd, messages, initParams := connectWithServerEnd(t, client)
+
+		capabilities, _ := (<-initParams)["capabilities"].(map[string]interface{})
+		assert.NotContains(t, capabilities, "sampling")
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("s3", "sampling/createMessage", nil)))
+		response := nextMessage(t, messages)
+		require.NotNil(t, response.Error)
+		assert.Equal(t, protocol.ErrMethodNotFound, response.Error.Code)
+
+		// Pings from the server are always answered.
+		require.NoError(t, serverEnd.SendResponse(serverRequest("s4", "ping", nil)))
+		response = nextMessage(t, messages)
+		assert.Equal(t, protocol.StringID("s4"), response.ID)
+		assert.Nil(t, response.Error)
+	})
+}
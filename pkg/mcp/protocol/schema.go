@@ -0,0 +1,158 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaFromStruct generates an InputSchema from a struct, the inverse of
+// BindArguments. Properties are named after the json tags, and a field tagged
+// `jsonschema:"required"` or `validate:"required"` is required. A
+// `description` tag documents the property:
+//
+//	type readArgs struct {
+//		Path  string `json:"path" jsonschema:"required" description:"File to read"`
+//		Limit int    `json:"limit,omitempty"`
+//	}
+//	schema, err := protocol.SchemaFromStruct(readArgs{})
+//
+// Strings, booleans, integers and floats map to their JSON schema types,
+// slices and arrays to arrays, maps and nested structs to objects, and
+// time.Time to a date-time string. Pointers are treated as the type they point
+// to. Other types, such as interfaces and channels, are an error.
+func SchemaFromStruct(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema needs a struct, got %T", v)
+	}
+
+	return objectSchema(t, map[reflect.Type]bool{})
+}
+
+func objectSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := make(map[string]interface{})
+	var required []string
+	if err := addFields(t, properties, &required, visiting); err != nil {
+		return nil, err
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema, nil
+}
+
+// addFields adds the properties of t's fields, flattening embedded structs
+// the way encoding/json does.
+func addFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := addFields(fieldType, properties, required, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := typeSchema(fieldType, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		properties[name] = schema
+
+		if hasTagOption(field.Tag.Get("jsonschema"), "required") || hasTagOption(field.Tag.Get("validate"), "required") {
+			*required = append(*required, name)
+		}
+	}
+
+	return nil
+}
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes []byte as a base64 string.
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		return map[string]interface{}{"type": "object"}, nil
+	case reflect.Struct:
+		return objectSchema(t, visiting)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func hasTagOption(tag, option string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if strings.TrimSpace(part) == option {
+			return true
+		}
+	}
+	return false
+}
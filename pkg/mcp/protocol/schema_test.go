@@ -0,0 +1,106 @@
+package protocol_test
+
+import (
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFromStruct(t *testing.T) {
+	type Common struct {
+		Verbose bool `json:"verbose"`
+	}
+	type options struct {
+		Depth  int     `json:"depth" validate:"required"`
+		Filter *string `json:"filter,omitempty"`
+	}
+	type input struct {
+		Common
+		Path     string            `json:"path" jsonschema:"required" description:"File to read"`
+		Count    int64             `json:"count,omitempty"`
+		Ratio    float64           `json:"ratio"`
+		Tags     []string          `json:"tags"`
+		Matrix   [][]float32       `json:"matrix"`
+		Options  options           `json:"options"`
+		Extra    *options          `json:"extra,omitempty"`
+		Labels   map[string]string `json:"labels"`
+		Payload  []byte            `json:"payload"`
+		Since    time.Time         `json:"since"`
+		Untagged uint
+		Ignored  string `json:"-"`
+		hidden   string
+	}
+
+	schema, err := protocol.SchemaFromStruct(input{})
+	require.NoError(t, err)
+
+	optionsSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"depth":  map[string]interface{}{"type": "integer"},
+			"filter": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"depth"},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"verbose": map[string]interface{}{"type": "boolean"},
+			"path":    map[string]interface{}{"type": "string", "description": "File to read"},
+			"count":   map[string]interface{}{"type": "integer"},
+			"ratio":   map[string]interface{}{"type": "number"},
+			"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"matrix": map[string]interface{}{"type": "array", "items": map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"type": "number"},
+			}},
+			"options":  optionsSchema,
+			"extra":    optionsSchema,
+			"labels":   map[string]interface{}{"type": "object"},
+			"payload":  map[string]interface{}{"type": "string"},
+			"since":    map[string]interface{}{"type": "string", "format": "date-time"},
+			"Untagged": map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"path"},
+	}, schema)
+
+	t.Run("validates what BindArguments accepts", func(t *testing.T) {
+		tool := protocol.Tool{Name: "read", InputSchema: schema}
+		args := map[string]interface{}{
+			"path":    "/tmp/a",
+			"tags":    []interface{}{"x"},
+			"options": map[string]interface{}{"depth": 2.0},
+		}
+		require.NoError(t, tool.ValidateArguments(args))
+
+		var in input
+		require.NoError(t, protocol.BindArguments(args, &in))
+		assert.Equal(t, 2, in.Options.Depth)
+
+		assert.ErrorContains(t, tool.ValidateArguments(map[string]interface{}{}), "missing required field: path")
+	})
+
+	t.Run("accepts pointers", func(t *testing.T) {
+		fromPointer, err := protocol.SchemaFromStruct(&input{})
+		require.NoError(t, err)
+		assert.Equal(t, schema, fromPointer)
+	})
+
+	t.Run("rejects unsupported types", func(t *testing.T) {
+		_, err := protocol.SchemaFromStruct("not a struct")
+		assert.Error(t, err)
+
+		_, err = protocol.SchemaFromStruct(struct {
+			Anything interface{} `json:"anything"`
+		}{})
+		assert.ErrorContains(t, err, "field Anything")
+
+		type node struct {
+			Children []node `json:"children"`
+		}
+		_, err = protocol.SchemaFromStruct(node{})
+		assert.ErrorContains(t, err, "recursive type")
+	})
+}
@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// schemaTypes are the values of "type" that ValidateType understands.
+var schemaTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"array":   true,
+	"object":  true,
+}
+
+// schemaBounds are the keywords that must hold a number. exclusiveMinimum and
+// exclusiveMaximum may also be booleans, as in draft 4.
+var schemaBounds = []string{
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "minItems", "maxItems",
+}
+
+// ValidateSchema checks that schema is well-formed enough to validate
+// arguments against: every keyword ValidateArguments reads has the expected
+// shape, in schema and in every nested property and items schema. Keywords
+// it does not read are not checked. The error names the offending keyword,
+// such as "properties.config.items.type".
+func ValidateSchema(schema map[string]interface{}) error {
+	if err := checkSchema(schema); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	return nil
+}
+
+func checkSchema(schema map[string]interface{}) error {
+	if raw, exists := schema["type"]; exists {
+		name, ok := raw.(string)
+		if !ok {
+			return keywordError("type", "expected a string, got %T", raw)
+		}
+		if !schemaTypes[name] {
+			return keywordError("type", "unsupported type: %s", name)
+		}
+	}
+
+	if raw, exists := schema["properties"]; exists {
+		props, ok := raw.(map[string]interface{})
+		if !ok {
+			return keywordError("properties", "expected an object, got %T", raw)
+		}
+
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := checkSubschema("properties."+name, props[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if raw, exists := schema["items"]; exists {
+		if err := checkSubschema("items", raw); err != nil {
+			return err
+		}
+	}
+
+	if raw, exists := schema["required"]; exists {
+		switch required := raw.(type) {
+		case []string:
+		case []interface{}:
+			for i, field := range required {
+				if _, ok := field.(string); !ok {
+					return keywordError(fmt.Sprintf("required[%d]", i), "expected a string, got %T", field)
+				}
+			}
+		default:
+			return keywordError("required", "expected an array, got %T", raw)
+		}
+	}
+
+	if raw, exists := schema["enum"]; exists {
+		switch raw.(type) {
+		case []interface{}, []string:
+		default:
+			return keywordError("enum", "expected an array, got %T", raw)
+		}
+	}
+
+	if raw, exists := schema["additionalProperties"]; exists {
+		switch raw.(type) {
+		case bool, map[string]interface{}:
+		default:
+			return keywordError("additionalProperties", "expected a boolean or an object, got %T", raw)
+		}
+	}
+
+	for _, keyword := range schemaBounds {
+		raw, exists := schema[keyword]
+		if !exists {
+			continue
+		}
+		if _, ok := toFloat64(raw); ok {
+			continue
+		}
+		if _, ok := raw.(bool); ok && (keyword == "exclusiveMinimum" || keyword == "exclusiveMaximum") {
+			continue
+		}
+		return keywordError(keyword, "expected a number, got %T", raw)
+	}
+
+	if raw, exists := schema["pattern"]; exists {
+		pattern, ok := raw.(string)
+		if !ok {
+			return keywordError("pattern", "expected a string, got %T", raw)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return keywordError("pattern", "%v", err)
+		}
+	}
+
+	return nil
+}
+
+// checkSubschema checks the schema found at path, which must be an object.
+func checkSubschema(path string, raw interface{}) error {
+	schema, ok := raw.(map[string]interface{})
+	if !ok {
+		return keywordError(path, "expected a schema object, got %T", raw)
+	}
+	if err := checkSchema(schema); err != nil {
+		return prefixPath(path, err)
+	}
+	return nil
+}
+
+func keywordError(path, format string, args ...interface{}) *schemaError {
+	return &schemaError{Path: path, Err: fmt.Errorf(format, args...)}
+}
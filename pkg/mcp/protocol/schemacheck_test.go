@@ -0,0 +1,88 @@
+package protocol_test
+
+import (
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Run("accepts well-formed schemas", func(t *testing.T) {
+		for name, schema := range map[string]map[string]interface{}{
+			"empty": {},
+			"generated": mustSchema(t, struct {
+				Path  string   `json:"path" jsonschema:"required"`
+				Tags  []string `json:"tags"`
+				Limit int      `json:"limit"`
+			}{}),
+			"decoded from JSON": {
+				"type":                 "object",
+				"required":             []interface{}{"name"},
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"properties": map[string]interface{}{
+					"name":  map[string]interface{}{"type": "string", "minLength": 1.0, "pattern": "^[a-z]+$"},
+					"mode":  map[string]interface{}{"enum": []interface{}{"fast", "slow"}},
+					"ratio": map[string]interface{}{"type": "number", "minimum": 0.0, "exclusiveMinimum": true},
+					"items": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+				},
+			},
+		} {
+			assert.NoError(t, protocol.ValidateSchema(schema), name)
+		}
+	})
+
+	t.Run("rejects malformed schemas", func(t *testing.T) {
+		for want, schema := range map[string]map[string]interface{}{
+			"type: expected a string, got []interface {}": {"type": []interface{}{"string", "null"}},
+			"type: unsupported type: tuple":               {"type": "tuple"},
+			"properties: expected an object, got []interface {}": {
+				"properties": []interface{}{"path"},
+			},
+			"properties.path: expected a schema object, got string": {
+				"properties": map[string]interface{}{"path": "string"},
+			},
+			"properties.config.properties.retries.type: expected a string, got int": {
+				"properties": map[string]interface{}{
+					"config": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"retries": map[string]interface{}{"type": 1},
+						},
+					},
+				},
+			},
+			"properties.tags.items: expected a schema object, got string": {
+				"properties": map[string]interface{}{
+					"tags": map[string]interface{}{"type": "array", "items": "string"},
+				},
+			},
+			"properties.tags.items.minimum: expected a number, got string": {
+				"properties": map[string]interface{}{
+					"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"minimum": "0"}},
+				},
+			},
+			"required: expected an array, got string":     {"required": "name"},
+			"required[1]: expected a string, got float64": {"required": []interface{}{"name", 1.0}},
+			"enum: expected an array, got string":         {"enum": "fast"},
+			"additionalProperties: expected a boolean or an object, got string": {
+				"additionalProperties": "no",
+			},
+			"maxLength: expected a number, got string":              {"maxLength": "10"},
+			"maximum: expected a number, got bool":                  {"maximum": true},
+			"pattern: expected a string, got int":                   {"pattern": 1},
+			"pattern: error parsing regexp: missing closing ): `(`": {"pattern": "("},
+		} {
+			assert.EqualError(t, protocol.ValidateSchema(schema), "invalid schema: "+want)
+		}
+	})
+}
+
+func mustSchema(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	schema, err := protocol.SchemaFromStruct(v)
+	require.NoError(t, err)
+	return schema
+}
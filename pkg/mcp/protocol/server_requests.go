@@ -0,0 +1,110 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RequestHandler answers a request the server sends to the client. params
+// holds the request's params as sent, or is nil when there are none. ctx is
+// cancelled when the server cancels the request or the connection closes.
+// Returning a *JSONRPCError sends that error as is; any other error is
+// reported as an internal error.
+type RequestHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Handle registers handler for server requests with the given method,
+// replacing any earlier handler; nil removes it. Each request is handled on
+// its own goroutine. Requests without a handler are answered with a method
+// not found error, except ping, which is always answered.
+//
+// Handling sampling/createMessage, roots/list or elicitation/create before
+// Connect advertises the matching capability, as SetSamplingHandler, SetRoots
+// and SetElicitationHandler do.
+func (c *Client) Handle(method string, handler RequestHandler) {
+	if handler == nil {
+		c.notifications.setRequestHandler(method, nil)
+		return
+	}
+
+	c.notifications.setRequestHandler(method, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		var raw json.RawMessage
+		if params != nil {
+			data, err := json.Marshal(params)
+			if err != nil {
+				return nil, &JSONRPCError{Code: ErrInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+			}
+			raw = data
+		}
+
+		return handler(ctx, raw)
+	})
+}
+
+// requestHandler answers a request sent by the server. Returning a
+// *JSONRPCError sends that error as is; any other error is reported as an
+// internal error.
+type requestHandler func(ctx context.Context, params map[string]interface{}) (interface{}, error)
+
+func (r *notificationRouter) setRequestHandler(method string, handler requestHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if handler == nil {
+		delete(r.requests, method)
+		return
+	}
+	r.requests[method] = handler
+}
+
+func (r *notificationRouter) requestHandler(method string) (requestHandler, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	handler, exists := r.requests[method]
+	return handler, exists
+}
+
+// answerRequest runs the handler for a server request and sends back its
+// result. Servers may ping the client at any time, so ping is always answered.
+// ctx ends when the server cancels the request or the connection goes away;
+// the request is then left unanswered, as the spec asks.
+func (c *Client) answerRequest(ctx context.Context, transport Transport, request *JSONRPCResponse) {
+	sender, ok := transport.(ResponseSender)
+	if !ok {
+		c.logger.Warn("transport cannot answer server requests", "method", request.Method)
+		return
+	}
+
+	var response *JSONRPCResponse
+
+	handler, exists := c.notifications.requestHandler(request.Method)
+	switch {
+	case exists:
+		result, err := handler(ctx, request.Params)
+		if err != nil {
+			var rpcErr *JSONRPCError
+			if errors.As(err, &rpcErr) {
+				response = NewErrorResponse(request.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+			} else {
+				response = NewErrorResponse(request.ID, ErrInternalError, err.Error(), nil)
+			}
+		} else {
+			response = NewResponse(request.ID, result)
+		}
+	case request.Method == "ping":
+		response = NewResponse(request.ID, map[string]interface{}{})
+	default:
+		response = NewErrorResponse(request.ID, ErrMethodNotFound, "method not found: "+request.Method, nil)
+	}
+
+	if ctx.Err() != nil {
+		c.logger.Debug("server request cancelled", "method", request.Method, "id", request.ID.String())
+		return
+	}
+
+	if err := sender.SendResponse(response); err != nil {
+		c.logger.Warn("failed to answer server request", "method", request.Method, "error", err)
+	}
+}
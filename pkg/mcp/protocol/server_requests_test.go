@@ -0,0 +1,137 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientHandle(t *testing.T) {
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+
+	client.Handle("elicitation/create", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var request struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(params, &request); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"action": "accept", "echo": request.Message}, nil
+	})
+	client.Handle("custom/params", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"empty": params == nil}, nil
+	})
+	client.Handle("custom/failing", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	client.Handle("custom/rejecting", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, &protocol.JSONRPCError{Code: protocol.ErrInvalidParams, Message: "bad", Data: "detail"}
+	})
+
+	serverEnd, messages, _ := connectWithServerEnd(t, client)
+
+	ask := func(id, method string, params map[string]interface{}) *protocol.JSONRPCResponse {
+		t.Helper()
+		require.NoError(t, serverEnd.SendResponse(serverRequest(id, method, params)))
+
+		response := nextMessage(t, messages)
+		require.Equal(t, protocol.StringID(id), response.ID)
+		return response
+	}
+
+	response := ask("1", "elicitation/create", map[string]interface{}{"message": "name?"})
+	require.Nil(t, response.Error)
+	assert.Equal(t, map[string]interface{}{"action": "accept", "echo": "name?"}, response.Result)
+
+	response = ask("2", "custom/params", nil)
+	require.Nil(t, response.Error)
+	assert.Equal(t, map[string]interface{}{"empty": true}, response.Result)
+
+	response = ask("3", "custom/failing", nil)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.ErrInternalError, response.Error.Code)
+	assert.Equal(t, "boom", response.Error.Message)
+
+	response = ask("4", "custom/rejecting", nil)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.ErrInvalidParams, response.Error.Code)
+	assert.Equal(t, "detail", response.Error.Data)
+
+	client.Handle("elicitation/create", nil)
+	response = ask("5", "elicitation/create", nil)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, protocol.ErrMethodNotFound, response.Error.Code)
+}
+
+func TestClientHandleCancellation(t *testing.T) {
+	// blockingClient handles custom/wait until ctx is done, signalling on
+	// started when the handler runs and sending ctx.Err() on stopped.
+	blockingClient := func() (*protocol.Client, chan struct{}, chan error) {
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		started, stopped := make(chan struct{}, 1), make(chan error, 1)
+		client.Handle("custom/wait", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			started <- struct{}{}
+			<-ctx.Done()
+			stopped <- ctx.Err()
+			return nil, ctx.Err()
+		})
+		client.Handle("custom/params", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			return map[string]interface{}{}, nil
+		})
+		return client, started, stopped
+	}
+
+	cancelled := func(id string) *protocol.JSONRPCResponse {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: protocol.JSONRPCVersion,
+			Method:  "notifications/cancelled",
+			Params:  map[string]interface{}{"requestId": id, "reason": "user aborted"},
+		}
+	}
+
+	waitFor := func(t *testing.T, ch <-chan error) error {
+		t.Helper()
+		select {
+		case err := <-ch:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("handler context was not cancelled")
+			return nil
+		}
+	}
+
+	t.Run("when the server cancels the request", func(t *testing.T) {
+		client, started, stopped := blockingClient()
+		serverEnd, messages, _ := connectWithServerEnd(t, client)
+		defer client.Disconnect()
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("1", "custom/wait", nil)))
+		<-started
+
+		// Cancelling another request leaves this one running.
+		require.NoError(t, serverEnd.SendResponse(cancelled("2")))
+		require.NoError(t, serverEnd.SendResponse(cancelled("1")))
+		assert.ErrorIs(t, waitFor(t, stopped), context.Canceled)
+
+		// The cancelled request is not answered.
+		require.NoError(t, serverEnd.SendResponse(serverRequest("3", "custom/params", nil)))
+		assert.Equal(t, protocol.StringID("3"), nextMessage(t, messages).ID)
+	})
+
+	t.Run("when the client disconnects", func(t *testing.T) {
+		client, started, stopped := blockingClient()
+		serverEnd, _, _ := connectWithServerEnd(t, client)
+
+		require.NoError(t, serverEnd.SendResponse(serverRequest("1", "custom/wait", nil)))
+		<-started
+
+		require.NoError(t, client.Disconnect())
+		assert.ErrorIs(t, waitFor(t, stopped), context.Canceled)
+	})
+}
@@ -0,0 +1,82 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ServerTransport is the server's side of a connection: it reads requests and
+// writes responses.
+type ServerTransport interface {
+	ReceiveRequest() (*JSONRPCRequest, error)
+
+	SendResponse(response *JSONRPCResponse) error
+
+	Close() error
+}
+
+// StreamServerTransport serves newline-delimited JSON-RPC over a reader and
+// writer, typically os.Stdin and os.Stdout of a server process.
+type StreamServerTransport struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+	closer  io.Closer
+	mutex   sync.Mutex
+}
+
+func NewStreamServerTransport(in io.Reader, out io.Writer) *StreamServerTransport {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), DefaultMaxLineSize)
+
+	t := &StreamServerTransport{
+		scanner: scanner,
+		out:     out,
+	}
+	if closer, ok := in.(io.Closer); ok {
+		t.closer = closer
+	}
+
+	return t
+}
+
+func (t *StreamServerTransport) ReceiveRequest() (*JSONRPCRequest, error) {
+	if !t.scanner.Scan() {
+		if err := t.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading request: %w", err)
+		}
+		return nil, io.EOF
+	}
+
+	var request JSONRPCRequest
+	if err := json.Unmarshal(t.scanner.Bytes(), &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w, raw request: %s", err, t.scanner.Text())
+	}
+
+	return &request, nil
+}
+
+func (t *StreamServerTransport) SendResponse(response *JSONRPCResponse) error {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, err := t.out.Write(append(responseJSON, '\n')); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+
+	return nil
+}
+
+func (t *StreamServerTransport) Close() error {
+	if t.closer != nil {
+		return t.closer.Close()
+	}
+	return nil
+}
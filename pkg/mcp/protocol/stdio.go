@@ -11,26 +11,78 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 )
 
+// DefaultMaxLineSize bounds a single incoming JSON-RPC frame. bufio.Scanner's
+// own 64KB default is too small for tools returning images or file contents.
+const DefaultMaxLineSize = 10 * 1024 * 1024
+
+// DefaultShutdownGrace is how long Close waits for the server to exit after
+// SIGTERM before killing it.
+const DefaultShutdownGrace = 5 * time.Second
+
+// exitStatusTimeout bounds how long Receive waits, after the server closes
+// stdout, for it to exit so the exit status can be reported.
+const exitStatusTimeout = time.Second
+
+// ErrNotExited is returned by ExitError while the server process is still
+// running or was never started.
+var ErrNotExited = errors.New("server process has not exited")
+
 type StdioTransport struct {
 	cmd        *exec.Cmd
+	exit       *processExit
+	stopping   bool // Close is ending the process
+	closed     bool // Close has run since the last Start
+	onDrop     []func(err error)
 	stdin      io.WriteCloser
+	writes     *writeQueue // Serializes writes to stdin in FIFO order
 	stdout     io.ReadCloser
 	scanner    *bufio.Scanner
 	connected  bool
 	mutex      sync.Mutex
-	lineBuffer []string // For debug and error reporting
+	readLock   chan struct{}      // Serializes readers without blocking Send
+	inflight   chan frameResult   // Read left running by a cancelled receive
+	lineBuffer []string           // For debug and error reporting
+	queued     []*JSONRPCResponse // Remaining responses from a batch frame
 	env        map[string]string
-	cmdStr     string
+	inheritEnv bool
+	command    string
+	args       []string
+	workDir    string
+	maxLine    int
+	grace      time.Duration
+	framing    Framing
+	limits     *ProcessLimits
+	group      bool // The running server leads its own process group
 }
 
+// NewStdioTransport runs cmdStr split on whitespace. Arguments that contain
+// spaces need NewStdioTransportCommand.
 func NewStdioTransport(cmdStr string) *StdioTransport {
+	var command string
+	var args []string
+	if fields := strings.Fields(cmdStr); len(fields) > 0 {
+		command, args = fields[0], fields[1:]
+	}
+
+	return NewStdioTransportCommand(command, args...)
+}
+
+// NewStdioTransportCommand runs command with args passed through exactly as
+// given, without any shell-style splitting or quoting.
+func NewStdioTransportCommand(command string, args ...string) *StdioTransport {
 	return &StdioTransport{
-		cmdStr:     cmdStr,
+		command:    command,
+		args:       append([]string(nil), args...),
+		readLock:   make(chan struct{}, 1),
 		connected:  false,
 		lineBuffer: make([]string, 0, 10),
 		env:        make(map[string]string),
+		inheritEnv: true,
+		maxLine:    DefaultMaxLineSize,
+		grace:      DefaultShutdownGrace,
 	}
 }
 
@@ -44,6 +96,54 @@ func (t *StdioTransport) SetEnv(env map[string]string) {
 	}
 }
 
+// SetInheritEnv controls whether the server process starts with this
+// process's environment underneath the variables given to SetEnv. It does by
+// default; with inherit false the server sees only those variables, which
+// keeps unrelated secrets out of it.
+func (t *StdioTransport) SetInheritEnv(inherit bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.inheritEnv = inherit
+}
+
+// SetWorkDir sets the directory the server process is started in. It must be
+// called before Start.
+func (t *StdioTransport) SetWorkDir(dir string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.workDir = dir
+}
+
+// SetMaxLineSize sets the largest frame, in bytes, that Receive accepts. It
+// takes effect on the next Start.
+func (t *StdioTransport) SetMaxLineSize(size int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.maxLine = size
+}
+
+// SetFraming selects how messages written to the server are delimited.
+// Whichever is set, Receive accepts both newline-delimited and
+// Content-Length framed messages.
+func (t *StdioTransport) SetFraming(framing Framing) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.framing = framing
+}
+
+// SetShutdownGrace sets how long Close waits for the server to exit on its own
+// before it is killed.
+func (t *StdioTransport) SetShutdownGrace(grace time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.grace = grace
+}
+
 func (t *StdioTransport) Start() error {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -52,20 +152,25 @@ func (t *StdioTransport) Start() error {
 		return errors.New("transport already started")
 	}
 
-	args := strings.Fields(t.cmdStr)
-	if len(args) == 0 {
+	if t.command == "" {
 		return errors.New("empty command string")
 	}
 
-	cmdName := args[0]
-	var cmdArgs []string
-	if len(args) > 1 {
-		cmdArgs = args[1:]
+	if t.limits != nil {
+		t.cmd = limitedCommand(*t.limits, t.command, t.args)
+		setProcessGroup(t.cmd)
+	} else {
+		t.cmd = exec.Command(t.command, t.args...)
 	}
-	t.cmd = exec.Command(cmdName, cmdArgs...)
-
-	if len(t.env) > 0 {
-		t.cmd.Env = os.Environ()
+	t.cmd.Dir = t.workDir
+
+	if len(t.env) > 0 || !t.inheritEnv {
+		// A nil Env would make exec inherit everything, so a clean
+		// environment starts out empty instead.
+		t.cmd.Env = []string{}
+		if t.inheritEnv {
+			t.cmd.Env = os.Environ()
+		}
 
 		for k, v := range t.env {
 			t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("%s=%s", k, v))
@@ -85,110 +190,421 @@ func (t *StdioTransport) Start() error {
 	}
 
 	t.scanner = bufio.NewScanner(t.stdout)
+	t.scanner.Split(splitFrames)
+	if t.maxLine > 0 {
+		t.scanner.Buffer(make([]byte, 0, min(64*1024, t.maxLine)), t.maxLine)
+	}
 
 	if err := t.cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start process: %w", err)
 	}
 
+	t.exit = &processExit{cmd: t.cmd, done: make(chan struct{})}
+	t.writes = newWriteQueue(t.stdin)
+	t.group = t.limits != nil
+	t.stopping = false
+	t.closed = false
 	t.connected = true
 	return nil
 }
 
+// processExit calls Wait on a started command exactly once, whichever of
+// Receive and Close gets there first, and keeps the result.
+type processExit struct {
+	cmd  *exec.Cmd
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// wait must only be called once stdout has been read to EOF or is no longer
+// needed, since Wait closes it.
+func (p *processExit) wait() {
+	p.once.Do(func() {
+		p.err = p.cmd.Wait()
+		close(p.done)
+	})
+}
+
+// ExitError reports how the server process ended: nil for a zero exit status
+// or when Close stopped it, otherwise the *exec.ExitError from Wait. It
+// returns ErrNotExited until the process has exited and been reaped, which
+// happens when Receive reaches EOF or in Close.
+func (t *StdioTransport) ExitError() error {
+	t.mutex.Lock()
+	exit, stopping := t.exit, t.stopping
+	t.mutex.Unlock()
+
+	if exit == nil {
+		return ErrNotExited
+	}
+
+	select {
+	case <-exit.done:
+	default:
+		return ErrNotExited
+	}
+
+	// Close's SIGTERM or Kill shows up as death by signal, which is a
+	// shutdown, not a crash.
+	if stopping && exit.cmd.ProcessState.ExitCode() == -1 {
+		return nil
+	}
+
+	return exit.err
+}
+
+// ExitCode returns the server's exit status, or -1 while it is running or when
+// it was ended by a signal, including the one sent by Close.
+func (t *StdioTransport) ExitCode() int {
+	t.mutex.Lock()
+	exit := t.exit
+	t.mutex.Unlock()
+
+	if exit == nil {
+		return -1
+	}
+
+	select {
+	case <-exit.done:
+		return exit.cmd.ProcessState.ExitCode()
+	default:
+		return -1
+	}
+}
+
 func (t *StdioTransport) Send(request *JSONRPCRequest) error {
+	return t.SendWithContext(context.Background(), request)
+}
+
+func (t *StdioTransport) SendBatch(requests []*JSONRPCRequest) error {
+	if len(requests) == 0 {
+		return errors.New("empty batch")
+	}
+
+	batchJSON, err := json.Marshal(requests)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	return t.writeFrame(context.Background(), batchJSON)
+}
+
+func (t *StdioTransport) SendResponse(response *JSONRPCResponse) error {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return t.writeFrame(context.Background(), responseJSON)
+}
+
+func (t *StdioTransport) writeFrame(ctx context.Context, frame []byte) error {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
 
 	if !t.connected {
+		t.mutex.Unlock()
 		return fmt.Errorf("transport not connected")
 	}
 
+	if t.framing == FramingContentLength {
+		frame = append([]byte(fmt.Sprintf("Content-Length: %d\r\n\r\n", len(frame))), frame...)
+	} else {
+		frame = append(frame, '\n')
+	}
+
+	writes := t.writes
+	t.mutex.Unlock()
+
+	// The frame is written outside the lock, so a server that stops reading
+	// stdin blocks only the senders, not Close or IsConnected.
+	if err := writes.write(ctx, frame); err != nil {
+		if errors.Is(err, errQueueClosed) || errors.Is(err, ctx.Err()) {
+			return err
+		}
+		err = fmt.Errorf("failed to write to stdin: %w", err)
+		t.disconnect(err)
+		return err
+	}
+
+	return nil
+}
+
+// OnDisconnect registers handler to run when the connection drops other than
+// through Close: the server closed stdout or a write failed. Handlers run on
+// the goroutine that noticed, without the transport's lock held.
+func (t *StdioTransport) OnDisconnect(handler func(err error)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.onDrop = append(t.onDrop, handler)
+}
+
+// disconnect marks the transport disconnected and, if it was still connected,
+// runs the OnDisconnect handlers.
+func (t *StdioTransport) disconnect(err error) {
+	t.mutex.Lock()
+	if !t.connected {
+		t.mutex.Unlock()
+		return
+	}
+	t.connected = false
+	if t.writes != nil {
+		t.writes.close()
+	}
+	handlers := append([]func(error){}, t.onDrop...)
+	t.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// SendWithContext is Send that gives up with ctx.Err() once ctx is done. A
+// request it gives up on is never written, not even in part; one whose write
+// has begun is finished first.
+func (t *StdioTransport) SendWithContext(ctx context.Context, request *JSONRPCRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	requestJSON = append(requestJSON, '\n')
+	return t.writeFrame(ctx, requestJSON)
+}
+
+func (t *StdioTransport) Receive() (*JSONRPCResponse, error) {
+	return t.ReceiveWithContext(context.Background())
+}
+
+// ReceiveWithContext is Receive that gives up with ctx.Err() once ctx is done.
+// The read it abandons keeps running, and whatever it yields is handed to the
+// next receive, so no frame is lost or read twice. That read ends at the
+// latest when Close stops the server.
+func (t *StdioTransport) ReceiveWithContext(ctx context.Context) (*JSONRPCResponse, error) {
+	if err := t.lockRead(ctx); err != nil {
+		return nil, err
+	}
+	defer t.unlockRead()
+
+	if len(t.queued) > 0 {
+		response := t.queued[0]
+		t.queued = t.queued[1:]
+		return response, nil
+	}
 
-	_, err = t.stdin.Write(requestJSON)
+	responses, err := t.nextFrame(ctx)
 	if err != nil {
-		t.connected = false
-		return fmt.Errorf("failed to write to stdin: %w", err)
+		return nil, err
 	}
 
-	return nil
+	t.queued = responses[1:]
+	return responses[0], nil
 }
 
-func (t *StdioTransport) SendWithContext(ctx context.Context, request *JSONRPCRequest) error {
+// ReceiveBatch returns every response in the next frame. A single-object frame
+// yields a one-element slice. Responses already split off a batch by Receive
+// are returned first.
+func (t *StdioTransport) ReceiveBatch() ([]*JSONRPCResponse, error) {
+	if err := t.lockRead(context.Background()); err != nil {
+		return nil, err
+	}
+	defer t.unlockRead()
+
+	if len(t.queued) > 0 {
+		responses := t.queued
+		t.queued = nil
+		return responses, nil
+	}
+
+	return t.nextFrame(context.Background())
+}
+
+type frameResult struct {
+	responses []*JSONRPCResponse
+	err       error
+}
+
+func (t *StdioTransport) lockRead(ctx context.Context) error {
 	select {
+	case t.readLock <- struct{}{}:
+		return nil
 	case <-ctx.Done():
 		return ctx.Err()
-	default:
-		// Continue with send
 	}
+}
 
-	done := make(chan error, 1)
+func (t *StdioTransport) unlockRead() {
+	<-t.readLock
+}
 
-	go func() {
-		done <- t.Send(request)
-	}()
+// nextFrame waits for the next frame, resuming a read abandoned by an earlier
+// cancelled receive if there is one. It must be called with the read lock
+// held.
+func (t *StdioTransport) nextFrame(ctx context.Context) ([]*JSONRPCResponse, error) {
+	if t.inflight == nil {
+		result := make(chan frameResult, 1)
+		t.inflight = result
+		go func() {
+			responses, err := t.readFrame()
+			result <- frameResult{responses, err}
+		}()
+	}
 
 	select {
+	case result := <-t.inflight:
+		t.inflight = nil
+		return result.responses, result.err
 	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-done:
-		return err
+		return nil, ctx.Err()
 	}
 }
 
-func (t *StdioTransport) Receive() (*JSONRPCResponse, error) {
+func (t *StdioTransport) readFrame() ([]*JSONRPCResponse, error) {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
+	connected := t.connected
+	scanner := t.scanner
+	t.mutex.Unlock()
 
-	if !t.connected {
+	if !connected {
 		return nil, fmt.Errorf("transport not connected")
 	}
 
-	if !t.scanner.Scan() {
-		t.connected = false
-		if err := t.scanner.Err(); err != nil {
-			return nil, fmt.Errorf("error reading from stdout: %w", err)
+	// Scan blocks until the server writes a line, so it must not hold t.mutex
+	// or concurrent Sends would stall behind it.
+	if !scanner.Scan() {
+		var err error
+		if scanErr := scanner.Err(); scanErr != nil {
+			err = fmt.Errorf("error reading from stdout: %w", scanErr)
+		} else {
+			err = t.eofError()
 		}
-		return nil, fmt.Errorf("EOF reached")
+		t.disconnect(err)
+		return nil, err
 	}
 
-	text := t.scanner.Text()
+	text := scanner.Text()
 
+	t.mutex.Lock()
 	t.bufferLine(text)
+	t.mutex.Unlock()
+
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "[") {
+		return decodeBatch([]byte(trimmed), text)
+	}
+
+	response, err := decodeMessage([]byte(trimmed), text)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*JSONRPCResponse{response}, nil
+}
+
+// eofError describes the end of stdout, including the server's exit status
+// if it exits soon after.
+func (t *StdioTransport) eofError() error {
+	t.mutex.Lock()
+	exit := t.exit
+	t.mutex.Unlock()
 
-	var response JSONRPCResponse
-	if err := json.Unmarshal([]byte(text), &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w, raw response: %s", err, text)
+	go exit.wait()
+
+	select {
+	case <-exit.done:
+	case <-time.After(exitStatusTimeout):
+		return fmt.Errorf("EOF reached")
 	}
 
-	return &response, nil
+	if err := t.ExitError(); err != nil {
+		return fmt.Errorf("EOF reached: server exited: %w", err)
+	}
+	return fmt.Errorf("EOF reached: server exited with status %d", t.ExitCode())
 }
 
+// Close stops the server process. It is safe to call at any time and any
+// number of times: before Start, after the connection dropped, or again after
+// an earlier Close, which returns nil. A server that closed stdout but kept
+// running is still stopped.
 func (t *StdioTransport) Close() error {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
 
-	if !t.connected {
+	if t.closed || t.cmd == nil || t.cmd.Process == nil || t.exit == nil {
+		t.connected = false
+		t.mutex.Unlock()
 		return nil
 	}
 
+	t.closed = true
 	t.connected = false
-
+	t.stopping = true
+	cmd := t.cmd
+	exit := t.exit
+	grace := t.grace
+	group := t.group
+
+	// Closing stdin signals EOF, which is how well-behaved servers learn they
+	// should exit.
 	if t.stdin != nil {
 		t.stdin.Close()
 	}
+	if t.writes != nil {
+		t.writes.close()
+	}
+	t.mutex.Unlock()
 
-	if t.cmd.Process != nil {
-		return t.cmd.Process.Kill()
+	exited := exit.done
+	go exit.wait()
+
+	if group {
+		defer killProcessGroup(cmd)
+	}
+
+	select {
+	case <-exited:
+		return nil
+	default:
 	}
 
+	// Platforms without SIGTERM (e.g. Windows) return an error here, in which
+	// case we go straight to Kill.
+	if err := terminateProcess(cmd, group); err != nil {
+		return killProcess(cmd, exited)
+	}
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(grace):
+		return killProcess(cmd, exited)
+	}
+}
+
+func killProcess(cmd *exec.Cmd, exited <-chan struct{}) error {
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill process: %w", err)
+	}
+
+	<-exited
 	return nil
 }
 
+// QueueDepth returns the number of frames waiting to be written to the
+// server's stdin, including one being written.
+func (t *StdioTransport) QueueDepth() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.writes == nil {
+		return 0
+	}
+	return t.writes.len()
+}
+
 func (t *StdioTransport) IsConnected() bool {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -0,0 +1,702 @@
+package protocol_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go-mcp/pkg/mcp/protocol"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startEchoTransport launches `cat`, which writes every request frame straight
+// back. A request unmarshals cleanly into a response with the same ID, which is
+// all these tests need.
+func startEchoTransport(t *testing.T) *protocol.StdioTransport {
+	t.Helper()
+
+	transport := protocol.NewStdioTransport("cat")
+	require.NoError(t, transport.Start())
+	t.Cleanup(func() { transport.Close() })
+
+	return transport
+}
+
+func TestStdioTransportBatch(t *testing.T) {
+	t.Run("round-trips a batch frame", func(t *testing.T) {
+		transport := startEchoTransport(t)
+
+		err := transport.SendBatch([]*protocol.JSONRPCRequest{
+			protocol.NewRequest(protocol.StringID("1"), "first", nil),
+			protocol.NewRequest(protocol.StringID("2"), "second", nil),
+		})
+		require.NoError(t, err)
+
+		responses, err := transport.ReceiveBatch()
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		assert.Equal(t, protocol.StringID("1"), responses[0].ID)
+		assert.Equal(t, protocol.StringID("2"), responses[1].ID)
+	})
+
+	t.Run("Receive splits a batch frame into single responses", func(t *testing.T) {
+		transport := startEchoTransport(t)
+
+		err := transport.SendBatch([]*protocol.JSONRPCRequest{
+			protocol.NewRequest(protocol.StringID("1"), "first", nil),
+			protocol.NewRequest(protocol.StringID("2"), "second", nil),
+		})
+		require.NoError(t, err)
+
+		first, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID("1"), first.ID)
+
+		second, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID("2"), second.ID)
+	})
+
+	t.Run("ReceiveBatch wraps a single response", func(t *testing.T) {
+		transport := startEchoTransport(t)
+
+		require.NoError(t, transport.Send(protocol.NewRequest(protocol.StringID("1"), "single", nil)))
+
+		responses, err := transport.ReceiveBatch()
+		require.NoError(t, err)
+		require.Len(t, responses, 1)
+		assert.Equal(t, protocol.StringID("1"), responses[0].ID)
+	})
+
+	t.Run("rejects an empty batch", func(t *testing.T) {
+		transport := startEchoTransport(t)
+
+		assert.Error(t, transport.SendBatch(nil))
+	})
+}
+
+func TestStdioTransportMaxLineSize(t *testing.T) {
+	t.Run("round-trips a 1MB response by default", func(t *testing.T) {
+		transport := startEchoTransport(t)
+
+		payload := strings.Repeat("a", 1024*1024)
+
+		// cat echoes while we are still writing, so send concurrently to keep
+		// the pipes from filling up.
+		sent := make(chan error, 1)
+		go func() {
+			sent <- transport.Send(protocol.NewRequest(protocol.StringID("1"), "large", map[string]interface{}{"data": payload}))
+		}()
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		require.NoError(t, <-sent)
+		assert.Equal(t, protocol.StringID("1"), response.ID)
+	})
+
+	t.Run("rejects frames above the configured limit", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("cat")
+		transport.SetMaxLineSize(1024)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		payload := strings.Repeat("a", 4096)
+		require.NoError(t, transport.Send(protocol.NewRequest(protocol.StringID("1"), "large", map[string]interface{}{"data": payload})))
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, bufio.ErrTooLong)
+	})
+}
+
+func TestStdioTransportClose(t *testing.T) {
+	t.Run("lets the server exit on stdin EOF", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("cat")
+		require.NoError(t, transport.Start())
+
+		start := time.Now()
+		require.NoError(t, transport.Close())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.False(t, transport.IsConnected())
+	})
+
+	t.Run("terminates a server that ignores stdin", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("sleep 30")
+		require.NoError(t, transport.Start())
+
+		start := time.Now()
+		require.NoError(t, transport.Close())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("kills a server that ignores SIGTERM after the grace period", func(t *testing.T) {
+		script := filepath.Join(t.TempDir(), "stubborn.sh")
+		require.NoError(t, os.WriteFile(script, []byte("trap '' TERM\necho '{\"jsonrpc\":\"2.0\",\"id\":\"ready\",\"result\":{}}'\nexec sleep 30\n"), 0o755))
+
+		transport := protocol.NewStdioTransport("sh " + script)
+		transport.SetShutdownGrace(100 * time.Millisecond)
+		require.NoError(t, transport.Start())
+
+		// Wait until the trap is installed before asking the server to stop.
+		_, err := transport.Receive()
+		require.NoError(t, err)
+
+		start := time.Now()
+		require.NoError(t, transport.Close())
+		elapsed := time.Since(start)
+		assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+		assert.Less(t, elapsed, 5*time.Second)
+	})
+
+	t.Run("is a no-op before Start", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("cat")
+		assert.NoError(t, transport.Close())
+		assert.NoError(t, transport.Close())
+
+		failed := protocol.NewStdioTransport("definitely-not-a-real-command")
+		require.Error(t, failed.Start())
+		assert.NoError(t, failed.Close())
+	})
+
+	t.Run("can be called twice", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("sleep 30")
+		require.NoError(t, transport.Start())
+
+		require.NoError(t, transport.Close())
+		assert.NoError(t, transport.Close())
+		assert.False(t, transport.IsConnected())
+	})
+
+	t.Run("after the server exited", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("true")
+		require.NoError(t, transport.Start())
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+		assert.NoError(t, transport.Close())
+		assert.NoError(t, transport.Close())
+		assert.Equal(t, 0, transport.ExitCode())
+	})
+
+	t.Run("stops a server that closed stdout but kept running", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c", "exec >&-; exec sleep 30")
+		require.NoError(t, transport.Start())
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+		assert.False(t, transport.IsConnected())
+
+		start := time.Now()
+		require.NoError(t, transport.Close())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.NoError(t, transport.ExitError())
+	})
+}
+
+func TestStdioTransportWorkDir(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	// The script reports its working directory back as the response ID.
+	script := filepath.Join(t.TempDir(), "pwd.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`printf '{"jsonrpc":"2.0","id":"%s","result":{}}\n' "$(pwd -P)"`+"\n"), 0o755))
+
+	transport := protocol.NewStdioTransport("sh " + script)
+	transport.SetWorkDir(dir)
+	require.NoError(t, transport.Start())
+	defer transport.Close()
+
+	response, err := transport.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, dir, response.ID.String())
+}
+
+func TestStdioTransportInheritEnv(t *testing.T) {
+	t.Setenv("GO_MCP_TEST_SECRET", "secret")
+
+	// The script reports both variables back as the response ID.
+	start := func(inherit bool) string {
+		transport := protocol.NewStdioTransportCommand("sh", "-c",
+			`printf '{"jsonrpc":"2.0","id":"%s/%s","result":{}}\n' "$GO_MCP_TEST_SECRET" "$GO_MCP_TEST_EXTRA"`)
+		transport.SetEnv(map[string]string{"GO_MCP_TEST_EXTRA": "extra"})
+		transport.SetInheritEnv(inherit)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		response, err := transport.Receive()
+		require.NoError(t, err)
+		return response.ID.String()
+	}
+
+	assert.Equal(t, "secret/extra", start(true))
+	assert.Equal(t, "/extra", start(false))
+}
+
+func TestStdioTransportValidation(t *testing.T) {
+	// receiveFrame starts a server that writes frame and returns what Receive
+	// makes of it.
+	receiveFrame := func(t *testing.T, frame string) (*protocol.JSONRPCResponse, error) {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "frame.json")
+		require.NoError(t, os.WriteFile(path, []byte(frame+"\n"), 0o644))
+
+		transport := protocol.NewStdioTransport("cat " + path)
+		require.NoError(t, transport.Start())
+		t.Cleanup(func() { transport.Close() })
+
+		return transport.Receive()
+	}
+
+	for name, frame := range map[string]string{
+		"missing version":          `{"id":"1","result":{}}`,
+		"wrong version":            `{"jsonrpc":"1.0","id":"1","result":{}}`,
+		"neither result nor error": `{"jsonrpc":"2.0","id":"1"}`,
+		"both result and error":    `{"jsonrpc":"2.0","id":"1","result":{},"error":{"code":-32603,"message":"boom"}}`,
+		"malformed batch entry":    `[{"jsonrpc":"2.0","id":"1","result":{}},{"jsonrpc":"2.0","id":"2"}]`,
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := receiveFrame(t, frame)
+			require.Error(t, err)
+
+			var rpcErr *protocol.JSONRPCError
+			require.ErrorAs(t, err, &rpcErr)
+			assert.Equal(t, protocol.ErrProtocolError, rpcErr.Code)
+			assert.Equal(t, frame, rpcErr.Data)
+		})
+	}
+
+	for name, frame := range map[string]string{
+		"null result":    `{"jsonrpc":"2.0","id":"1","result":null}`,
+		"error response": `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+		"notification":   `{"jsonrpc":"2.0","method":"notifications/progress","params":{}}`,
+		"server request": `{"jsonrpc":"2.0","id":"s1","method":"ping"}`,
+	} {
+		t.Run("accepts "+name, func(t *testing.T) {
+			_, err := receiveFrame(t, frame)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStdioClientSkipsInvalidFrames(t *testing.T) {
+	// The script answers the handshake and the "good" tool properly. The
+	// "bad" tool gets a garbage line followed by an answer with the wrong
+	// JSON-RPC version.
+	script := filepath.Join(t.TempDir(), "server.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`while IFS= read -r line; do
+  id=$(printf '%s' "$line" | sed -n 's/.*"id":\(.*\)}$/\1/p')
+  [ -z "$id" ] && continue
+  case "$line" in
+    *'"method":"initialize"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"`+protocol.LatestProtocolVersion+`","capabilities":{"tools":{}},"serverInfo":{"name":"fake","version":"1.0"}}}\n' "$id" ;;
+    *'"method":"mcp.list_tools"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}\n' "$id" ;;
+    *'"method":"bad"'*) printf 'not json\n{"jsonrpc":"1.0","id":%s,"result":{}}\n' "$id" ;;
+    *) printf '{"jsonrpc":"2.0","id":%s,"result":"ok"}\n' "$id" ;;
+  esac
+done
+`), 0o755))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(protocol.NewStdioTransport("sh "+script)))
+	defer client.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := client.CallTool(ctx, "bad", nil)
+	var rpcErr *protocol.JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrProtocolError, rpcErr.Code)
+	assert.Contains(t, rpcErr.Message, `unsupported JSON-RPC version "1.0"`)
+
+	assert.True(t, client.IsConnected())
+	result, err := client.CallTool(ctx, "good", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+}
+
+func TestStdioTransportInterleavedNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frames.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"1","progress":1}}`,
+		`{"jsonrpc":"2.0","id":"s1","method":"ping"}`,
+		`{"jsonrpc":"2.0","id":"1","result":{}}`,
+	}, "\n")+"\n"), 0o644))
+
+	transport := protocol.NewStdioTransport("cat " + path)
+	require.NoError(t, transport.Start())
+	defer transport.Close()
+
+	notification, err := transport.Receive()
+	require.NoError(t, err)
+	assert.True(t, notification.IsNotification())
+	assert.False(t, notification.IsResponse())
+
+	request, err := transport.Receive()
+	require.NoError(t, err)
+	assert.True(t, request.IsRequest())
+	assert.False(t, request.IsResponse())
+
+	response, err := transport.Receive()
+	require.NoError(t, err)
+	assert.True(t, response.IsResponse())
+	assert.Equal(t, protocol.StringID("1"), response.ID)
+}
+
+func TestStdioTransportCommandArgs(t *testing.T) {
+	// The script reports its first argument back as the response ID.
+	transport := protocol.NewStdioTransportCommand("sh", "-c",
+		`printf '{"jsonrpc":"2.0","id":"%s","result":{}}\n' "$1"`, "sh", "a b  c")
+	require.NoError(t, transport.Start())
+	defer transport.Close()
+
+	response, err := transport.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, "a b  c", response.ID.String())
+}
+
+func TestStdioTransportReceiveWithContext(t *testing.T) {
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("sleep 30")
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := transport.ReceiveWithContext(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("hands the abandoned read to the next receive", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c",
+			`sleep 0.2; printf '{"jsonrpc":"2.0","id":"1","result":{}}\n{"jsonrpc":"2.0","id":"2","result":{}}\n'; sleep 30`)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := transport.ReceiveWithContext(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		first, err := transport.ReceiveWithContext(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID("1"), first.ID)
+
+		second, err := transport.Receive()
+		require.NoError(t, err)
+		assert.Equal(t, protocol.StringID("2"), second.ID)
+	})
+}
+
+func TestStdioTransportExitStatus(t *testing.T) {
+	t.Run("reports a crash in Receive", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c", "exit 3")
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 3")
+
+		var exitErr *exec.ExitError
+		assert.ErrorAs(t, err, &exitErr)
+		assert.ErrorAs(t, transport.ExitError(), &exitErr)
+		assert.Equal(t, 3, transport.ExitCode())
+	})
+
+	t.Run("reports a clean exit", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("true")
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 0")
+		assert.NoError(t, transport.ExitError())
+		assert.Equal(t, 0, transport.ExitCode())
+	})
+
+	t.Run("treats a process stopped by Close as shut down", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("sleep 30")
+		require.NoError(t, transport.Start())
+
+		assert.ErrorIs(t, transport.ExitError(), protocol.ErrNotExited)
+		assert.Equal(t, -1, transport.ExitCode())
+
+		require.NoError(t, transport.Close())
+		assert.NoError(t, transport.ExitError())
+		assert.Equal(t, -1, transport.ExitCode())
+	})
+}
+
+func TestStdioTransportOnDisconnect(t *testing.T) {
+	t.Run("fires when the server exits", func(t *testing.T) {
+		transport := protocol.NewStdioTransportCommand("sh", "-c", "exit 2")
+		dropped := make(chan error, 1)
+		transport.OnDisconnect(func(err error) { dropped <- err })
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		_, err := transport.Receive()
+		require.Error(t, err)
+
+		select {
+		case dropErr := <-dropped:
+			assert.Equal(t, err, dropErr)
+			assert.False(t, transport.IsConnected())
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected OnDisconnect to fire")
+		}
+	})
+
+	t.Run("does not fire on Close", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("cat")
+		dropped := make(chan error, 1)
+		transport.OnDisconnect(func(err error) { dropped <- err })
+		require.NoError(t, transport.Start())
+
+		received := make(chan error, 1)
+		go func() {
+			_, err := transport.Receive()
+			received <- err
+		}()
+
+		require.NoError(t, transport.Close())
+		assert.Error(t, <-received)
+		assert.Empty(t, dropped)
+	})
+
+	t.Run("handlers may call back into the transport", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("true")
+		done := make(chan bool, 1)
+		transport.OnDisconnect(func(err error) { done <- transport.IsConnected() })
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		transport.Receive()
+
+		select {
+		case connected := <-done:
+			assert.False(t, connected)
+		case <-time.After(5 * time.Second):
+			t.Fatal("OnDisconnect handler deadlocked")
+		}
+	})
+}
+
+func TestStdioTransportFraming(t *testing.T) {
+	t.Run("round-trips Content-Length frames", func(t *testing.T) {
+		transport := protocol.NewStdioTransport("cat")
+		transport.SetFraming(protocol.FramingContentLength)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		for _, id := range []string{"1", "2"} {
+			require.NoError(t, transport.Send(protocol.NewRequest(protocol.StringID(id), "ping", nil)))
+		}
+
+		for _, id := range []string{"1", "2"} {
+			response, err := transport.Receive()
+			require.NoError(t, err)
+			assert.Equal(t, protocol.StringID(id), response.ID)
+		}
+	})
+
+	t.Run("writes a Content-Length header", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		transport := protocol.NewStdioTransportCommand("sh", "-c", "cat > "+out)
+		transport.SetFraming(protocol.FramingContentLength)
+		require.NoError(t, transport.Start())
+
+		defer transport.Close()
+
+		require.NoError(t, transport.Send(protocol.NewRequest(protocol.NumberID(1), "ping", nil)))
+
+		body := `{"jsonrpc":"2.0","method":"ping","id":1}`
+		want := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+		assert.Eventually(t, func() bool {
+			written, _ := os.ReadFile(out)
+			return string(written) == want
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("reads pretty-printed and newline frames alike", func(t *testing.T) {
+		pretty := "{\n  \"jsonrpc\": \"2.0\",\n  \"id\": \"1\",\n  \"result\": {}\n}"
+		frames := fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/json\r\n\r\n%s", len(pretty), pretty) +
+			`{"jsonrpc":"2.0","id":"2","result":{}}` + "\n" +
+			fmt.Sprintf("content-length: %d\n\n%s", len(pretty), strings.Replace(pretty, `"1"`, `"3"`, 1))
+
+		file := filepath.Join(t.TempDir(), "frames")
+		require.NoError(t, os.WriteFile(file, []byte(frames), 0o644))
+
+		transport := protocol.NewStdioTransportCommand("cat", file)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		for _, id := range []string{"1", "2", "3"} {
+			response, err := transport.Receive()
+			require.NoError(t, err)
+			assert.Equal(t, protocol.StringID(id), response.ID)
+		}
+
+		_, err := transport.Receive()
+		assert.ErrorContains(t, err, "EOF")
+	})
+
+	t.Run("rejects a truncated frame", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "frames")
+		require.NoError(t, os.WriteFile(file, []byte("Content-Length: 100\r\n\r\n{}"), 0o644))
+
+		transport := protocol.NewStdioTransportCommand("cat", file)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		_, err := transport.Receive()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestStdioTransportWriteQueue(t *testing.T) {
+	t.Run("answers concurrent calls correctly", func(t *testing.T) {
+		// The script answers the handshake, then answers every other request
+		// by returning the request itself as the result.
+		script := filepath.Join(t.TempDir(), "server.sh")
+		require.NoError(t, os.WriteFile(script, []byte(`while IFS= read -r line; do
+  id=$(printf '%s' "$line" | sed -n 's/.*"id":\(.*\)}$/\1/p')
+  [ -z "$id" ] && continue
+  case "$line" in
+    *'"method":"initialize"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"`+protocol.LatestProtocolVersion+`","capabilities":{"tools":{}},"serverInfo":{"name":"fake","version":"1.0"}}}\n' "$id" ;;
+    *'"method":"mcp.list_tools"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"tools":[]}}\n' "$id" ;;
+    *) printf '{"jsonrpc":"2.0","id":%s,"result":%s}\n' "$id" "$line" ;;
+  esac
+done
+`), 0o755))
+
+		client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+		require.NoError(t, client.Connect(protocol.NewStdioTransport("sh "+script)))
+		defer client.Disconnect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		const calls = 100
+		payload := strings.Repeat("x", 4096)
+
+		var wg sync.WaitGroup
+		for i := 0; i < calls; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				name := fmt.Sprintf("tool-%d", i)
+				result, err := client.CallTool(ctx, name, map[string]interface{}{"n": i, "payload": payload})
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				echoed, ok := result.(map[string]interface{})
+				if !assert.True(t, ok, "unexpected result %v", result) {
+					return
+				}
+				assert.Equal(t, name, echoed["method"])
+				params, _ := echoed["params"].(map[string]interface{})
+				assert.Equal(t, float64(i), params["n"])
+				assert.Equal(t, payload, params["payload"])
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("reports queued frames and releases them on Close", func(t *testing.T) {
+		// sleep never reads stdin, so once the pipe fills every write blocks.
+		transport := protocol.NewStdioTransport("sleep 30")
+		require.NoError(t, transport.Start())
+
+		frame := map[string]interface{}{"payload": strings.Repeat("x", 256*1024)}
+		errs := make(chan error, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				errs <- transport.Send(protocol.NewRequest(protocol.NumberID(int64(i)), "big", frame))
+			}()
+		}
+
+		assert.Eventually(t, func() bool { return transport.QueueDepth() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+		// Close is not held up by the blocked writes.
+		closed := make(chan error, 1)
+		go func() { closed <- transport.Close() }()
+		select {
+		case err := <-closed:
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Close blocked behind queued writes")
+		}
+
+		for i := 0; i < 3; i++ {
+			assert.Error(t, <-errs)
+		}
+		assert.Equal(t, 0, transport.QueueDepth())
+	})
+
+	t.Run("never writes a send after it is cancelled", func(t *testing.T) {
+		// The server reads nothing for a while, so the pipe fills and the
+		// sends below contend for the writer, then copies stdin to a file.
+		dir := t.TempDir()
+		received := filepath.Join(dir, "received")
+		script := filepath.Join(dir, "server.sh")
+		require.NoError(t, os.WriteFile(script, []byte("sleep 1\nexec cat > "+received+"\n"), 0o755))
+
+		transport := protocol.NewStdioTransport("sh " + script)
+		require.NoError(t, transport.Start())
+		defer transport.Close()
+
+		big := make(chan error, 1)
+		go func() {
+			big <- transport.Send(protocol.NewRequest(protocol.NumberID(0), "big", map[string]interface{}{"payload": strings.Repeat("x", 256*1024)}))
+		}()
+		require.Eventually(t, func() bool { return transport.QueueDepth() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		const sends = 20
+		var wg sync.WaitGroup
+		for i := 0; i < sends; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+
+				err := transport.SendWithContext(ctx, protocol.NewRequest(protocol.NumberID(int64(i+1)), "cancelled", nil))
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			}()
+		}
+		wg.Wait()
+
+		// The cancelled sends left nothing waiting behind them.
+		assert.Equal(t, 1, transport.QueueDepth())
+
+		require.NoError(t, <-big)
+		require.NoError(t, transport.Send(protocol.NewRequest(protocol.NumberID(sends+1), "last", nil)))
+
+		var data []byte
+		require.Eventually(t, func() bool {
+			data, _ = os.ReadFile(received)
+			return strings.Contains(string(data), `"method":"last"`)
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.NotContains(t, string(data), "cancelled")
+		assert.Equal(t, 2, strings.Count(string(data), "\n"))
+	})
+}
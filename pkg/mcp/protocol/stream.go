@@ -0,0 +1,177 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrToolResultError is reported by CallToolStream when the final result has
+// isError set. Its content is still delivered.
+var ErrToolResultError = errors.New("tool reported an error")
+
+// CallToolStream calls a tool and yields its output as it is produced. The
+// server streams chunks in either of two ways, which may be mixed:
+//
+//   - as notifications/progress for the call's progress token, each carrying a
+//     "content" array of content items;
+//   - as partial results: responses with the call's request ID whose result
+//     has _meta.partial set to true and a "content" array.
+//
+// The server signals completion by sending a result without _meta.partial, or
+// an error, for the request ID. The content of that final result follows the
+// chunks.
+//
+// Items arrive in the order the server sent them, and every chunk sent before
+// the result precedes the result's content. The content channel is closed once
+// the call has finished and everything has been delivered. The error channel
+// then receives at most one error, from the call, from decoding, or
+// ErrToolResultError, and is closed as well. The caller must keep reading the
+// content channel until it is closed or cancel ctx; after cancellation the
+// error channel reports ctx.Err().
+func (c *Client) CallToolStream(ctx context.Context, name string, params map[string]interface{}, opts ...CallOption) (<-chan Content, <-chan error) {
+	content := make(chan Content)
+	errs := make(chan error, 1)
+
+	token := c.ids.NextID().String()
+	queue := newContentQueue()
+
+	// Chunks are queued rather than sent directly, as the handlers run on the
+	// read goroutine and must not wait for the caller.
+	chunk := func(fields map[string]interface{}) {
+		items, _ := fields["content"].([]interface{})
+		decoded, err := DecodeContent(items)
+		if err != nil {
+			queue.fail(fmt.Errorf("invalid streamed content: %w", err))
+			return
+		}
+		queue.push(decoded)
+	}
+	c.notifications.addProgressHandler(token, chunk)
+
+	onPartial := func(partial *JSONRPCResponse) {
+		result, _ := partial.Result.(map[string]interface{})
+		chunk(result)
+	}
+
+	go func() {
+		// The full slice expression keeps append from writing into spare
+		// capacity of the caller's slice.
+		opts := append(opts[:len(opts):len(opts)], WithMeta(map[string]interface{}{"progressToken": token}))
+		result, err := c.callTool(ctx, name, params, onPartial, opts...)
+		c.notifications.removeProgress(token)
+
+		if err != nil {
+			queue.finish(err)
+			return
+		}
+
+		decoded, err := DecodeCallToolResult(result)
+		if err != nil {
+			queue.finish(err)
+			return
+		}
+
+		queue.push(decoded.Content)
+		if decoded.IsError {
+			queue.finish(ErrToolResultError)
+			return
+		}
+		queue.finish(nil)
+	}()
+
+	go func() {
+		defer close(errs)
+		defer close(content)
+
+		for {
+			items, done, err := queue.next(ctx)
+			for _, item := range items {
+				select {
+				case content <- item:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+
+			if done {
+				if err != nil {
+					errs <- err
+				}
+				return
+			}
+		}
+	}()
+
+	return content, errs
+}
+
+// contentQueue buffers streamed content between the read goroutine and the
+// consumer, without bound.
+type contentQueue struct {
+	items []Content
+	done  bool
+	err   error
+	ready chan struct{}
+	mutex sync.Mutex
+}
+
+func newContentQueue() *contentQueue {
+	return &contentQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *contentQueue) push(items []Content) {
+	q.mutex.Lock()
+	q.items = append(q.items, items...)
+	q.mutex.Unlock()
+	q.notify()
+}
+
+// fail records err to be reported once the call finishes, keeping the first.
+func (q *contentQueue) fail(err error) {
+	q.mutex.Lock()
+	if q.err == nil {
+		q.err = err
+	}
+	q.mutex.Unlock()
+}
+
+func (q *contentQueue) finish(err error) {
+	q.mutex.Lock()
+	q.done = true
+	if err != nil {
+		q.err = err
+	}
+	q.mutex.Unlock()
+	q.notify()
+}
+
+func (q *contentQueue) notify() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for queued items or the end of the call and takes everything
+// available. done is only reported together with the last items.
+func (q *contentQueue) next(ctx context.Context) ([]Content, bool, error) {
+	for {
+		q.mutex.Lock()
+		items, done, err := q.items, q.done, q.err
+		q.items = nil
+		q.mutex.Unlock()
+
+		if len(items) > 0 || done {
+			return items, done, err
+		}
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package protocol_test
+
+import (
+	"context"
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCallToolStream(t *testing.T) {
+	text := func(s string) map[string]interface{} {
+		return map[string]interface{}{"type": "text", "text": s}
+	}
+	chunk := func(token interface{}, items ...interface{}) *protocol.JSONRPCResponse {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: protocol.JSONRPCVersion,
+			Method:  "notifications/progress",
+			Params: map[string]interface{}{
+				"progressToken": token,
+				"content":       items,
+			},
+		}
+	}
+
+	partial := func(id protocol.RequestID, items ...interface{}) *protocol.JSONRPCResponse {
+		return protocol.NewResponse(id, map[string]interface{}{
+			"_meta":   map[string]interface{}{"partial": true},
+			"content": items,
+		})
+	}
+
+	transport := newScriptedTransport(handshakeHandler(func(req *protocol.JSONRPCRequest) []*protocol.JSONRPCResponse {
+		meta, _ := req.Params["_meta"].(map[string]interface{})
+		token := meta["progressToken"]
+
+		switch req.Method {
+		case "logs":
+			return []*protocol.JSONRPCResponse{
+				chunk(token, text("one")),
+				chunk("someone-else", text("not ours")),
+				chunk(token, text("two"), text("three")),
+				protocol.NewResponse(req.ID, map[string]interface{}{
+					"content": []interface{}{text("done")},
+				}),
+			}
+		case "frames":
+			return []*protocol.JSONRPCResponse{
+				partial(req.ID, text("one")),
+				chunk(token, text("two")),
+				partial(req.ID, text("three"), text("four")),
+				protocol.NewResponse(req.ID, map[string]interface{}{
+					"_meta":   map[string]interface{}{"partial": false},
+					"content": []interface{}{text("done")},
+				}),
+			}
+		case "frames-then-error":
+			return []*protocol.JSONRPCResponse{
+				partial(req.ID, text("partial")),
+				{
+					JSONRPC: protocol.JSONRPCVersion,
+					ID:      req.ID,
+					Error:   &protocol.JSONRPCError{Code: protocol.ErrInternalError, Message: "crashed"},
+				},
+			}
+		case "hang":
+			return []*protocol.JSONRPCResponse{chunk(token, text("started"))}
+		case "broken":
+			return []*protocol.JSONRPCResponse{
+				chunk(token, text("partial")),
+				protocol.NewResponse(req.ID, map[string]interface{}{
+					"content": []interface{}{text("failed")},
+					"isError": true,
+				}),
+			}
+		}
+		return []*protocol.JSONRPCResponse{{
+			JSONRPC: protocol.JSONRPCVersion,
+			ID:      req.ID,
+			Error:   &protocol.JSONRPCError{Code: protocol.MethodNotFound, Message: "no such tool"},
+		}}
+	}))
+
+	client := protocol.NewClient(protocol.ClientInfo{Name: "test", Version: "1.0"})
+	require.NoError(t, client.Connect(transport))
+	defer client.Disconnect()
+
+	collect := func(content <-chan protocol.Content, errs <-chan error) ([]string, error) {
+		var texts []string
+		for item := range content {
+			texts = append(texts, item.(protocol.TextContent).Text)
+		}
+		return texts, <-errs
+	}
+
+	t.Run("yields chunks in order followed by the result", func(t *testing.T) {
+		texts, err := collect(client.CallToolStream(context.Background(), "logs", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "two", "three", "done"}, texts)
+	})
+
+	t.Run("yields partial results until the final one", func(t *testing.T) {
+		texts, err := collect(client.CallToolStream(context.Background(), "frames", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "two", "three", "four", "done"}, texts)
+	})
+
+	t.Run("ends a stream of partial results with an error", func(t *testing.T) {
+		texts, err := collect(client.CallToolStream(context.Background(), "frames-then-error", nil))
+		assert.ErrorContains(t, err, "crashed")
+		assert.Equal(t, []string{"partial"}, texts)
+	})
+
+	t.Run("CallTool waits past partial results", func(t *testing.T) {
+		result, err := client.CallTool(context.Background(), "frames", nil)
+		require.NoError(t, err)
+
+		decoded, err := protocol.DecodeCallToolResult(result)
+		require.NoError(t, err)
+		assert.Equal(t, []protocol.Content{protocol.TextContent{Type: "text", Text: "done"}}, decoded.Content)
+	})
+
+	t.Run("reports an error result after its content", func(t *testing.T) {
+		texts, err := collect(client.CallToolStream(context.Background(), "broken", nil))
+		assert.ErrorIs(t, err, protocol.ErrToolResultError)
+		assert.Equal(t, []string{"partial", "failed"}, texts)
+	})
+
+	t.Run("leaves the caller's options alone", func(t *testing.T) {
+		opts := make([]protocol.CallOption, 1, 2)
+		opts[0] = protocol.WithMeta(map[string]interface{}{"traceId": "abc123"})
+
+		_, err := collect(client.CallToolStream(context.Background(), "logs", nil, opts...))
+		require.NoError(t, err)
+		assert.Nil(t, opts[:2][1], "spare capacity must not be written to")
+	})
+
+	t.Run("reports a failed call", func(t *testing.T) {
+		texts, err := collect(client.CallToolStream(context.Background(), "missing", nil))
+		assert.ErrorContains(t, err, "no such tool")
+		assert.Empty(t, texts)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		content, errs := client.CallToolStream(ctx, "hang", nil)
+
+		<-content
+		cancel()
+
+		for range content {
+		}
+		assert.ErrorIs(t, <-errs, context.Canceled)
+	})
+}
@@ -19,6 +19,32 @@ type Transport interface {
 	IsConnected() bool
 }
 
+// BatchSender is implemented by transports that can put several requests in a
+// single JSON-RPC batch frame.
+type BatchSender interface {
+	SendBatch(requests []*JSONRPCRequest) error
+}
+
+// ResponseSender is implemented by transports that can answer requests made
+// by the server.
+type ResponseSender interface {
+	SendResponse(response *JSONRPCResponse) error
+}
+
+// StateAware is implemented by transports that report losing their
+// connection. Handlers registered with OnDisconnect run when the connection
+// drops for any reason other than Close, such as the server crashing, and
+// receive the error that revealed it.
+type StateAware interface {
+	OnDisconnect(handler func(err error))
+}
+
+// QueueReporter is implemented by transports that queue outgoing frames.
+// QueueDepth is the number of frames waiting to be written.
+type QueueReporter interface {
+	QueueDepth() int
+}
+
 type ReadWriteCloser interface {
 	io.Reader
 	io.Writer
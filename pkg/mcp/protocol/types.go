@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -17,8 +18,6 @@ type ProgressToken interface{}
 
 type Cursor string
 
-type RequestID interface{}
-
 type Role string
 
 const (
@@ -28,14 +27,14 @@ const (
 
 type RequestMessage struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      json.RawMessage `json:"id"`
+	ID      RequestID       `json:"id"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
 type ResponseMessage struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      json.RawMessage `json:"id"`
+	ID      RequestID       `json:"id"`
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *ErrorMessage   `json:"error,omitempty"`
 }
@@ -80,11 +79,13 @@ type ClientCapabilities struct {
 	Experimental map[string]interface{} `json:"experimental,omitempty"`
 	Roots        *RootsCapability       `json:"roots,omitempty"`
 	Sampling     *struct{}              `json:"sampling,omitempty"`
+	Elicitation  *struct{}              `json:"elicitation,omitempty"`
 }
 
 type ServerCapabilities struct {
 	Experimental map[string]interface{} `json:"experimental,omitempty"`
 	Logging      *struct{}              `json:"logging,omitempty"`
+	Completions  *struct{}              `json:"completions,omitempty"`
 	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
 	Resources    *ResourcesCapability   `json:"resources,omitempty"`
 	Tools        *ToolsCapability       `json:"tools,omitempty"`
@@ -153,91 +154,113 @@ type BlobResourceContents struct {
 	Blob string `json:"blob"`
 }
 
+var ErrNoToolHandler = errors.New("tool has no execute handler")
+
+type ToolHandler func(args map[string]interface{}) (*CallToolResult, error)
+
 type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// InputSchema is the JSON schema of the tool's arguments. A nil or empty
+	// schema places no constraints on them, like the JSON schema {}, so
+	// ValidateArguments accepts any arguments.
 	InputSchema map[string]interface{} `json:"inputSchema"`
+
+	// Execute runs the tool locally. Tools discovered from a remote server
+	// leave it nil and are invoked through the client instead.
+	Execute ToolHandler `json:"-"`
+
+	// ApplyDefaults makes ValidateAndExecute fill in absent top-level
+	// arguments from the "default" of their property schema before
+	// validating them.
+	ApplyDefaults bool `json:"-"`
+
+	// SensitiveFields names top-level arguments holding secrets, in addition
+	// to properties whose schema sets "x-sensitive": true. Their values are
+	// redacted from audit events, dry runs and validation errors.
+	SensitiveFields []string `json:"-"`
+
+	// compiled, when set by Compile, holds what validation reads from
+	// InputSchema on every call.
+	compiled *compiledSchema
 }
 
-func (t *Tool) ValidateAndExecute(args map[string]interface{}) (*CallToolResult, error) {
-	if err := t.ValidateArguments(args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
+// MarshalJSON sends a nil or empty InputSchema as {"type":"object"}, since
+// MCP requires every tool to advertise an object schema.
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type tool Tool
+
+	if acceptsAnything(t.InputSchema) {
+		t.InputSchema = map[string]interface{}{"type": "object"}
 	}
+	return json.Marshal(tool(t))
+}
 
-	//TODO: Mock Result
-	return &CallToolResult{
-		Content: []Content{
-			TextContent{
-				Type: string(ContentTypeText),
-				Text: "Tool execution result",
-			},
-		},
-	}, nil
-}
-
-func (t *Tool) ValidateArguments(args map[string]interface{}) error {
-	schema := t.InputSchema
-
-	if required, ok := schema["required"].([]string); ok {
-		for _, field := range required {
-			if _, exists := args[field]; !exists {
-				return fmt.Errorf("missing required field: %s", field)
-			}
-		}
+// Clone returns a copy of t whose InputSchema shares no maps or slices with
+// the original, so either can be modified without affecting the other.
+func (t *Tool) Clone() *Tool {
+	clone := *t
+	// The copy's schema may be changed, which Compile's result would miss.
+	clone.compiled = nil
+	if t.InputSchema != nil {
+		clone.InputSchema = copyValue(t.InputSchema).(map[string]interface{})
+	}
+	if t.SensitiveFields != nil {
+		clone.SensitiveFields = append([]string(nil), t.SensitiveFields...)
 	}
+	return &clone
+}
 
-	if props, ok := schema["properties"].(map[string]interface{}); ok {
-		for name, value := range args {
-			if propSchema, exists := props[name]; exists {
-				if err := ValidateType(propSchema.(map[string]interface{}), value); err != nil {
-					return fmt.Errorf("invalid argument %s: %w", name, err)
-				}
-			}
+// copyValue deep-copies the maps and slices of a schema or decoded JSON value.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
 		}
+		return copied
+	case []string:
+		return append([]string(nil), v...)
+	default:
+		return value
 	}
-
-	return nil
 }
 
-func ValidateType(schema map[string]interface{}, value interface{}) error {
-	expectedType, ok := schema["type"].(string)
-	if !ok {
-		return fmt.Errorf("schema missing type")
+// ValidateAndExecute validates args against InputSchema and runs Execute with
+// them. Execute always receives a non-nil map, even for a call without
+// arguments.
+func (t *Tool) ValidateAndExecute(args map[string]interface{}) (*CallToolResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	if t.ApplyDefaults {
+		args = withDefaults(t.InputSchema, args)
 	}
 
-	switch expectedType {
-	case "string":
-		if _, ok := value.(string); !ok {
-			return fmt.Errorf("expected string, got %T", value)
-		}
-	case "number":
-		switch v := value.(type) {
-		case float64, float32, int, int32, int64:
-		default:
-			return fmt.Errorf("expected number, got %T", v)
-		}
-	case "boolean":
-		if _, ok := value.(bool); !ok {
-			return fmt.Errorf("expected boolean, got %T", value)
-		}
-	case "array":
-		if _, ok := value.([]interface{}); !ok {
-			return fmt.Errorf("expected array, got %T", value)
-		}
-	case "object":
-		if _, ok := value.(map[string]interface{}); !ok {
-			return fmt.Errorf("expected object, got %T", value)
-		}
-	default:
-		return fmt.Errorf("unsupported type: %s", expectedType)
+	if err := t.ValidateArguments(args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if t.Execute == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoToolHandler, t.Name)
 	}
 
-	return nil
+	return t.Execute(args)
 }
 
 type CallToolResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content []Content              `json:"content"`
+	IsError bool                   `json:"isError,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
 }
 
 type ContentType string
@@ -246,6 +269,7 @@ const (
 	ContentTypeText     ContentType = "text"
 	ContentTypeImage    ContentType = "image"
 	ContentTypeResource ContentType = "resource"
+	ContentTypeAudio    ContentType = "audio"
 )
 
 type Content interface {
@@ -278,6 +302,18 @@ func (ic ImageContent) GetType() ContentType {
 	return ContentTypeImage
 }
 
+// AudioContent carries base64-encoded audio data.
+type AudioContent struct {
+	Type        ContentType `json:"type"`
+	Data        string      `json:"data"`
+	MimeType    string      `json:"mimeType"`
+	Annotations *Annotation `json:"annotations,omitempty"`
+}
+
+func (ac AudioContent) GetType() ContentType {
+	return ContentTypeAudio
+}
+
 type EmbeddedResource struct {
 	Type        ContentType      `json:"type"`
 	Resource    ResourceContents `json:"resource"`
@@ -316,11 +352,18 @@ type Root struct {
 }
 
 type ListToolsResponse struct {
-	Tools []Tool `json:"tools"`
+	Tools      []Tool `json:"tools"`
+	NextCursor Cursor `json:"nextCursor,omitempty"`
 }
 
 type ListResourcesResponse struct {
-	Resources []Resource `json:"resources"`
+	Resources  []Resource `json:"resources"`
+	NextCursor Cursor     `json:"nextCursor,omitempty"`
+}
+
+type ListResourceTemplatesResponse struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        Cursor             `json:"nextCursor,omitempty"`
 }
 
 type ToolCall struct {
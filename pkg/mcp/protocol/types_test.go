@@ -2,6 +2,7 @@ package protocol_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"go-mcp/pkg/mcp/protocol"
 	"testing"
 
@@ -77,6 +78,14 @@ func TestTool(t *testing.T) {
 				},
 				"required": []string{"a", "b"},
 			},
+			Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+				sum := args["a"].(int) + args["b"].(int)
+				return &protocol.CallToolResult{
+					Content: []protocol.Content{
+						protocol.TextContent{Type: string(protocol.ContentTypeText), Text: fmt.Sprint(sum)},
+					},
+				}, nil
+			},
 		}
 
 		result, err := tool.ValidateAndExecute(map[string]interface{}{
@@ -85,6 +94,543 @@ func TestTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 		assert.NotNil(t, result)
-		assert.Len(t, result.Content, 1)
+		require.Len(t, result.Content, 1)
+		assert.Equal(t, "8", result.Content[0].(protocol.TextContent).Text)
+	})
+
+	t.Run("errors when no handler is set", func(t *testing.T) {
+		tool := protocol.Tool{
+			Name:        "remote_only",
+			InputSchema: map[string]interface{}{"type": "object"},
+		}
+
+		_, err := tool.ValidateAndExecute(map[string]interface{}{})
+		assert.ErrorIs(t, err, protocol.ErrNoToolHandler)
+	})
+	t.Run("advertises an object schema when it has none", func(t *testing.T) {
+		for _, schema := range []map[string]interface{}{nil, {}} {
+			data, err := json.Marshal(protocol.Tool{Name: "ping", InputSchema: schema})
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"name":"ping","inputSchema":{"type":"object"}}`, string(data))
+		}
+
+		data, err := json.Marshal(&protocol.Tool{Name: "ping", InputSchema: map[string]interface{}{"type": "object", "required": []string{"host"}}})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"ping","inputSchema":{"type":"object","required":["host"]}}`, string(data))
+	})
+
+	t.Run("reports missing required fields from a JSON schema", func(t *testing.T) {
+		var tool protocol.Tool
+		err := json.Unmarshal([]byte(`{
+			"name": "add_numbers",
+			"inputSchema": {
+				"type": "object",
+				"properties": {
+					"a": {"type": "number"},
+					"b": {"type": "number"}
+				},
+				"required": ["a", "b"]
+			}
+		}`), &tool)
+		require.NoError(t, err)
+
+		err = tool.ValidateArguments(map[string]interface{}{"a": 1.0})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing required field: b")
+
+		assert.NoError(t, tool.ValidateArguments(map[string]interface{}{"a": 1.0, "b": 2.0}))
+	})
+
+	t.Run("applies schema defaults to absent arguments", func(t *testing.T) {
+		var received map[string]interface{}
+		tool := protocol.Tool{
+			Name: "search",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{"type": "string"},
+					"limit": map[string]interface{}{"type": "integer", "default": 10.0},
+					"tags":  map[string]interface{}{"type": "array", "default": []interface{}{"all"}},
+				},
+				"required": []string{"query", "limit"},
+			},
+			Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+				received = args
+				return &protocol.CallToolResult{}, nil
+			},
+		}
+
+		args := map[string]interface{}{"query": "go", "tags": []interface{}{"mcp"}}
+
+		_, err := tool.ValidateAndExecute(args)
+		assert.Error(t, err, "defaults are opt-in")
+
+		tool.ApplyDefaults = true
+		_, err = tool.ValidateAndExecute(args)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"query": "go", "limit": 10.0, "tags": []interface{}{"mcp"}}, received)
+		assert.NotContains(t, args, "limit", "the caller's map is left untouched")
+
+		_, err = tool.ValidateAndExecute(map[string]interface{}{"query": "go", "limit": 3.0})
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, received["limit"])
+
+		received["tags"].([]interface{})[0] = "changed"
+		schemaTags := tool.InputSchema["properties"].(map[string]interface{})["tags"].(map[string]interface{})
+		assert.Equal(t, []interface{}{"all"}, schemaTags["default"])
+	})
+
+	t.Run("reports missing required fields from a Go schema", func(t *testing.T) {
+		tool := protocol.Tool{
+			Name: "add_numbers",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"a"},
+			},
+		}
+
+		err := tool.ValidateArguments(map[string]interface{}{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing required field: a")
+	})
+
+	t.Run("accepts any arguments without a schema", func(t *testing.T) {
+		for name, schema := range map[string]map[string]interface{}{
+			"nil":   nil,
+			"empty": {},
+		} {
+			var received []map[string]interface{}
+			tool := protocol.Tool{
+				Name:          "tool1",
+				InputSchema:   schema,
+				ApplyDefaults: true,
+				Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+					received = append(received, args)
+					return &protocol.CallToolResult{}, nil
+				},
+			}
+
+			assert.NoError(t, tool.ValidateArguments(nil), name)
+			assert.NoError(t, tool.ValidateArguments(map[string]interface{}{"anything": []interface{}{1, "two"}}), name)
+
+			_, err := tool.ValidateAndExecute(nil)
+			require.NoError(t, err, name)
+			_, err = tool.ValidateAndExecute(map[string]interface{}{"path": "/tmp"})
+			require.NoError(t, err, name)
+
+			assert.Equal(t, []map[string]interface{}{{}, {"path": "/tmp"}}, received, name)
+			assert.NotNil(t, received[0], "%s: Execute should get an empty map for a call without arguments", name)
+		}
+	})
+
+	t.Run("validates the same once compiled", func(t *testing.T) {
+		tool := &protocol.Tool{
+			Name: "deploy",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"env"},
+				"properties": map[string]interface{}{
+					"env":     map[string]interface{}{"type": "string", "enum": []string{"dev", "prod"}},
+					"version": map[string]interface{}{"type": "string", "pattern": "^v[0-9]+$"},
+					"broken":  map[string]interface{}{"type": "string", "pattern": "("},
+				},
+			},
+		}
+		cases := []map[string]interface{}{
+			{},
+			{"env": "dev", "version": "v2"},
+			{"env": "staging"},
+			{"env": "dev", "version": "2"},
+			{"env": "dev", "broken": "x"},
+		}
+
+		var want []error
+		for _, args := range cases {
+			want = append(want, tool.ValidateArguments(args))
+		}
+
+		tool.Compile()
+		for i, args := range cases {
+			assert.Equal(t, want[i], tool.ValidateArguments(args), "%v", args)
+		}
+
+		// A clone is not compiled, so changes to its schema take effect.
+		clone := tool.Clone()
+		clone.InputSchema["required"] = []interface{}{"version"}
+		assert.EqualError(t, clone.ValidateArguments(map[string]interface{}{"env": "dev"}), "missing required field: version")
 	})
+
+	t.Run("reports a property schema that is not an object", func(t *testing.T) {
+		tool := protocol.Tool{
+			Name: "read_file",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":  "string",
+					"limit": map[string]interface{}{"type": "integer"},
+				},
+			},
+		}
+
+		var err error
+		assert.NotPanics(t, func() {
+			err = tool.ValidateArguments(map[string]interface{}{"path": "/tmp/notes.txt"})
+		})
+		assert.EqualError(t, err, "invalid argument path: malformed schema: expected an object, got string")
+
+		assert.NoError(t, tool.ValidateArguments(map[string]interface{}{"limit": 10}))
+	})
+}
+
+func TestValidateType(t *testing.T) {
+	t.Run("accepts integers and whole floats for integer", func(t *testing.T) {
+		schema := map[string]interface{}{"type": "integer"}
+
+		assert.NoError(t, protocol.ValidateType(schema, 3))
+		assert.NoError(t, protocol.ValidateType(schema, int64(3)))
+		assert.NoError(t, protocol.ValidateType(schema, 3.0))
+		assert.Error(t, protocol.ValidateType(schema, 3.5))
+		assert.Error(t, protocol.ValidateType(schema, "3"))
+	})
+
+	t.Run("accepts every Go numeric type for number", func(t *testing.T) {
+		schema := map[string]interface{}{"type": "number"}
+
+		for _, value := range []interface{}{
+			1.5, float32(1.5), 3, int8(3), int16(3), int32(3), int64(3),
+			uint(3), uint8(3), uint16(3), uint32(3), uint64(3),
+		} {
+			assert.NoError(t, protocol.ValidateType(schema, value), "%T", value)
+		}
+		assert.Error(t, protocol.ValidateType(schema, "3"))
+		assert.Error(t, protocol.ValidateType(schema, true))
+	})
+
+	t.Run("enforces enum membership", func(t *testing.T) {
+		schema := map[string]interface{}{
+			"type": "string",
+			"enum": []interface{}{"a", "b", "c"},
+		}
+
+		assert.NoError(t, protocol.ValidateType(schema, "b"))
+
+		err := protocol.ValidateType(schema, "foo")
+		require.Error(t, err)
+		assert.Equal(t, `value "foo" not in enum [a b c]`, err.Error())
+	})
+
+	t.Run("compares numeric enums across numeric types", func(t *testing.T) {
+		schema := map[string]interface{}{
+			"type": "integer",
+			"enum": []interface{}{1.0, 2.0},
+		}
+
+		assert.NoError(t, protocol.ValidateType(schema, 2))
+		assert.Error(t, protocol.ValidateType(schema, 3))
+	})
+}
+
+func TestNestedValidation(t *testing.T) {
+	tool := protocol.Tool{
+		Name: "deploy",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"config": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"retries": map[string]interface{}{"type": "number"},
+					},
+					"required": []interface{}{"retries"},
+				},
+				"tags": map[string]interface{}{
+					"type":  "array",
+					"items": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+
+	t.Run("accepts valid nested values", func(t *testing.T) {
+		err := tool.ValidateArguments(map[string]interface{}{
+			"config": map[string]interface{}{"retries": 3.0},
+			"tags":   []interface{}{"a", "b"},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports the path of a nested type error", func(t *testing.T) {
+		err := tool.ValidateArguments(map[string]interface{}{
+			"config": map[string]interface{}{"retries": "lots"},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid argument config.retries: expected number, got string", err.Error())
+	})
+
+	t.Run("reports missing nested required fields", func(t *testing.T) {
+		err := tool.ValidateArguments(map[string]interface{}{
+			"config": map[string]interface{}{},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid argument config: missing required field: retries", err.Error())
+	})
+
+	t.Run("validates each array item", func(t *testing.T) {
+		err := tool.ValidateArguments(map[string]interface{}{
+			"tags": []interface{}{"a", 2.0},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid argument tags[1]: expected string, got float64", err.Error())
+	})
+}
+
+func TestAdditionalProperties(t *testing.T) {
+	schema := func(additional interface{}) map[string]interface{} {
+		nested := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"retries": map[string]interface{}{"type": "number"},
+			},
+		}
+		schema := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":   map[string]interface{}{"type": "string"},
+				"config": nested,
+			},
+		}
+		if additional != nil {
+			schema["additionalProperties"] = additional
+			nested["additionalProperties"] = additional
+		}
+		return schema
+	}
+
+	args := map[string]interface{}{"name": "web", "nmae": "typo", "config": map[string]interface{}{"retries": 1.0}}
+
+	t.Run("allows unknown keys by default", func(t *testing.T) {
+		for _, additional := range []interface{}{nil, true, map[string]interface{}{"type": "string"}} {
+			tool := protocol.Tool{Name: "deploy", InputSchema: schema(additional)}
+			assert.NoError(t, tool.ValidateArguments(args), "additionalProperties: %v", additional)
+		}
+	})
+
+	t.Run("rejects unknown keys when false", func(t *testing.T) {
+		tool := protocol.Tool{Name: "deploy", InputSchema: schema(false)}
+
+		assert.EqualError(t, tool.ValidateArguments(args), "unexpected argument: nmae")
+
+		err := tool.ValidateArguments(map[string]interface{}{
+			"config": map[string]interface{}{"retries": 1.0, "timeout": 5.0},
+		})
+		assert.EqualError(t, err, "invalid argument config: unexpected field: timeout")
+
+		assert.NoError(t, tool.ValidateArguments(map[string]interface{}{"name": "web"}))
+	})
+}
+
+func TestConstraintValidation(t *testing.T) {
+	t.Run("numeric ranges", func(t *testing.T) {
+		schema := map[string]interface{}{"type": "number", "minimum": 1.0, "maximum": 10.0}
+		assert.NoError(t, protocol.ValidateType(schema, 1.0))
+		assert.NoError(t, protocol.ValidateType(schema, 10))
+		assert.EqualError(t, protocol.ValidateType(schema, 0.5), "value 0.5 is less than minimum 1")
+		assert.EqualError(t, protocol.ValidateType(schema, 11), "value 11 is greater than maximum 10")
+
+		exclusive := map[string]interface{}{"type": "integer", "exclusiveMinimum": 0, "exclusiveMaximum": 5}
+		assert.NoError(t, protocol.ValidateType(exclusive, 1))
+		assert.Error(t, protocol.ValidateType(exclusive, 0))
+		assert.Error(t, protocol.ValidateType(exclusive, 5))
+
+		draft4 := map[string]interface{}{"type": "number", "minimum": 0.0, "exclusiveMinimum": true}
+		assert.Error(t, protocol.ValidateType(draft4, 0.0))
+		assert.NoError(t, protocol.ValidateType(draft4, 0.1))
+	})
+
+	t.Run("string length and pattern", func(t *testing.T) {
+		schema := map[string]interface{}{
+			"type":      "string",
+			"minLength": 2.0,
+			"maxLength": 4.0,
+			"pattern":   "^[a-z]+$",
+		}
+		assert.NoError(t, protocol.ValidateType(schema, "abc"))
+		assert.EqualError(t, protocol.ValidateType(schema, "a"), "string length 1 is less than minLength 2")
+		assert.EqualError(t, protocol.ValidateType(schema, "abcde"), "string length 5 is greater than maxLength 4")
+		assert.EqualError(t, protocol.ValidateType(schema, "AB"), `value "AB" does not match pattern "^[a-z]+$"`)
+	})
+
+	t.Run("array length", func(t *testing.T) {
+		schema := map[string]interface{}{"type": "array", "minItems": 1, "maxItems": 2}
+		assert.NoError(t, protocol.ValidateType(schema, []interface{}{"a"}))
+		assert.EqualError(t, protocol.ValidateType(schema, []interface{}{}), "array length 0 is less than minItems 1")
+		assert.EqualError(t, protocol.ValidateType(schema, []interface{}{1, 2, 3}), "array length 3 is greater than maxItems 2")
+	})
+}
+
+func TestDecodeCallToolResult(t *testing.T) {
+	var raw interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"isError": true,
+		"content": [
+			{"type": "text", "text": "hello"},
+			{"type": "image", "data": "aGk=", "mimeType": "image/png"},
+			{"type": "resource", "resource": {"uri": "file:///a.txt", "mimeType": "text/plain"}}
+		]
+	}`), &raw))
+
+	result, err := protocol.DecodeCallToolResult(raw)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 3)
+
+	text, ok := result.Content[0].(protocol.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "hello", text.Text)
+
+	image, ok := result.Content[1].(protocol.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, "aGk=", image.Data)
+	assert.Equal(t, "image/png", image.MimeType)
+
+	resource, ok := result.Content[2].(protocol.EmbeddedResource)
+	require.True(t, ok)
+	assert.Equal(t, "file:///a.txt", resource.Resource.URI)
+
+	_, err = protocol.DecodeContent([]interface{}{map[string]interface{}{"type": "hologram"}})
+	assert.ErrorContains(t, err, "unknown content type: hologram")
+}
+
+func TestCallToolResultUnmarshalJSON(t *testing.T) {
+	var result protocol.CallToolResult
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"content": [
+			{"type": "text", "text": "hello"},
+			{"type": "image", "data": "aGk=", "mimeType": "image/png"},
+			{"type": "resource", "resource": {"uri": "file:///a.txt", "mimeType": "text/plain"}}
+		],
+		"isError": true
+	}`), &result))
+
+	assert.True(t, result.IsError)
+	assert.Equal(t, []protocol.Content{
+		protocol.TextContent{Type: "text", Text: "hello"},
+		protocol.ImageContent{Type: protocol.ContentTypeImage, Data: "aGk=", MimeType: "image/png"},
+		protocol.EmbeddedResource{Type: protocol.ContentTypeResource, Resource: protocol.ResourceContents{URI: "file:///a.txt", MimeType: "text/plain"}},
+	}, result.Content)
+
+	// Results round-trip through JSON.
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var decoded protocol.CallToolResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, result, decoded)
+
+	err = json.Unmarshal([]byte(`{"content": [{"type": "hologram"}]}`), &decoded)
+	assert.ErrorContains(t, err, "unknown content type: hologram")
+}
+
+func TestAudioContent(t *testing.T) {
+	audio := protocol.AudioContent{
+		Type:        protocol.ContentTypeAudio,
+		Data:        "UklGRg==",
+		MimeType:    "audio/wav",
+		Annotations: &protocol.Annotation{Priority: 0.5},
+	}
+	assert.Equal(t, protocol.ContentTypeAudio, audio.GetType())
+
+	data, err := json.Marshal(audio)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "audio", "data": "UklGRg==", "mimeType": "audio/wav", "annotations": {"priority": 0.5}}`, string(data))
+
+	var raw interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+
+	contents, err := protocol.DecodeContent([]interface{}{raw})
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, audio, contents[0])
+}
+
+func TestToolClone(t *testing.T) {
+	tool := &protocol.Tool{
+		Name: "deploy",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"env": map[string]interface{}{"type": "string", "enum": []interface{}{"dev", "prod"}},
+			},
+			"required": []string{"env"},
+		},
+	}
+
+	clone := tool.Clone()
+	assert.Equal(t, tool, clone)
+
+	clone.Name = "changed"
+	clone.InputSchema["type"] = "array"
+	clone.InputSchema["properties"].(map[string]interface{})["env"].(map[string]interface{})["enum"].([]interface{})[0] = "test"
+	clone.InputSchema["required"].([]string)[0] = "other"
+
+	assert.Equal(t, "deploy", tool.Name)
+	assert.Equal(t, "object", tool.InputSchema["type"])
+	assert.Equal(t, []interface{}{"dev", "prod"}, tool.InputSchema["properties"].(map[string]interface{})["env"].(map[string]interface{})["enum"])
+	assert.Equal(t, []string{"env"}, tool.InputSchema["required"])
+}
+
+func TestCallToolResultValidate(t *testing.T) {
+	t.Run("accepts well-formed content", func(t *testing.T) {
+		result := &protocol.CallToolResult{Content: []protocol.Content{
+			protocol.TextContent{Type: "text", Text: "hello", Annotations: &protocol.Annotation{Priority: 0.5}},
+			&protocol.TextContent{Type: "text", Text: "by pointer"},
+			protocol.ImageContent{Type: protocol.ContentTypeImage, Data: "aGVsbG8=", MimeType: "image/png"},
+			protocol.AudioContent{Type: protocol.ContentTypeAudio, Data: "aGVsbG8=", MimeType: "audio/wav"},
+			protocol.EmbeddedResource{Type: protocol.ContentTypeResource, Resource: protocol.ResourceContents{URI: "file:///a"}},
+		}}
+		assert.NoError(t, result.Validate())
+		assert.NoError(t, (&protocol.CallToolResult{}).Validate())
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		result := &protocol.CallToolResult{Content: []protocol.Content{
+			protocol.TextContent{Text: "no type"},
+			protocol.ImageContent{Type: protocol.ContentTypeImage, Data: "not base64!"},
+			protocol.AudioContent{Type: protocol.ContentTypeAudio, MimeType: "audio/wav", Annotations: &protocol.Annotation{Priority: 2}},
+			protocol.EmbeddedResource{Type: protocol.ContentTypeResource},
+			nil,
+		}}
+
+		err := result.Validate()
+		require.Error(t, err)
+		for _, want := range []string{
+			`content item 0: type is "", want "text"`,
+			"content item 1: data is not valid base64",
+			"content item 1: missing mimeType",
+			"content item 2: missing data",
+			"content item 2: annotation priority 2 is outside [0, 1]",
+			"content item 3: missing resource uri",
+			"content item 4: content is nil",
+		} {
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+}
+
+func TestCallToolResultText(t *testing.T) {
+	image := protocol.ImageContent{Type: protocol.ContentTypeImage, Data: "aGVsbG8=", MimeType: "image/png"}
+	result := &protocol.CallToolResult{Content: []protocol.Content{
+		protocol.TextContent{Type: "text", Text: "first"},
+		image,
+		&protocol.TextContent{Type: "text", Text: "second"},
+		nil,
+		(*protocol.TextContent)(nil),
+	}}
+
+	assert.Equal(t, "first\nsecond", result.Text())
+	assert.Equal(t, []protocol.Content{image}, result.ContentByType(protocol.ContentTypeImage))
+	assert.Len(t, result.ContentByType(protocol.ContentTypeText), 2)
+	assert.Empty(t, result.ContentByType(protocol.ContentTypeAudio))
+
+	assert.Equal(t, "", (&protocol.CallToolResult{}).Text())
 }
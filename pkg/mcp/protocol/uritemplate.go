@@ -0,0 +1,103 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expand fills in the URI template with vars. It supports RFC 6570 simple
+// expansion ({var}), which percent-encodes everything but unreserved
+// characters, and reserved expansion ({+var}), which also keeps reserved
+// characters such as "/" and existing percent-encoded triplets. Several
+// variables may share an expression ({a,b}); their values are joined with
+// commas. Every variable must be provided.
+func (rt ResourceTemplate) Expand(vars map[string]string) (string, error) {
+	var out strings.Builder
+	template := rt.URITemplate
+
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			if strings.IndexByte(template, '}') >= 0 {
+				return "", fmt.Errorf("unmatched '}' in URI template %q", rt.URITemplate)
+			}
+			out.WriteString(template)
+			return out.String(), nil
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed expression in URI template %q", rt.URITemplate)
+		}
+		end += start
+
+		out.WriteString(template[:start])
+
+		expanded, err := expandExpression(template[start+1:end], vars)
+		if err != nil {
+			return "", fmt.Errorf("URI template %q: %w", rt.URITemplate, err)
+		}
+		out.WriteString(expanded)
+
+		template = template[end+1:]
+	}
+}
+
+func expandExpression(expression string, vars map[string]string) (string, error) {
+	reserved := false
+	if strings.HasPrefix(expression, "+") {
+		reserved = true
+		expression = expression[1:]
+	} else if expression != "" && strings.ContainsRune("#./;?&=,!@|", rune(expression[0])) {
+		return "", fmt.Errorf("unsupported operator %q", expression[0])
+	}
+
+	names := strings.Split(expression, ",")
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			return "", fmt.Errorf("empty variable name")
+		}
+
+		value, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("missing variable %s", name)
+		}
+		values = append(values, encodeTemplateValue(value, reserved))
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+const uriReservedChars = ":/?#[]@!$&'()*+,;="
+
+func encodeTemplateValue(value string, reserved bool) string {
+	var out strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case isUnreserved(c):
+			out.WriteByte(c)
+		case reserved && strings.IndexByte(uriReservedChars, c) >= 0:
+			out.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			out.WriteString(value[i : i+3])
+			i += 2
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+
+	return out.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
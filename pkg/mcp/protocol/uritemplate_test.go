@@ -0,0 +1,49 @@
+package protocol_test
+
+import (
+	"go-mcp/pkg/mcp/protocol"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTemplateExpand(t *testing.T) {
+	vars := map[string]string{
+		"path":  "docs/read me.md",
+		"user":  "ada",
+		"query": "a&b",
+		"enc":   "50%25",
+	}
+
+	tests := []struct {
+		template string
+		want     string
+		wantErr  string
+	}{
+		{template: "file:///{path}", want: "file:///docs%2Fread%20me.md"},
+		{template: "file:///{+path}", want: "file:///docs/read%20me.md"},
+		{template: "users://{user}/profile", want: "users://ada/profile"},
+		{template: "search://{query}", want: "search://a%26b"},
+		{template: "search://{+query}", want: "search://a&b"},
+		{template: "x://{+enc}", want: "x://50%25"},
+		{template: "x://{enc}", want: "x://50%2525"},
+		{template: "x://{user,query}", want: "x://ada,a%26b"},
+		{template: "static://no/vars", want: "static://no/vars"},
+		{template: "file:///{missing}", wantErr: "missing variable missing"},
+		{template: "file:///{path", wantErr: "unclosed expression"},
+		{template: "file:///path}", wantErr: "unmatched '}'"},
+		{template: "file:///{?path}", wantErr: "unsupported operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := protocol.ResourceTemplate{URITemplate: tt.template}.Expand(vars)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
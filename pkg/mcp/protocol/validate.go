@@ -0,0 +1,408 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// acceptsAnything reports whether schema places no constraints at all, which
+// is the case for a nil or empty one.
+func acceptsAnything(schema map[string]interface{}) bool {
+	return len(schema) == 0
+}
+
+// ValidateArguments checks args against InputSchema. A tool with a nil or
+// empty InputSchema accepts any arguments, including none.
+func (t *Tool) ValidateArguments(args map[string]interface{}) error {
+	schema := t.InputSchema
+	if acceptsAnything(schema) {
+		return nil
+	}
+
+	compiled := t.compiled
+	for _, field := range compiled.requiredIn(schema) {
+		if _, exists := args[field]; !exists {
+			return fmt.Errorf("missing required field: %s", field)
+		}
+	}
+
+	if field, found := unexpectedField(schema, args); found {
+		return fmt.Errorf("unexpected argument: %s", field)
+	}
+
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, value := range args {
+			prop, exists := props[name]
+			if !exists {
+				continue
+			}
+			propSchema, ok := prop.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid argument %s: %w", name, malformedProperty(prop))
+			}
+			if err := validateType(compiled.property(name), propSchema, value); err != nil {
+				if t.IsSensitive(name) {
+					err = errRedactedValue
+				}
+				pathErr := prefixPath(name, err)
+				return fmt.Errorf("invalid argument %s: %w", pathErr.Path, pathErr.Err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// malformedProperty reports an argument declared by a property whose schema is
+// not an object, such as "path": "string", which could never be validated.
+func malformedProperty(prop interface{}) error {
+	return fmt.Errorf("malformed schema: expected an object, got %T", prop)
+}
+
+// schemaError is a validation failure at Path within the validated value,
+// written like "config.retries" or "tags[2]". An empty Path means the value
+// itself.
+type schemaError struct {
+	Path string
+	Err  error
+}
+
+func (e *schemaError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *schemaError) Unwrap() error {
+	return e.Err
+}
+
+// prefixPath records that err happened inside the field or index segment.
+func prefixPath(segment string, err error) *schemaError {
+	var inner *schemaError
+	if !errors.As(err, &inner) {
+		return &schemaError{Path: segment, Err: err}
+	}
+
+	switch {
+	case inner.Path == "":
+		return &schemaError{Path: segment, Err: inner.Err}
+	case strings.HasPrefix(inner.Path, "["):
+		return &schemaError{Path: segment + inner.Path, Err: inner.Err}
+	default:
+		return &schemaError{Path: segment + "." + inner.Path, Err: inner.Err}
+	}
+}
+
+func validateObject(compiled *compiledSchema, schema map[string]interface{}, obj map[string]interface{}) error {
+	for _, field := range compiled.requiredIn(schema) {
+		if _, exists := obj[field]; !exists {
+			return fmt.Errorf("missing required field: %s", field)
+		}
+	}
+
+	if field, found := unexpectedField(schema, obj); found {
+		return fmt.Errorf("unexpected field: %s", field)
+	}
+
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for name, value := range obj {
+		propSchema, ok := props[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := validateType(compiled.property(name), propSchema, value); err != nil {
+			if isSensitiveSchema(propSchema) {
+				err = errRedactedValue
+			}
+			return prefixPath(name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateArray(compiled *compiledSchema, schema map[string]interface{}, items []interface{}) error {
+	if minItems, ok := schemaNumber(schema, "minItems"); ok && float64(len(items)) < minItems {
+		return fmt.Errorf("array length %d is less than minItems %v", len(items), minItems)
+	}
+	if maxItems, ok := schemaNumber(schema, "maxItems"); ok && float64(len(items)) > maxItems {
+		return fmt.Errorf("array length %d is greater than maxItems %v", len(items), maxItems)
+	}
+
+	itemSchema, ok := schema["items"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for i, item := range items {
+		if err := validateType(compiled.item(), itemSchema, item); err != nil {
+			return prefixPath(fmt.Sprintf("[%d]", i), err)
+		}
+	}
+
+	return nil
+}
+
+// unexpectedField returns the first key of obj, in sorted order, that is not
+// among the schema's properties when the schema sets additionalProperties to
+// false. Any other additionalProperties value allows extra keys.
+func unexpectedField(schema map[string]interface{}, obj map[string]interface{}) (string, bool) {
+	if allowed, ok := schema["additionalProperties"].(bool); !ok || allowed {
+		return "", false
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+
+	var unexpected []string
+	for name := range obj {
+		if _, declared := props[name]; !declared {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if len(unexpected) == 0 {
+		return "", false
+	}
+
+	sort.Strings(unexpected)
+	return unexpected[0], true
+}
+
+// requiredFields reads the schema's "required" list. Schemas written in Go use
+// []string, while schemas decoded from JSON arrive as []interface{}.
+func requiredFields(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		fields := make([]string, 0, len(required))
+		for _, field := range required {
+			if name, ok := field.(string); ok {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	}
+	return nil
+}
+
+func ValidateType(schema map[string]interface{}, value interface{}) error {
+	return validateType(nil, schema, value)
+}
+
+// validateType is ValidateType reading what it can from compiled, the
+// compiled form of schema, which may be nil.
+func validateType(compiled *compiledSchema, schema map[string]interface{}, value interface{}) error {
+	expectedType, ok := schema["type"].(string)
+	if !ok {
+		return fmt.Errorf("schema missing type")
+	}
+
+	switch expectedType {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		if err := validateStringConstraints(compiled, schema, str); err != nil {
+			return err
+		}
+	case "number":
+		if _, ok := toFloat64(value); !ok {
+			return fmt.Errorf("expected number, got %T", value)
+		}
+		if err := validateNumberConstraints(schema, value); err != nil {
+			return err
+		}
+	case "integer":
+		if !isInteger(value) {
+			return fmt.Errorf("expected integer, got %T", value)
+		}
+		if err := validateNumberConstraints(schema, value); err != nil {
+			return err
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected boolean, got %T", value)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected array, got %T", value)
+		}
+		if err := validateArray(compiled, schema, items); err != nil {
+			return err
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %T", value)
+		}
+		if err := validateObject(compiled, schema, obj); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported type: %s", expectedType)
+	}
+
+	if enum, ok := compiled.enumIn(schema); ok {
+		if err := validateEnum(enum, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float64:
+		return v == math.Trunc(v) && !math.IsInf(v, 0)
+	case float32:
+		return float64(v) == math.Trunc(float64(v)) && !math.IsInf(float64(v), 0)
+	}
+	return false
+}
+
+// toFloat64 converts any Go numeric type to float64 so that values built in
+// Go compare equal to the float64s produced by encoding/json.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func validateEnum(enum interface{}, value interface{}) error {
+	var members []interface{}
+	switch e := enum.(type) {
+	case []interface{}:
+		members = e
+	case []string:
+		for _, member := range e {
+			members = append(members, member)
+		}
+	default:
+		return fmt.Errorf("invalid enum in schema: %T", enum)
+	}
+
+	for _, member := range members {
+		if enumEqual(member, value) {
+			return nil
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		return fmt.Errorf("value %q not in enum %v", str, members)
+	}
+	return fmt.Errorf("value %v not in enum %v", value, members)
+}
+
+func enumEqual(member, value interface{}) bool {
+	if a, ok := toFloat64(member); ok {
+		b, ok := toFloat64(value)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(member, value)
+}
+
+// schemaNumber reads a numeric keyword, accepting any Go numeric type.
+func schemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	raw, exists := schema[keyword]
+	if !exists {
+		return 0, false
+	}
+	return toFloat64(raw)
+}
+
+func validateNumberConstraints(schema map[string]interface{}, value interface{}) error {
+	n, _ := toFloat64(value)
+
+	// Draft 4 spells exclusive bounds as booleans modifying minimum/maximum;
+	// later drafts make them numbers of their own.
+	exclusiveMin, _ := schema["exclusiveMinimum"].(bool)
+	exclusiveMax, _ := schema["exclusiveMaximum"].(bool)
+
+	if minimum, ok := schemaNumber(schema, "minimum"); ok {
+		if exclusiveMin && n <= minimum {
+			return fmt.Errorf("value %v must be greater than exclusive minimum %v", n, minimum)
+		}
+		if n < minimum {
+			return fmt.Errorf("value %v is less than minimum %v", n, minimum)
+		}
+	}
+	if maximum, ok := schemaNumber(schema, "maximum"); ok {
+		if exclusiveMax && n >= maximum {
+			return fmt.Errorf("value %v must be less than exclusive maximum %v", n, maximum)
+		}
+		if n > maximum {
+			return fmt.Errorf("value %v is greater than maximum %v", n, maximum)
+		}
+	}
+	if minimum, ok := schemaNumber(schema, "exclusiveMinimum"); ok && n <= minimum {
+		return fmt.Errorf("value %v must be greater than exclusiveMinimum %v", n, minimum)
+	}
+	if maximum, ok := schemaNumber(schema, "exclusiveMaximum"); ok && n >= maximum {
+		return fmt.Errorf("value %v must be less than exclusiveMaximum %v", n, maximum)
+	}
+
+	return nil
+}
+
+func validateStringConstraints(compiled *compiledSchema, schema map[string]interface{}, str string) error {
+	length := utf8.RuneCountInString(str)
+
+	if minLength, ok := schemaNumber(schema, "minLength"); ok && float64(length) < minLength {
+		return fmt.Errorf("string length %d is less than minLength %v", length, minLength)
+	}
+	if maxLength, ok := schemaNumber(schema, "maxLength"); ok && float64(length) > maxLength {
+		return fmt.Errorf("string length %d is greater than maxLength %v", length, maxLength)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := compiled.patternFor(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in schema: %w", pattern, err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("value %q does not match pattern %q", str, pattern)
+		}
+	}
+
+	return nil
+}
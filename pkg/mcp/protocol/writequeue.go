@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var errQueueClosed = errors.New("transport not connected")
+
+// writeQueue hands frames to a single writer goroutine so that concurrent
+// senders never interleave partial writes. Senders blocked on the unbuffered
+// requests channel are served in the order they arrived, so frames reach the
+// peer first in, first out.
+type writeQueue struct {
+	w         io.Writer
+	requests  chan writeRequest
+	done      chan struct{}
+	closeOnce sync.Once
+	depth     atomic.Int64
+}
+
+type writeRequest struct {
+	ctx    context.Context
+	frame  []byte
+	result chan error
+}
+
+func newWriteQueue(w io.Writer) *writeQueue {
+	q := &writeQueue{
+		w:        w,
+		requests: make(chan writeRequest),
+		done:     make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *writeQueue) run() {
+	for {
+		select {
+		case req := <-q.requests:
+			// The sender may have given up while the hand-off raced with
+			// its context; such a frame must not reach the peer.
+			if err := req.ctx.Err(); err != nil {
+				req.result <- err
+				continue
+			}
+			_, err := q.w.Write(req.frame)
+			req.result <- err
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// write queues frame and waits until it has been written. Until the writer
+// takes the frame, write gives up with ctx.Err() once ctx is done and nothing
+// is written. A frame the writer has taken is written in full and always
+// answered, so only the hand-off watches for ctx and close.
+func (q *writeQueue) write(ctx context.Context, frame []byte) error {
+	q.depth.Add(1)
+	defer q.depth.Add(-1)
+
+	req := writeRequest{ctx: ctx, frame: frame, result: make(chan error, 1)}
+	select {
+	case q.requests <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.done:
+		return errQueueClosed
+	}
+
+	return <-req.result
+}
+
+// close stops the writer once it finishes the frame in hand. Frames still
+// waiting fail with errQueueClosed.
+func (q *writeQueue) close() {
+	q.closeOnce.Do(func() { close(q.done) })
+}
+
+// len is the number of frames waiting to be written, including the one being
+// written.
+func (q *writeQueue) len() int {
+	return int(q.depth.Load())
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyRequests is returned by CallTool, with WithRejectWhenBusy, when a
+// server already has MaxConcurrency calls in flight.
+var ErrTooManyRequests = errors.New("too many concurrent requests")
+
+// WithRejectWhenBusy makes CallTool fail with ErrTooManyRequests instead of
+// waiting for a free slot when a server is at its MaxConcurrency.
+func WithRejectWhenBusy() ManagerOption {
+	return func(m *Manager) {
+		m.rejectWhenBusy = true
+	}
+}
+
+// callSlots returns the semaphore bounding the server's calls in flight,
+// creating it on first use, or nil if the config sets no limit.
+func (s *Server) callSlots() chan struct{} {
+	s.callsMutex.Lock()
+	defer s.callsMutex.Unlock()
+
+	if s.calls == nil && s.Config.MaxConcurrency > 0 {
+		s.calls = make(chan struct{}, s.Config.MaxConcurrency)
+	}
+	return s.calls
+}
+
+// acquireCall takes one of the server's call slots, waiting for ctx unless
+// reject is set. The returned function gives the slot back.
+func (s *Server) acquireCall(ctx context.Context, reject bool) (func(), error) {
+	slots := s.callSlots()
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	release := func() { <-slots }
+
+	if reject {
+		select {
+		case slots <- struct{}{}:
+			return release, nil
+		default:
+			return nil, fmt.Errorf("%w: server %s is at its limit of %d", ErrTooManyRequests, s.Name, cap(slots))
+		}
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
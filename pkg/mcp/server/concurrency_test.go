@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// gatedClient blocks every CallTool until release is closed, counting the
+// calls in flight.
+type gatedClient struct {
+	*MockClient
+	release  chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *gatedClient) CallTool(ctx context.Context, name string, params map[string]interface{}, opts ...protocol.CallOption) (interface{}, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	select {
+	case <-c.release:
+		return c.MockClient.CallTool(ctx, name, params, opts...)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	newManager := func(limit int, opts ...ManagerOption) (*Manager, *gatedClient) {
+		manager := NewManager(opts...)
+		client := &gatedClient{MockClient: NewMockClient(), release: make(chan struct{})}
+		server := createMockServer("limited")
+		server.Client = client
+		server.Config.MaxConcurrency = limit
+		manager.servers["limited"] = server
+		return manager, client
+	}
+
+	t.Run("enforces the limit", func(t *testing.T) {
+		manager, client := newManager(2)
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := manager.CallTool(ctx, "limited", "echo", nil); err != nil {
+					t.Errorf("CallTool failed: %v", err)
+				}
+			}()
+		}
+
+		waitFor(t, func() bool { return client.inFlight.Load() == 2 })
+		time.Sleep(20 * time.Millisecond)
+		if n := client.inFlight.Load(); n != 2 {
+			t.Fatalf("Expected 2 calls in flight, got %d", n)
+		}
+
+		close(client.release)
+		wg.Wait()
+
+		if peak := client.peak.Load(); peak != 2 {
+			t.Fatalf("Expected at most 2 calls in flight, got %d", peak)
+		}
+		if calls := manager.Metrics()["limited"].ToolCalls; calls != 6 {
+			t.Fatalf("Expected 6 recorded calls, got %d", calls)
+		}
+	})
+
+	t.Run("context cancels a queued call", func(t *testing.T) {
+		manager, client := newManager(1)
+		defer close(client.release)
+
+		go manager.CallTool(context.Background(), "limited", "echo", nil)
+		waitFor(t, func() bool { return client.inFlight.Load() == 1 })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := manager.CallTool(ctx, "limited", "echo", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected the queued call to time out, got %v", err)
+		}
+		if calls := manager.Metrics()["limited"].ToolCalls; calls != 0 {
+			t.Fatalf("Expected the queued call not to be recorded, got %d calls", calls)
+		}
+	})
+
+	t.Run("rejects calls when busy", func(t *testing.T) {
+		manager, client := newManager(1, WithRejectWhenBusy())
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := manager.CallTool(context.Background(), "limited", "echo", nil)
+			done <- err
+		}()
+		waitFor(t, func() bool { return client.inFlight.Load() == 1 })
+
+		if _, err := manager.CallTool(context.Background(), "limited", "echo", nil); !errors.Is(err, ErrTooManyRequests) {
+			t.Fatalf("Expected ErrTooManyRequests, got %v", err)
+		}
+
+		close(client.release)
+		if err := <-done; err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+
+		if _, err := manager.CallTool(context.Background(), "limited", "echo", nil); err != nil {
+			t.Fatalf("Expected a call to succeed once the slot was released, got %v", err)
+		}
+	})
+
+	t.Run("zero means no limit", func(t *testing.T) {
+		manager, client := newManager(0, WithRejectWhenBusy())
+		defer close(client.release)
+
+		for i := 0; i < 5; i++ {
+			go manager.CallTool(context.Background(), "limited", "echo", nil)
+		}
+		waitFor(t, func() bool { return client.inFlight.Load() == 5 })
+	})
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// TransportType selects how the Manager talks to a server.
+type TransportType string
+
+const (
+	// TransportStdio runs Command as a subprocess. It is the default.
+	TransportStdio TransportType = "stdio"
+	// TransportHTTP connects to URL over HTTP.
+	TransportHTTP TransportType = "http"
+	// TransportWebSocket connects to URL over a WebSocket.
+	TransportWebSocket TransportType = "ws"
+)
+
+var (
+	ErrInvalidConfig        = errors.New("invalid server config")
+	ErrUnsupportedTransport = errors.New("unsupported transport")
+)
+
+// TransportType returns c.Type, defaulting to TransportStdio.
+func (c ServerConfig) TransportType() TransportType {
+	if c.Type == "" {
+		return TransportStdio
+	}
+	return c.Type
+}
+
+// Validate checks that the fields set on c suit its transport type: a
+// subprocess config may not carry a URL or headers, and a URL config needs a
+// URL with a matching scheme and none of the stdio fields.
+// LaunchServer validates configs after expanding environment references.
+func (c ServerConfig) Validate() error {
+	switch c.TransportType() {
+	case TransportStdio:
+		if c.URL != "" || len(c.Headers) > 0 {
+			return fmt.Errorf("%w: %s: URL and Headers are not used by stdio servers", ErrInvalidConfig, c.Name)
+		}
+		return nil
+
+	case TransportHTTP, TransportWebSocket:
+		if c.Command != "" || len(c.Args) > 0 || len(c.Env) > 0 || c.ClearEnv || c.WorkDir != "" {
+			return fmt.Errorf("%w: %s: Command, Args, Env, ClearEnv and WorkDir are only used by stdio servers", ErrInvalidConfig, c.Name)
+		}
+		if c.URL == "" {
+			return fmt.Errorf("%w: %s: %s servers need a URL", ErrInvalidConfig, c.Name, c.Type)
+		}
+		return c.validateURL()
+
+	default:
+		return fmt.Errorf("%w: %s: unknown transport type %q", ErrInvalidConfig, c.Name, c.Type)
+	}
+}
+
+func (c ServerConfig) validateURL() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, c.Name, err)
+	}
+
+	schemes := map[TransportType][]string{
+		TransportHTTP:      {"http", "https"},
+		TransportWebSocket: {"ws", "wss"},
+	}[c.Type]
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s: %s servers need a URL with scheme %v, got %q", ErrInvalidConfig, c.Name, c.Type, schemes, u.Scheme)
+}
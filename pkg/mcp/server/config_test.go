@@ -0,0 +1,44 @@
+package server
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServerConfigValidate(t *testing.T) {
+	valid := map[string]ServerConfig{
+		"stdio by default":  {Name: "s", Command: "server", Args: []string{"--stdio"}, Env: map[string]string{"A": "1"}},
+		"explicit stdio":    {Name: "s", Type: TransportStdio, Command: "server"},
+		"http":              {Name: "s", Type: TransportHTTP, URL: "https://example.com/mcp", Headers: map[string]string{"X": "1"}},
+		"websocket":         {Name: "s", Type: TransportWebSocket, URL: "ws://localhost:8080"},
+		"secure websocket":  {Name: "s", Type: TransportWebSocket, URL: "wss://example.com"},
+		"plain http scheme": {Name: "s", Type: TransportHTTP, URL: "http://localhost:3000"},
+	}
+	for name, config := range valid {
+		t.Run("accepts "+name, func(t *testing.T) {
+			if err := config.Validate(); err != nil {
+				t.Fatalf("Expected config to be valid, got %v", err)
+			}
+		})
+	}
+
+	invalid := map[string]ServerConfig{
+		"URL on stdio":           {Name: "s", Command: "server", URL: "https://example.com"},
+		"headers on stdio":       {Name: "s", Command: "server", Headers: map[string]string{"X": "1"}},
+		"command on http":        {Name: "s", Type: TransportHTTP, URL: "https://example.com", Command: "server"},
+		"env on websocket":       {Name: "s", Type: TransportWebSocket, URL: "ws://example.com", Env: map[string]string{"A": "1"}},
+		"workdir on http":        {Name: "s", Type: TransportHTTP, URL: "https://example.com", WorkDir: "/tmp"},
+		"missing URL":            {Name: "s", Type: TransportHTTP},
+		"websocket URL for http": {Name: "s", Type: TransportHTTP, URL: "ws://example.com"},
+		"http URL for websocket": {Name: "s", Type: TransportWebSocket, URL: "https://example.com"},
+		"unknown transport type": {Name: "s", Type: "carrier-pigeon"},
+		"URL without any scheme": {Name: "s", Type: TransportHTTP, URL: "example.com/mcp"},
+	}
+	for name, config := range invalid {
+		t.Run("rejects "+name, func(t *testing.T) {
+			if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import "os"
+
+// expandConfig returns config with ${VAR} and $VAR references replaced in
+// Command, Args, URL and the values of Env and Headers. A name defined in
+// config.Env refers to that entry, itself expanded, so entries can build on
+// each other and on the host environment (PATH=${HOME}/bin:${PATH}); any other
+// name is looked up in the process environment. "$$" produces a literal "$".
+// Nothing is expanded when config.DisableExpansion is set.
+func expandConfig(config ServerConfig) ServerConfig {
+	if config.DisableExpansion {
+		return config
+	}
+
+	e := &expander{
+		raw:       config.Env,
+		expanded:  make(map[string]string, len(config.Env)),
+		expanding: make(map[string]bool),
+	}
+
+	expanded := config
+	expanded.Command = e.expand(config.Command)
+
+	if config.Args != nil {
+		expanded.Args = make([]string, len(config.Args))
+		for i, arg := range config.Args {
+			expanded.Args[i] = e.expand(arg)
+		}
+	}
+
+	expanded.URL = e.expand(config.URL)
+
+	if config.Headers != nil {
+		expanded.Headers = make(map[string]string, len(config.Headers))
+		for name, value := range config.Headers {
+			expanded.Headers[name] = e.expand(value)
+		}
+	}
+
+	if config.Env != nil {
+		expanded.Env = make(map[string]string, len(config.Env))
+		for name := range config.Env {
+			expanded.Env[name] = e.lookup(name)
+		}
+	}
+
+	return expanded
+}
+
+type expander struct {
+	raw       map[string]string
+	expanded  map[string]string
+	expanding map[string]bool
+}
+
+func (e *expander) expand(s string) string {
+	return os.Expand(s, e.lookup)
+}
+
+func (e *expander) lookup(name string) string {
+	if name == "$" {
+		return "$"
+	}
+
+	raw, defined := e.raw[name]
+	// A self-reference, directly or through other entries, means the
+	// inherited value: PATH=${PATH}:/extra.
+	if !defined || e.expanding[name] {
+		return os.Getenv(name)
+	}
+
+	if value, done := e.expanded[name]; done {
+		return value
+	}
+
+	e.expanding[name] = true
+	value := e.expand(raw)
+	delete(e.expanding, name)
+
+	e.expanded[name] = value
+	return value
+}
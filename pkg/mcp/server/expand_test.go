@@ -0,0 +1,76 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandConfig(t *testing.T) {
+	t.Setenv("MCP_TEST_HOME", "/home/user")
+	t.Setenv("MCP_TEST_PATH", "/usr/bin")
+
+	t.Run("expands the host environment", func(t *testing.T) {
+		config := expandConfig(ServerConfig{
+			Command: "${MCP_TEST_HOME}/bin/server",
+			Args:    []string{"--root=$MCP_TEST_HOME", "--missing=${MCP_TEST_UNSET}"},
+		})
+
+		if config.Command != "/home/user/bin/server" {
+			t.Fatalf("Unexpected command: %s", config.Command)
+		}
+		if want := []string{"--root=/home/user", "--missing="}; !reflect.DeepEqual(config.Args, want) {
+			t.Fatalf("Expected args %v, got %v", want, config.Args)
+		}
+	})
+
+	t.Run("Env entries refer to each other and to inherited values", func(t *testing.T) {
+		config := expandConfig(ServerConfig{
+			Command: "${TOOLS}/server",
+			Env: map[string]string{
+				"TOOLS":         "${MCP_TEST_HOME}/tools",
+				"MCP_TEST_PATH": "${TOOLS}/bin:${MCP_TEST_PATH}",
+			},
+		})
+
+		want := map[string]string{
+			"TOOLS":         "/home/user/tools",
+			"MCP_TEST_PATH": "/home/user/tools/bin:/usr/bin",
+		}
+		if !reflect.DeepEqual(config.Env, want) {
+			t.Fatalf("Expected env %v, got %v", want, config.Env)
+		}
+		if config.Command != "/home/user/tools/server" {
+			t.Fatalf("Unexpected command: %s", config.Command)
+		}
+	})
+
+	t.Run("$$ escapes a dollar sign", func(t *testing.T) {
+		config := expandConfig(ServerConfig{Command: "echo", Args: []string{"cost: $$5", "$${MCP_TEST_HOME}"}})
+
+		if want := []string{"cost: $5", "${MCP_TEST_HOME}"}; !reflect.DeepEqual(config.Args, want) {
+			t.Fatalf("Expected args %v, got %v", want, config.Args)
+		}
+	})
+
+	t.Run("DisableExpansion keeps values verbatim", func(t *testing.T) {
+		original := ServerConfig{
+			Command:          "${MCP_TEST_HOME}/server",
+			Args:             []string{"$MCP_TEST_HOME"},
+			Env:              map[string]string{"A": "${MCP_TEST_PATH}"},
+			DisableExpansion: true,
+		}
+
+		if config := expandConfig(original); !reflect.DeepEqual(config, original) {
+			t.Fatalf("Expected %+v, got %+v", original, config)
+		}
+	})
+
+	t.Run("does not modify the original config", func(t *testing.T) {
+		original := ServerConfig{Args: []string{"$MCP_TEST_HOME"}, Env: map[string]string{"A": "$MCP_TEST_HOME"}}
+		expandConfig(original)
+
+		if original.Args[0] != "$MCP_TEST_HOME" || original.Env["A"] != "$MCP_TEST_HOME" {
+			t.Fatalf("Original config was modified: %+v", original)
+		}
+	})
+}
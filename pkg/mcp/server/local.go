@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go-mcp/pkg/mcp/protocol"
+	"go-mcp/pkg/mcp/tool"
+)
+
+// RequestHandler answers a single JSON-RPC method. Returning a
+// *protocol.JSONRPCError sends that error to the client verbatim; any other
+// error becomes an internal error.
+type RequestHandler func(ctx context.Context, params map[string]interface{}) (interface{}, error)
+
+// LocalServer is an MCP server implemented in Go. It answers initialize, ping,
+// tools/list, tools/call and resources/list itself, with tools backed by a
+// tool.Registry, and dispatches any other method to handlers added with Handle.
+type LocalServer struct {
+	info      protocol.Implementation
+	registry  *tool.Registry
+	resources []protocol.Resource
+	handlers  map[string]RequestHandler
+	mutex     sync.RWMutex
+}
+
+func NewLocalServer(info protocol.Implementation, registry *tool.Registry) *LocalServer {
+	if registry == nil {
+		registry = tool.NewRegistry()
+	}
+
+	return &LocalServer{
+		info:     info,
+		registry: registry,
+		handlers: make(map[string]RequestHandler),
+	}
+}
+
+func (s *LocalServer) Registry() *tool.Registry {
+	return s.registry
+}
+
+func (s *LocalServer) AddResource(resource protocol.Resource) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.resources = append(s.resources, resource)
+}
+
+// Handle registers handler for method, replacing any earlier handler,
+// including the built-in ones.
+func (s *LocalServer) Handle(method string, handler RequestHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Serve answers requests from transport until it fails or ctx is cancelled.
+// Each request is handled on its own goroutine. Cancelling ctx closes the
+// transport.
+func (s *LocalServer) Serve(ctx context.Context, transport protocol.ServerTransport) error {
+	requests := make(chan *protocol.JSONRPCRequest)
+	readErr := make(chan error, 1)
+
+	go func() {
+		for {
+			request, err := transport.ReceiveRequest()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case requests <- request:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			transport.Close()
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case request := <-requests:
+			// Notifications (initialized, cancelled, ...) need no answer.
+			if request.ID.IsZero() {
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				transport.SendResponse(s.handleRequest(ctx, request))
+			}()
+		}
+	}
+}
+
+func (s *LocalServer) handleRequest(ctx context.Context, request *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	s.mutex.RLock()
+	handler, exists := s.handlers[request.Method]
+	s.mutex.RUnlock()
+
+	if !exists {
+		handler, exists = s.builtinHandler(request.Method)
+	}
+
+	if !exists {
+		return protocol.NewErrorResponse(request.ID, protocol.ErrMethodNotFound,
+			fmt.Sprintf("method not found: %s", request.Method), nil)
+	}
+
+	result, err := handler(ctx, request.Params)
+	if err != nil {
+		var rpcErr *protocol.JSONRPCError
+		if errors.As(err, &rpcErr) {
+			return protocol.NewErrorResponse(request.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+		}
+		return protocol.NewErrorResponse(request.ID, protocol.ErrInternalError, err.Error(), nil)
+	}
+
+	return protocol.NewResponse(request.ID, result)
+}
+
+func (s *LocalServer) builtinHandler(method string) (RequestHandler, bool) {
+	switch method {
+	case "initialize":
+		return s.handleInitialize, true
+	case "ping":
+		return func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{}, nil
+		}, true
+	case "tools/list":
+		return s.handleListTools, true
+	case "tools/call":
+		return s.handleCallTool, true
+	case "resources/list":
+		return s.handleListResources, true
+	}
+	return nil, false
+}
+
+func (s *LocalServer) handleInitialize(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	// Answer with the client's version when we support it, otherwise offer
+	// our latest and let the client decide.
+	version := protocol.LatestProtocolVersion
+	if requested, ok := params["protocolVersion"].(string); ok {
+		for _, supported := range protocol.SupportedProtocolVersions {
+			if requested == supported {
+				version = requested
+			}
+		}
+	}
+
+	s.mutex.RLock()
+	hasResources := len(s.resources) > 0
+	s.mutex.RUnlock()
+
+	capabilities := protocol.ServerCapabilities{
+		Tools: &protocol.ToolsCapability{},
+	}
+	if hasResources {
+		capabilities.Resources = &protocol.ResourcesCapability{}
+	}
+
+	return protocol.InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    capabilities,
+		ServerInfo:      s.info,
+	}, nil
+}
+
+func (s *LocalServer) handleListTools(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	tools := s.registry.ListTools()
+
+	list := make([]protocol.Tool, 0, len(tools))
+	for _, t := range tools {
+		list = append(list, *t)
+	}
+
+	return protocol.ListToolsResponse{Tools: list}, nil
+}
+
+func (s *LocalServer) handleCallTool(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	name, _ := params["name"].(string)
+	if name == "" {
+		return nil, &protocol.JSONRPCError{Code: protocol.ErrInvalidParams, Message: "missing tool name"}
+	}
+
+	if _, exists := s.registry.GetTool(name); !exists {
+		return nil, &protocol.JSONRPCError{Code: protocol.ErrInvalidParams, Message: fmt.Sprintf("unknown tool: %s", name)}
+	}
+
+	args, _ := params["arguments"].(map[string]interface{})
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	// Failures of the tool itself are reported in the result, as the spec
+	// asks, so the model can see them.
+	result, err := s.registry.ExecuteTool(&protocol.ToolCall{Name: name, Arguments: args})
+	if err != nil {
+		return &protocol.CallToolResult{
+			Content: []protocol.Content{
+				protocol.TextContent{Type: string(protocol.ContentTypeText), Text: err.Error()},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return result, nil
+}
+
+func (s *LocalServer) handleListResources(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return protocol.ListResourcesResponse{
+		Resources: append([]protocol.Resource{}, s.resources...),
+	}, nil
+}
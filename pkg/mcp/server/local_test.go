@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+func startLocalServer(t *testing.T, srv *LocalServer) *protocol.InMemoryTransport {
+	t.Helper()
+
+	clientEnd, serverEnd := protocol.NewInMemoryPair()
+	if err := clientEnd.Start(); err != nil {
+		t.Fatalf("Failed to start client end: %v", err)
+	}
+	if err := serverEnd.Start(); err != nil {
+		t.Fatalf("Failed to start server end: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		srv.Serve(ctx, serverEnd)
+		close(done)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return clientEnd
+}
+
+func roundTrip(t *testing.T, transport *protocol.InMemoryTransport, method string, params map[string]interface{}) *protocol.JSONRPCResponse {
+	t.Helper()
+
+	id := protocol.StringID(fmt.Sprintf("%s-%d", method, time.Now().UnixNano()))
+	if err := transport.Send(protocol.NewRequest(id, method, params)); err != nil {
+		t.Fatalf("Failed to send %s: %v", method, err)
+	}
+
+	response, err := transport.Receive()
+	if err != nil {
+		t.Fatalf("Failed to receive %s response: %v", method, err)
+	}
+	if response.ID != id {
+		t.Fatalf("Expected response ID %s, got %s", id, response.ID)
+	}
+
+	return response
+}
+
+func TestLocalServer(t *testing.T) {
+	srv := NewLocalServer(protocol.Implementation{Name: "local", Version: "1.0"}, nil)
+	err := srv.Registry().RegisterTool(&protocol.Tool{
+		Name: "echo",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"text"},
+		},
+		Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+			return &protocol.CallToolResult{
+				Content: []protocol.Content{
+					protocol.TextContent{Type: string(protocol.ContentTypeText), Text: args["text"].(string)},
+				},
+			}, nil
+		},
+	}, "local")
+	if err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	err = srv.Registry().RegisterTool(&protocol.Tool{
+		Name: "ping",
+		Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+			return &protocol.CallToolResult{}, nil
+		},
+	}, "local")
+	if err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	transport := startLocalServer(t, srv)
+
+	t.Run("initialize", func(t *testing.T) {
+		response := roundTrip(t, transport, "initialize", map[string]interface{}{
+			"protocolVersion": "2024-11-05",
+			"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0"},
+		})
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %v", response.Error)
+		}
+
+		result := response.Result.(map[string]interface{})
+		if result["protocolVersion"] != "2024-11-05" {
+			t.Fatalf("Expected negotiated version 2024-11-05, got %v", result["protocolVersion"])
+		}
+		if _, ok := result["capabilities"].(map[string]interface{})["tools"]; !ok {
+			t.Fatal("Expected tools capability")
+		}
+
+		// The initialized notification must not get a reply.
+		if err := transport.Send(protocol.NewNotification("notifications/initialized", nil)); err != nil {
+			t.Fatalf("Failed to send notification: %v", err)
+		}
+	})
+
+	t.Run("tools/list", func(t *testing.T) {
+		response := roundTrip(t, transport, "tools/list", nil)
+
+		tools := response.Result.(map[string]interface{})["tools"].([]interface{})
+		if len(tools) != 2 {
+			t.Fatalf("Expected 2 tools, got %d", len(tools))
+		}
+		if name := tools[0].(map[string]interface{})["name"]; name != "echo" {
+			t.Fatalf("Expected tool echo, got %v", name)
+		}
+
+		// A tool registered without a schema still advertises an object one.
+		ping := tools[1].(map[string]interface{})
+		if schema, ok := ping["inputSchema"].(map[string]interface{}); !ok || schema["type"] != "object" {
+			t.Fatalf("Expected ping to advertise an object schema, got %v", ping["inputSchema"])
+		}
+	})
+
+	t.Run("tools/call", func(t *testing.T) {
+		response := roundTrip(t, transport, "tools/call", map[string]interface{}{
+			"name":      "echo",
+			"arguments": map[string]interface{}{"text": "hello"},
+		})
+
+		result, err := protocol.DecodeCallToolResult(response.Result)
+		if err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		if result.IsError {
+			t.Fatal("Expected a successful result")
+		}
+		if text := result.Content[0].(protocol.TextContent).Text; text != "hello" {
+			t.Fatalf("Expected echoed text, got %q", text)
+		}
+	})
+
+	t.Run("tools/call reports invalid arguments as a tool error", func(t *testing.T) {
+		response := roundTrip(t, transport, "tools/call", map[string]interface{}{
+			"name":      "echo",
+			"arguments": map[string]interface{}{},
+		})
+
+		result, err := protocol.DecodeCallToolResult(response.Result)
+		if err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		if !result.IsError {
+			t.Fatal("Expected an error result")
+		}
+	})
+
+	t.Run("tools/call with an unknown tool", func(t *testing.T) {
+		response := roundTrip(t, transport, "tools/call", map[string]interface{}{"name": "missing"})
+
+		if response.Error == nil || response.Error.Code != protocol.ErrInvalidParams {
+			t.Fatalf("Expected invalid params error, got %+v", response.Error)
+		}
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		response := roundTrip(t, transport, "nope", nil)
+
+		if response.Error == nil || response.Error.Code != protocol.ErrMethodNotFound {
+			t.Fatalf("Expected method not found error, got %+v", response.Error)
+		}
+	})
+
+	t.Run("echoes numeric request IDs", func(t *testing.T) {
+		if err := transport.Send(protocol.NewRequest(protocol.NumberID(42), "ping", nil)); err != nil {
+			t.Fatalf("Failed to send ping: %v", err)
+		}
+
+		response, err := transport.Receive()
+		if err != nil {
+			t.Fatalf("Failed to receive ping response: %v", err)
+		}
+		if response.ID != protocol.NumberID(42) {
+			t.Fatalf("Expected numeric ID 42, got %v (numeric: %v)", response.ID, response.ID.IsNumber())
+		}
+	})
+
+	t.Run("answers an empty string request ID", func(t *testing.T) {
+		if err := transport.Send(protocol.NewRequest(protocol.StringID(""), "ping", nil)); err != nil {
+			t.Fatalf("Failed to send ping: %v", err)
+		}
+
+		response, err := transport.Receive()
+		if err != nil {
+			t.Fatalf("Failed to receive ping response: %v", err)
+		}
+		if response.ID != protocol.StringID("") {
+			t.Fatalf("Expected empty string ID, got %v (zero: %v)", response.ID, response.ID.IsZero())
+		}
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		srv.Handle("custom/echo", func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+			return params, nil
+		})
+
+		response := roundTrip(t, transport, "custom/echo", map[string]interface{}{"x": "y"})
+		if response.Result.(map[string]interface{})["x"] != "y" {
+			t.Fatalf("Unexpected result: %v", response.Result)
+		}
+	})
+}
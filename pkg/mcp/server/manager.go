@@ -4,31 +4,107 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"go-mcp/pkg/mcp/protocol"
 )
 
-var transportFactory = func(cmdStr string) protocol.Transport {
-	return protocol.NewStdioTransport(cmdStr)
+// DefaultSupervisionInterval is how often the supervisor checks that a
+// launched server is still connected.
+const DefaultSupervisionInterval = time.Second
+
+// TransportFactory creates the transport for a server about to be launched.
+// It receives the config validated and with environment references already
+// expanded; the transport is started by the Manager.
+type TransportFactory func(config ServerConfig) (protocol.Transport, error)
+
+// DefaultTransportFactory picks the transport for config.Type. Only stdio is
+// built in; HTTP and WebSocket servers need a factory supplied with
+// WithTransportFactory.
+func DefaultTransportFactory(config ServerConfig) (protocol.Transport, error) {
+	if config.TransportType() != TransportStdio {
+		return nil, fmt.Errorf("%w: %s (server %s)", ErrUnsupportedTransport, config.Type, config.Name)
+	}
+	return StdioTransportFactory(config)
+}
+
+// StdioTransportFactory runs config.Command as a subprocess speaking JSON-RPC
+// over stdin and stdout.
+func StdioTransportFactory(config ServerConfig) (protocol.Transport, error) {
+	transport := protocol.NewStdioTransportCommand(config.Command, config.Args...)
+
+	if len(config.Env) > 0 {
+		transport.SetEnv(config.Env)
+	}
+
+	if config.ClearEnv {
+		transport.SetInheritEnv(false)
+	}
+
+	if config.WorkDir != "" {
+		transport.SetWorkDir(config.WorkDir)
+	}
+
+	return transport, nil
 }
 
 var (
 	ErrServerNotFound = errors.New("server not found")
 	ErrServerExists   = errors.New("server already exists")
 	ErrNoServers      = errors.New("no servers available")
+
+	// ErrLaunchAborted is returned by LaunchServer when the server is shut
+	// down before its launch completes.
+	ErrLaunchAborted = errors.New("server shut down while launching")
 )
 
 type ServerConfig struct {
 	Name string
 
+	// Type selects the transport; empty means TransportStdio. Command, Args,
+	// Env, ClearEnv and WorkDir apply to stdio servers only, URL and Headers
+	// to the others.
+	Type TransportType
+
 	Command string
 
 	Args []string
 
 	Env map[string]string
 
+	// ClearEnv starts the server with only the variables in Env instead of
+	// adding them to the manager's own environment.
+	ClearEnv bool
+
 	WorkDir string
+
+	URL string
+
+	Headers map[string]string
+
+	// LaunchTimeout bounds how long a launch, or a restart, may take to
+	// complete the handshake and list tools. Zero leaves only the context
+	// given to LaunchServer and the client's default connect timeout.
+	LaunchTimeout time.Duration
+
+	// DisableExpansion passes Command, Args, Env, URL and Headers through
+	// verbatim instead of expanding ${VAR} references in them. With expansion
+	// on, write "$$" for a literal "$".
+	DisableExpansion bool
+
+	// MaxConcurrency caps the tool calls Manager.CallTool has in flight on
+	// the server at once. Further calls wait for a free slot, or fail with
+	// ErrTooManyRequests if the manager was created WithRejectWhenBusy. Zero
+	// or less means no limit.
+	MaxConcurrency int
+
+	// ReadyCheck, when set, makes LaunchServer wait after the handshake until
+	// the server passes it, for servers that need time to warm up. It counts
+	// towards LaunchTimeout.
+	ReadyCheck *ReadyCheck
 }
 
 type Server struct {
@@ -36,13 +112,41 @@ type Server struct {
 
 	Client protocol.MCPClient
 
-	Tools []protocol.Tool
+	// Tools is the list of tools as last discovered. DiscoverTools replaces
+	// it while other goroutines may be reading it, so once the server is
+	// managed read it with GetTools.
+	Tools      []protocol.Tool
+	toolsMutex sync.RWMutex
 
 	Capabilities *protocol.ServerCapabilities
 
 	Transport protocol.Transport
 
 	Config ServerConfig
+
+	// dropped is signalled when a StateAware transport reports losing its
+	// connection, so the supervisor need not wait for its next check.
+	dropped chan struct{}
+
+	// calls holds one token per tool call in flight when Config sets a
+	// MaxConcurrency.
+	calls      chan struct{}
+	callsMutex sync.Mutex
+}
+
+// GetTools returns a copy of the server's tools.
+func (s *Server) GetTools() []protocol.Tool {
+	s.toolsMutex.RLock()
+	defer s.toolsMutex.RUnlock()
+
+	return append([]protocol.Tool(nil), s.Tools...)
+}
+
+func (s *Server) setTools(tools []protocol.Tool) {
+	s.toolsMutex.Lock()
+	defer s.toolsMutex.Unlock()
+
+	s.Tools = tools
 }
 
 func (s *Server) IsRunning() bool {
@@ -50,63 +154,203 @@ func (s *Server) IsRunning() bool {
 }
 
 type Manager struct {
-	servers map[string]*Server
-	mutex   sync.RWMutex
+	servers     map[string]*Server
+	supervisors map[string]chan struct{}
+
+	// launching reserves the names of servers LaunchServer is connecting,
+	// which happens without m.mutex held.
+	launching map[string]*launch
+
+	maxRetries    int
+	backoff       time.Duration
+	checkInterval time.Duration
+
+	healthCallbacks []HealthChangeFunc
+
+	transportFactory TransportFactory
+
+	shutdownTimeout time.Duration
+
+	rejectWhenBusy bool
+
+	metrics      map[string]*serverMetrics
+	metricsMutex sync.Mutex
+	recorder     MetricsRecorder
+
+	logger protocol.Logger
+
+	mutex sync.RWMutex
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		servers: make(map[string]*Server),
+// ManagerOption configures a Manager created by NewManager.
+type ManagerOption func(*Manager)
+
+// WithLogger routes the manager's diagnostics, and those of the clients it
+// creates, to logger.
+func WithLogger(logger protocol.Logger) ManagerOption {
+	return func(m *Manager) {
+		if logger != nil {
+			m.logger = logger
+		}
 	}
 }
 
-func (m *Manager) LaunchServer(ctx context.Context, config ServerConfig) (*Server, error) {
+// WithTransportFactory makes the manager launch servers through factory
+// instead of DefaultTransportFactory, for example to pick an HTTP or WebSocket
+// transport based on the config.
+func WithTransportFactory(factory TransportFactory) ManagerOption {
+	return func(m *Manager) {
+		if factory != nil {
+			m.transportFactory = factory
+		}
+	}
+}
+
+// WithShutdownTimeout bounds how long ShutdownAll waits for any one server to
+// disconnect. By default only the context passed to ShutdownAll applies.
+func WithShutdownTimeout(timeout time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.shutdownTimeout = timeout
+	}
+}
+
+// HealthChangeFunc is called by the health monitor when a server becomes
+// healthy or unhealthy.
+type HealthChangeFunc func(serverName string, healthy bool)
+
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		servers:          make(map[string]*Server),
+		supervisors:      make(map[string]chan struct{}),
+		launching:        make(map[string]*launch),
+		metrics:          make(map[string]*serverMetrics),
+		checkInterval:    DefaultSupervisionInterval,
+		logger:           protocol.NopLogger{},
+		transportFactory: DefaultTransportFactory,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// SetRestartPolicy makes the Manager relaunch servers whose connection drops.
+// Each crash gets up to maxRetries attempts, waiting backoff before the first
+// and doubling the wait after every failure. A maxRetries of zero, the
+// default, disables restarts.
+func (m *Manager) SetRestartPolicy(maxRetries int, backoff time.Duration) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, exists := m.servers[config.Name]; exists {
+	m.maxRetries = maxRetries
+	m.backoff = backoff
+}
+
+// launch is the reservation of a server name while LaunchServer connects.
+type launch struct {
+	cancel context.CancelFunc
+}
+
+// LaunchServer starts the server described by config and manages it under
+// config.Name. Connecting, and waiting for a ReadyCheck, does not hold up
+// other calls on the manager; a shutdown meanwhile aborts the launch with
+// ErrLaunchAborted.
+func (m *Manager) LaunchServer(ctx context.Context, config ServerConfig) (*Server, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reservation := &launch{cancel: cancel}
+
+	m.mutex.Lock()
+	_, exists := m.servers[config.Name]
+	_, launching := m.launching[config.Name]
+	if exists || launching {
+		m.mutex.Unlock()
 		return nil, fmt.Errorf("%w: %s", ErrServerExists, config.Name)
 	}
+	m.launching[config.Name] = reservation
+	m.mutex.Unlock()
+
+	server, err := m.connectServer(ctx, config)
 
-	cmdStr := config.Command
-	for _, arg := range config.Args {
-		cmdStr += " " + arg
+	m.mutex.Lock()
+	if m.launching[config.Name] != reservation {
+		m.mutex.Unlock()
+		if server != nil {
+			server.Client.Disconnect()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrLaunchAborted, config.Name)
 	}
+	delete(m.launching, config.Name)
+	defer m.mutex.Unlock()
 
-	transport := transportFactory(cmdStr)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(config.Env) > 0 {
-		if t, ok := transport.(*protocol.StdioTransport); ok {
-			t.SetEnv(config.Env)
-		}
+	m.servers[config.Name] = server
+
+	stop := make(chan struct{})
+	m.supervisors[config.Name] = stop
+	go m.supervise(config.Name, stop)
+
+	return server, nil
+}
+
+// connectServer starts the server process described by config, performs the
+// MCP handshake and lists its tools. Environment references in config are
+// expanded on every launch, so a restarted server sees the environment as it
+// is then.
+func (m *Manager) connectServer(ctx context.Context, config ServerConfig) (*Server, error) {
+	if config.LaunchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.LaunchTimeout)
+		defer cancel()
 	}
 
-	// Set working directory if provided
-	// Note: If StdioTransport doesn't have SetWorkDir, we can handle this
-	// through environment variables or by modifying the transport interface later
-	if config.WorkDir != "" {
-		// Working directory will be handled by the transport
-		// implementation if supported
+	expanded := expandConfig(config)
+
+	if err := expanded.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Start the transport
-	if err := transport.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start transport: %w", err)
+	transport, err := m.transportFactory(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make(chan struct{}, 1)
+	if t, ok := transport.(protocol.StateAware); ok {
+		t.OnDisconnect(func(err error) {
+			m.logger.Warn("server connection lost", "server", config.Name, "error", err)
+			select {
+			case dropped <- struct{}{}:
+			default:
+			}
+		})
 	}
 
 	// Create client
 	client := protocol.NewClient(protocol.ClientInfo{
 		Name:    "go-mcp",
 		Version: "0.1.0",
-	})
+	}, protocol.WithLogger(m.logger))
 
-	// Connect to the server with the transport
-	if err := client.Connect(transport); err != nil {
+	// Connect starts the transport and performs the handshake
+	if err := client.ConnectWithContext(ctx, transport); err != nil {
 		// Clean up on connect failure
 		transport.Close()
 		return nil, fmt.Errorf("failed to connect to server: %w", err)
 	}
 
+	if config.ReadyCheck != nil {
+		if err := waitReady(ctx, client, *config.ReadyCheck); err != nil {
+			client.Disconnect()
+			return nil, fmt.Errorf("%w: %s: %w", ErrNotReady, config.Name, err)
+		}
+	}
+
 	// Create server instance
 	server := &Server{
 		Name:         config.Name,
@@ -115,23 +359,125 @@ func (m *Manager) LaunchServer(ctx context.Context, config ServerConfig) (*Serve
 		Capabilities: client.GetServerCapabilities(),
 		Transport:    transport,
 		Config:       config,
+		dropped:      dropped,
 	}
 
 	// Get tools
 	tools, err := client.ListTools(ctx)
 	if err != nil {
 		// Non-fatal error, for now we'll just set an empty tools list
-		server.Tools = []protocol.Tool{}
+		m.logger.Warn("failed to list tools", "server", config.Name, "error", err)
+		server.setTools([]protocol.Tool{})
 	} else {
-		server.Tools = tools
+		server.setTools(tools)
 	}
 
-	// Add to server map
-	m.servers[config.Name] = server
-
 	return server, nil
 }
 
+// supervise relaunches the named server when its connection drops, until stop
+// is closed by ShutdownServer or ShutdownAll. Drops reported by a StateAware
+// transport are acted on at once; others are found by the periodic check.
+func (m *Manager) supervise(name string, stop chan struct{}) {
+	m.mutex.RLock()
+	interval := m.checkInterval
+	m.mutex.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.mutex.RLock()
+		var dropped chan struct{}
+		if server, exists := m.servers[name]; exists {
+			dropped = server.dropped
+		}
+		m.mutex.RUnlock()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-dropped:
+		}
+
+		m.mutex.RLock()
+		server, exists := m.servers[name]
+		maxRetries, backoff := m.maxRetries, m.backoff
+		m.mutex.RUnlock()
+
+		if !exists || server.IsRunning() || maxRetries <= 0 {
+			continue
+		}
+
+		m.logger.Warn("server disconnected, restarting", "server", name)
+
+		if !m.restart(server, maxRetries, backoff, stop) {
+			return
+		}
+	}
+}
+
+// restart replaces a dead server with a freshly launched one. It reports
+// whether supervision should continue.
+func (m *Manager) restart(dead *Server, maxRetries int, backoff time.Duration, stop chan struct{}) bool {
+	wait := backoff
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(wait):
+		}
+		wait *= 2
+
+		server, err := m.connectServer(context.Background(), dead.Config)
+		if err != nil {
+			m.logger.Warn("restart attempt failed", "server", dead.Name, "attempt", attempt+1, "error", err)
+			continue
+		}
+
+		m.mutex.Lock()
+		// A shutdown may have happened while the server was relaunching.
+		if current, exists := m.servers[dead.Name]; !exists || current != dead || m.supervisors[dead.Name] != stop {
+			m.mutex.Unlock()
+			server.Client.Disconnect()
+			return false
+		}
+		m.servers[dead.Name] = server
+		m.mutex.Unlock()
+
+		if dead.Client != nil {
+			dead.Client.Disconnect()
+		}
+
+		m.logger.Info("server restarted", "server", dead.Name)
+		return true
+	}
+
+	m.logger.Error("giving up restarting server", "server", dead.Name, "attempts", maxRetries)
+	return false
+}
+
+// abortLaunch cancels the launch of the named server, if one is under way. It
+// must be called with m.mutex held.
+func (m *Manager) abortLaunch(name string) bool {
+	reservation, exists := m.launching[name]
+	if exists {
+		reservation.cancel()
+		delete(m.launching, name)
+	}
+	return exists
+}
+
+// stopSupervisor must be called with m.mutex held.
+func (m *Manager) stopSupervisor(name string) {
+	if stop, exists := m.supervisors[name]; exists {
+		close(stop)
+		delete(m.supervisors, name)
+	}
+}
+
 func (m *Manager) GetServer(name string) (*Server, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -144,15 +490,22 @@ func (m *Manager) GetServer(name string) (*Server, error) {
 	return server, nil
 }
 
+// ShutdownServer disconnects the named server and stops managing it. A server
+// still launching has its launch aborted instead.
 func (m *Manager) ShutdownServer(ctx context.Context, name string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	server, exists := m.servers[name]
 	if !exists {
+		if m.abortLaunch(name) {
+			return nil
+		}
 		return fmt.Errorf("%w: %s", ErrServerNotFound, name)
 	}
 
+	m.stopSupervisor(name)
+
 	if server.Client != nil {
 		if err := server.Client.Disconnect(); err != nil {
 			return fmt.Errorf("failed to disconnect from server: %w", err)
@@ -160,29 +513,144 @@ func (m *Manager) ShutdownServer(ctx context.Context, name string) error {
 	}
 
 	delete(m.servers, name)
+	m.resetMetrics(name)
 
 	return nil
 }
 
+// ServerError is the failure of one server.
+type ServerError struct {
+	Server string
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server %s: %v", e.Server, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError lists, by server name, every server that did not shut down
+// cleanly. errors.Is and errors.As see through it to each server's error.
+type ShutdownError struct {
+	Failures []*ServerError
+}
+
+func (e *ShutdownError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		messages[i] = failure.Error()
+	}
+	return fmt.Sprintf("failed to shut down %d server(s): %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure
+	}
+	return errs
+}
+
+// Servers returns the names of the servers that failed.
+func (e *ShutdownError) Servers() []string {
+	names := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		names[i] = failure.Server
+	}
+	return names
+}
+
+// ShutdownAll disconnects every server concurrently. If any fail, it returns a
+// *ShutdownError naming each of them. The servers are removed at once, so lookups do not
+// wait for the shutdown. When ctx is done first, the servers still
+// disconnecting are reported with ctx.Err() and left to finish in the
+// background. Launches still under way are aborted.
 func (m *Manager) ShutdownAll(ctx context.Context) error {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	servers := m.servers
+	m.servers = make(map[string]*Server)
+	for name := range servers {
+		m.stopSupervisor(name)
+		m.resetMetrics(name)
+	}
+	for name := range m.launching {
+		m.abortLaunch(name)
+	}
+	timeout := m.shutdownTimeout
+	m.mutex.Unlock()
 
-	var lastErr error
-	for name, server := range m.servers {
-		if server.Client != nil {
-			if err := server.Client.Disconnect(); err != nil {
-				lastErr = fmt.Errorf("failed to disconnect from server %s: %w", name, err)
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(servers))
+
+	for name, server := range servers {
+		go func() {
+			results <- result{name, disconnectServer(server, timeout)}
+		}()
+	}
+
+	pending := make(map[string]bool, len(servers))
+	for name := range servers {
+		pending[name] = true
+	}
+
+	var failures []*ServerError
+wait:
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				failures = append(failures, &ServerError{Server: r.name, Err: r.err})
+			}
+		case <-ctx.Done():
+			for name := range pending {
+				failures = append(failures, &ServerError{Server: name, Err: ctx.Err()})
 			}
+			break wait
 		}
 	}
 
-	// Clear the map
-	m.servers = make(map[string]*Server)
+	if len(failures) == 0 {
+		return nil
+	}
 
-	return lastErr
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Server < failures[j].Server
+	})
+	return &ShutdownError{Failures: failures}
 }
 
+// disconnectServer disconnects server, giving up after timeout if it is
+// positive.
+func disconnectServer(server *Server, timeout time.Duration) error {
+	if server.Client == nil {
+		return nil
+	}
+
+	if timeout <= 0 {
+		return server.Client.Disconnect()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Client.Disconnect()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("disconnect timed out after %v", timeout)
+	}
+}
+
+// ListServers returns the names of the managed servers in sorted order.
 func (m *Manager) ListServers() []string {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -191,59 +659,200 @@ func (m *Manager) ListServers() []string {
 	for name := range m.servers {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 
 	return names
 }
 
+// discoveryWorkers bounds how many servers DiscoverTools queries at once.
+const discoveryWorkers = 8
+
+// DiscoverTools lists the tools of every running server concurrently. Servers
+// that fail to answer are left out of the result; a cancelled ctx aborts the
+// discovery with ctx.Err().
 func (m *Manager) DiscoverTools(ctx context.Context) (map[string][]protocol.Tool, error) {
+	// Snapshot the servers so the lock is not held across network calls.
 	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	servers := make([]*Server, 0, len(m.servers))
+	for _, server := range m.servers {
+		servers = append(servers, server)
+	}
+	m.mutex.RUnlock()
 
-	if len(m.servers) == 0 {
+	if len(servers) == 0 {
 		return nil, ErrNoServers
 	}
 
 	tools := make(map[string][]protocol.Tool)
+	var toolsMutex sync.Mutex
 
-	for name, server := range m.servers {
-		if !server.IsRunning() {
-			continue
-		}
+	jobs := make(chan *Server)
+	var wg sync.WaitGroup
 
-		// Try to get tools from server
-		serverTools, err := server.Client.ListTools(ctx)
-		if err != nil {
-			continue // Skip servers that fail to list tools
+	workers := discoveryWorkers
+	if len(servers) < workers {
+		workers = len(servers)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for server := range jobs {
+				if !server.IsRunning() {
+					continue
+				}
+
+				// Try to get tools from server
+				serverTools, err := server.Client.ListTools(ctx)
+				if err != nil {
+					m.logger.Debug("skipping server in tool discovery", "server", server.Name, "error", err)
+					continue
+				}
+
+				toolsMutex.Lock()
+				tools[server.Name] = serverTools
+				toolsMutex.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, server := range servers {
+		select {
+		case jobs <- server:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		server.Tools = serverTools
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		// Add tools to map
-		tools[name] = serverTools
+	// Only update servers that are still the ones registered under their name.
+	m.mutex.Lock()
+	for _, server := range servers {
+		serverTools, found := tools[server.Name]
+		if found && m.servers[server.Name] == server {
+			server.setTools(serverTools)
+		}
 	}
+	m.mutex.Unlock()
 
 	return tools, nil
 }
 
+// MonitorHealth checks every server at once and returns the outcome by name.
+// The checks run without the manager's lock, so a server that hangs delays
+// neither the other checks nor changes to the managed servers.
 func (m *Manager) MonitorHealth(ctx context.Context) map[string]error {
 	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	results := make(map[string]error)
-
+	clients := make(map[string]protocol.MCPClient, len(m.servers))
 	for name, server := range m.servers {
-		if !server.IsRunning() {
-			results[name] = errors.New("server not running")
-			continue
-		}
+		clients[name] = server.Client
+	}
+	m.mutex.RUnlock()
+
+	results := make(map[string]error, len(clients))
+	var resultsMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, client := range clients {
+		wg.Add(1)
+		go func(name string, client protocol.MCPClient) {
+			defer wg.Done()
+
+			var err error
+			if client == nil || !client.IsConnected() {
+				err = errors.New("server not running")
+			} else {
+				err = client.HealthCheck(ctx)
+			}
+
+			m.recordHealthCheck(name, err)
 
-		// Check server health
-		if err := server.Client.HealthCheck(ctx); err != nil {
+			resultsMutex.Lock()
 			results[name] = err
-		} else {
-			results[name] = nil
-		}
+			resultsMutex.Unlock()
+		}(name, client)
 	}
+	wg.Wait()
 
 	return results
 }
+
+// OnHealthChange registers a callback for status changes seen by the health
+// monitor. Callbacks run on the monitor goroutine.
+func (m *Manager) OnHealthChange(callback HealthChangeFunc) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.healthCallbacks = append(m.healthCallbacks, callback)
+}
+
+// StartHealthMonitor checks every server each interval until ctx is cancelled.
+// Servers are assumed healthy when first seen; callbacks fire whenever a check
+// disagrees with the last known state.
+func (m *Manager) StartHealthMonitor(ctx context.Context, interval time.Duration) {
+	go m.monitorHealth(ctx, interval)
+}
+
+func (m *Manager) monitorHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	healthy := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		results := m.MonitorHealth(checkCtx)
+		cancel()
+
+		// A cancelled context fails every check; that is not a status change.
+		if ctx.Err() != nil {
+			return
+		}
+
+		for name := range healthy {
+			if _, exists := results[name]; !exists {
+				delete(healthy, name)
+			}
+		}
+
+		m.mutex.RLock()
+		callbacks := m.healthCallbacks
+		m.mutex.RUnlock()
+
+		for name, err := range results {
+			previous, known := healthy[name]
+			if !known {
+				previous = true
+			}
+
+			current := err == nil
+			healthy[name] = current
+
+			if current != previous {
+				if current {
+					m.logger.Info("server healthy", "server", name)
+				} else {
+					m.logger.Warn("server unhealthy", "server", name, "error", err)
+				}
+
+				for _, callback := range callbacks {
+					callback(name, current)
+				}
+			}
+		}
+	}
+}
@@ -2,7 +2,10 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -58,6 +61,10 @@ func NewMockClient() *MockClient {
 }
 
 func (m *MockClient) Connect(transport protocol.Transport) error {
+	return m.ConnectWithContext(context.Background(), transport)
+}
+
+func (m *MockClient) ConnectWithContext(ctx context.Context, transport protocol.Transport) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	m.connected = true
@@ -82,7 +89,16 @@ func (m *MockClient) ListResources(ctx context.Context) ([]protocol.Resource, er
 	return []protocol.Resource{}, nil
 }
 
-func (m *MockClient) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+func (m *MockClient) ListResourceTemplates(ctx context.Context) ([]protocol.ResourceTemplate, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if !m.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	return []protocol.ResourceTemplate{}, nil
+}
+
+func (m *MockClient) CallTool(ctx context.Context, name string, params map[string]interface{}, opts ...protocol.CallOption) (interface{}, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -242,3 +258,798 @@ func TestServerManager(t *testing.T) {
 		}
 	})
 }
+
+// hangingClient blocks in Disconnect until release is closed.
+type hangingClient struct {
+	*MockClient
+	release chan struct{}
+}
+
+func (c *hangingClient) Disconnect() error {
+	<-c.release
+	return c.MockClient.Disconnect()
+}
+
+func TestShutdownAll(t *testing.T) {
+	t.Run("reports every failed server", func(t *testing.T) {
+		manager := NewManager()
+		errBroken := errors.New("broken pipe")
+
+		for _, name := range []string{"ok", "bad1", "bad2"} {
+			srv := createMockServer(name)
+			if name != "ok" {
+				srv.Client.(*MockClient).SetDisconnectError(errBroken)
+			}
+			manager.servers[name] = srv
+		}
+
+		err := manager.ShutdownAll(context.Background())
+		if !errors.Is(err, errBroken) {
+			t.Fatalf("Expected the disconnect error, got %v", err)
+		}
+
+		var shutdownErr *ShutdownError
+		if !errors.As(err, &shutdownErr) {
+			t.Fatalf("Expected a *ShutdownError, got %T", err)
+		}
+		if want := []string{"bad1", "bad2"}; !reflect.DeepEqual(shutdownErr.Servers(), want) {
+			t.Fatalf("Expected failed servers %v, got %v", want, shutdownErr.Servers())
+		}
+
+		var serverErr *ServerError
+		if !errors.As(err, &serverErr) || serverErr.Server != "bad1" {
+			t.Fatalf("Expected errors.As to find the first server's error, got %v", serverErr)
+		}
+		for _, name := range []string{"bad1", "bad2"} {
+			if !strings.Contains(err.Error(), "server "+name) {
+				t.Fatalf("Expected %s in the error, got %v", name, err)
+			}
+		}
+		if strings.Contains(err.Error(), "server ok") {
+			t.Fatalf("Did not expect the healthy server in the error, got %v", err)
+		}
+		if len(manager.ListServers()) != 0 {
+			t.Fatal("Expected every server to be removed")
+		}
+	})
+
+	t.Run("honors the context deadline and does not block lookups", func(t *testing.T) {
+		manager := NewManager()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		hung := createMockServer("hung")
+		hung.Client = &hangingClient{MockClient: NewMockClient(), release: release}
+		manager.servers["hung"] = hung
+		manager.servers["ok"] = createMockServer("ok")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() { done <- manager.ShutdownAll(ctx) }()
+
+		// The lock is not held while servers disconnect.
+		waitFor(t, func() bool { return len(manager.ListServers()) == 0 })
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "server hung") {
+				t.Fatalf("Expected a deadline error for the hung server, got %v", err)
+			}
+			if strings.Contains(err.Error(), "server ok") {
+				t.Fatalf("Did not expect the healthy server in the error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("ShutdownAll ignored the context deadline")
+		}
+	})
+
+	t.Run("applies the per-server timeout", func(t *testing.T) {
+		manager := NewManager(WithShutdownTimeout(20 * time.Millisecond))
+
+		release := make(chan struct{})
+		defer close(release)
+
+		hung := createMockServer("hung")
+		hung.Client = &hangingClient{MockClient: NewMockClient(), release: release}
+		manager.servers["hung"] = hung
+
+		err := manager.ShutdownAll(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("Expected a timeout error, got %v", err)
+		}
+	})
+}
+
+// fakeProcesses is a TransportFactory that serves each launch from an
+// in-memory fake server, so tests can crash a "process" by closing its server
+// end.
+type fakeProcesses struct {
+	mutex    sync.Mutex
+	launches []*protocol.InMemoryTransport
+	commands [][]string
+	failing  bool
+}
+
+func (f *fakeProcesses) factory(config ServerConfig) (protocol.Transport, error) {
+	clientEnd, serverEnd := protocol.NewInMemoryPair()
+
+	f.mutex.Lock()
+	failing := f.failing
+	f.launches = append(f.launches, serverEnd)
+	f.commands = append(f.commands, append([]string{config.Command}, config.Args...))
+	f.mutex.Unlock()
+
+	if failing {
+		serverEnd.Close()
+		return clientEnd, nil
+	}
+
+	serverEnd.Start()
+	go serveFake(serverEnd)
+	return clientEnd, nil
+}
+
+func (f *fakeProcesses) count() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.launches)
+}
+
+func (f *fakeProcesses) lastCommand() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.commands[len(f.commands)-1]
+}
+
+func (f *fakeProcesses) crash() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.launches[len(f.launches)-1].Close()
+}
+
+func (f *fakeProcesses) setFailing(failing bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failing = failing
+}
+
+func serveFake(transport *protocol.InMemoryTransport) {
+	for {
+		req, err := transport.ReceiveRequest()
+		if err != nil {
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			result = map[string]interface{}{
+				"protocolVersion": req.Params["protocolVersion"],
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+			}
+		case "mcp.list_tools":
+			result = map[string]interface{}{
+				"tools": []interface{}{map[string]interface{}{"name": "echo"}},
+			}
+		default:
+			continue
+		}
+
+		if err := transport.SendResponse(protocol.NewResponse(req.ID, result)); err != nil {
+			return
+		}
+	}
+}
+
+// notifyingTransport is an in-memory transport that, like StdioTransport,
+// reports crashes through OnDisconnect.
+type notifyingTransport struct {
+	*protocol.InMemoryTransport
+	serverEnd *protocol.InMemoryTransport
+	mutex     sync.Mutex
+	handlers  []func(err error)
+}
+
+func (t *notifyingTransport) OnDisconnect(handler func(err error)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+func (t *notifyingTransport) crash() {
+	t.serverEnd.Close()
+
+	t.mutex.Lock()
+	handlers := t.handlers
+	t.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(fmt.Errorf("EOF reached"))
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLaunchServerArguments(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeProcesses{}
+
+	manager := NewManager(WithTransportFactory(fake.factory))
+	defer manager.ShutdownAll(ctx)
+
+	_, err := manager.LaunchServer(ctx, ServerConfig{
+		Name:    "fake",
+		Command: "fake-server",
+		Args:    []string{`--flag="a b"`, "two  spaces", ""},
+	})
+	if err != nil {
+		t.Fatalf("Failed to launch server: %v", err)
+	}
+
+	want := []string{"fake-server", `--flag="a b"`, "two  spaces", ""}
+	if got := fake.lastCommand(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected command %q, got %q", want, got)
+	}
+}
+
+func TestTransportFactory(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+
+	var got ServerConfig
+	fake := &fakeProcesses{}
+	manager := NewManager(WithTransportFactory(func(config ServerConfig) (protocol.Transport, error) {
+		got = config
+		return fake.factory(config)
+	}))
+	defer manager.ShutdownAll(context.Background())
+
+	_, err := manager.LaunchServer(context.Background(), ServerConfig{
+		Name:    "remote",
+		Type:    TransportHTTP,
+		URL:     "https://example.com/mcp",
+		Headers: map[string]string{"Authorization": "Bearer ${MCP_TEST_TOKEN}"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to launch server: %v", err)
+	}
+
+	if got.Name != "remote" || got.Headers["Authorization"] != "Bearer secret" {
+		t.Fatalf("Expected the expanded config, got %+v", got)
+	}
+
+	srv, _ := manager.GetServer("remote")
+	if srv.Config.Headers["Authorization"] != "Bearer ${MCP_TEST_TOKEN}" {
+		t.Fatalf("Expected the server to keep its original config, got %+v", srv.Config)
+	}
+
+	_, err = NewManager().LaunchServer(context.Background(), ServerConfig{
+		Name: "remote",
+		Type: TransportWebSocket,
+		URL:  "wss://example.com/mcp",
+	})
+	if !errors.Is(err, ErrUnsupportedTransport) {
+		t.Fatalf("Expected ErrUnsupportedTransport from the default factory, got %v", err)
+	}
+}
+
+func TestLaunchTimeout(t *testing.T) {
+	// The fake server accepts the connection but never answers the handshake.
+	var ends []*protocol.InMemoryTransport
+	manager := NewManager(WithTransportFactory(func(config ServerConfig) (protocol.Transport, error) {
+		clientEnd, serverEnd := protocol.NewInMemoryPair()
+		serverEnd.Start()
+		ends = append(ends, serverEnd)
+		return clientEnd, nil
+	}))
+
+	// Both bounds are far below the client's default connect timeout, which
+	// would otherwise end the launch too.
+	launch := func(ctx context.Context, config ServerConfig) error {
+		t.Helper()
+
+		start := time.Now()
+		_, err := manager.LaunchServer(ctx, config)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("LaunchServer took %v", elapsed)
+		}
+		if ends[len(ends)-1].IsConnected() {
+			t.Fatal("Expected the abandoned transport to be closed")
+		}
+		return err
+	}
+
+	err := launch(context.Background(), ServerConfig{Name: "slow", Command: "fake", LaunchTimeout: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline error from LaunchTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = launch(ctx, ServerConfig{Name: "slow", Command: "fake"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline error from the launch context, got %v", err)
+	}
+
+	if _, err := manager.GetServer("slow"); !errors.Is(err, ErrServerNotFound) {
+		t.Fatalf("Expected no server to be registered, got %v", err)
+	}
+}
+
+func TestServerRestart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("relaunches a crashed server", func(t *testing.T) {
+		fake := &fakeProcesses{}
+
+		manager := NewManager(WithTransportFactory(fake.factory))
+		manager.checkInterval = 5 * time.Millisecond
+		manager.SetRestartPolicy(3, time.Millisecond)
+		defer manager.ShutdownAll(ctx)
+
+		original, err := manager.LaunchServer(ctx, ServerConfig{Name: "fake", Command: "fake-server"})
+		if err != nil {
+			t.Fatalf("Failed to launch server: %v", err)
+		}
+
+		fake.crash()
+
+		waitFor(t, func() bool {
+			srv, err := manager.GetServer("fake")
+			return err == nil && srv != original && srv.IsRunning()
+		})
+
+		srv, _ := manager.GetServer("fake")
+		if tools := srv.GetTools(); len(tools) != 1 || tools[0].Name != "echo" {
+			t.Fatalf("Expected tools to be rediscovered, got %v", tools)
+		}
+		if fake.count() != 2 {
+			t.Fatalf("Expected 2 launches, got %d", fake.count())
+		}
+	})
+
+	t.Run("restarts at once when the transport reports a drop", func(t *testing.T) {
+		var mutex sync.Mutex
+		var launches []*notifyingTransport
+
+		manager := NewManager(WithTransportFactory(func(config ServerConfig) (protocol.Transport, error) {
+			clientEnd, serverEnd := protocol.NewInMemoryPair()
+			serverEnd.Start()
+			go serveFake(serverEnd)
+
+			transport := &notifyingTransport{InMemoryTransport: clientEnd, serverEnd: serverEnd}
+			mutex.Lock()
+			launches = append(launches, transport)
+			mutex.Unlock()
+			return transport, nil
+		}))
+		manager.checkInterval = time.Hour
+		manager.SetRestartPolicy(3, time.Millisecond)
+		defer manager.ShutdownAll(ctx)
+
+		launched, err := manager.LaunchServer(ctx, ServerConfig{Name: "fake"})
+		if err != nil {
+			t.Fatalf("Failed to launch server: %v", err)
+		}
+
+		mutex.Lock()
+		launches[0].crash()
+		mutex.Unlock()
+
+		waitFor(t, func() bool {
+			srv, err := manager.GetServer("fake")
+			return err == nil && srv != launched && srv.IsRunning()
+		})
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		fake := &fakeProcesses{}
+
+		manager := NewManager(WithTransportFactory(fake.factory))
+		manager.checkInterval = 5 * time.Millisecond
+		manager.SetRestartPolicy(2, time.Millisecond)
+		defer manager.ShutdownAll(ctx)
+
+		if _, err := manager.LaunchServer(ctx, ServerConfig{Name: "fake"}); err != nil {
+			t.Fatalf("Failed to launch server: %v", err)
+		}
+
+		fake.setFailing(true)
+		fake.crash()
+
+		waitFor(t, func() bool { return fake.count() == 3 })
+
+		time.Sleep(50 * time.Millisecond)
+		if fake.count() != 3 {
+			t.Fatalf("Expected no launches after giving up, got %d", fake.count())
+		}
+	})
+
+	t.Run("shutdown stops supervision", func(t *testing.T) {
+		fake := &fakeProcesses{}
+
+		manager := NewManager(WithTransportFactory(fake.factory))
+		manager.checkInterval = 5 * time.Millisecond
+		manager.SetRestartPolicy(3, 20*time.Millisecond)
+
+		if _, err := manager.LaunchServer(ctx, ServerConfig{Name: "fake"}); err != nil {
+			t.Fatalf("Failed to launch server: %v", err)
+		}
+
+		fake.crash()
+		if err := manager.ShutdownServer(ctx, "fake"); err != nil {
+			t.Fatalf("Failed to shutdown server: %v", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		if _, err := manager.GetServer("fake"); err == nil {
+			t.Fatal("Server should not come back after shutdown")
+		}
+		if fake.count() != 1 {
+			t.Fatalf("Expected no relaunch after shutdown, got %d launches", fake.count())
+		}
+	})
+}
+
+func TestHealthMonitor(t *testing.T) {
+	manager := NewManager()
+
+	mockServer := createMockServer("test-server")
+	manager.servers["test-server"] = mockServer
+
+	type change struct {
+		name    string
+		healthy bool
+	}
+	changes := make(chan change, 10)
+	manager.OnHealthChange(func(name string, healthy bool) {
+		changes <- change{name, healthy}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.StartHealthMonitor(ctx, 5*time.Millisecond)
+
+	expect := func(want change) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("Expected %+v, got %+v", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %+v", want)
+		}
+	}
+
+	mockClient := mockServer.Client.(*MockClient)
+	mockClient.SetHealthStatus(fmt.Errorf("ping failed"))
+	expect(change{"test-server", false})
+
+	mockClient.SetHealthStatus(nil)
+	expect(change{"test-server", true})
+
+	// Unchanged status is not reported again.
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case got := <-changes:
+		t.Fatalf("Unexpected change %+v", got)
+	default:
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	mockClient.SetHealthStatus(fmt.Errorf("ping failed"))
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case got := <-changes:
+		t.Fatalf("Monitor kept running after cancel: %+v", got)
+	default:
+	}
+}
+
+// unresponsiveClient never answers a health check before ctx is done.
+type unresponsiveClient struct {
+	*MockClient
+}
+
+func (c *unresponsiveClient) HealthCheck(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestMonitorHealthWithHungServer(t *testing.T) {
+	manager := NewManager()
+	hung := createMockServer("hung")
+	hung.Client = &unresponsiveClient{MockClient: hung.Client.(*MockClient)}
+	manager.servers["hung"] = hung
+	manager.servers["healthy"] = createMockServer("healthy")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan map[string]error, 1)
+	go func() {
+		results <- manager.MonitorHealth(ctx)
+	}()
+
+	// Writers must not wait for the hung check.
+	time.Sleep(20 * time.Millisecond)
+	locked := make(chan struct{})
+	go func() {
+		manager.mutex.Lock()
+		manager.mutex.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("MonitorHealth held the manager lock during a health check")
+	}
+
+	cancel()
+	select {
+	case got := <-results:
+		if got["healthy"] != nil {
+			t.Fatalf("Expected the healthy server to pass, got %v", got["healthy"])
+		}
+		if !errors.Is(got["hung"], context.Canceled) {
+			t.Fatalf("Expected the hung server to fail with context.Canceled, got %v", got["hung"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("MonitorHealth did not return after cancel")
+	}
+}
+
+// slowClient delays ListTools to simulate a server that is slow to answer.
+type slowClient struct {
+	*MockClient
+	delay time.Duration
+}
+
+func (c *slowClient) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	select {
+	case <-time.After(c.delay):
+		return c.MockClient.ListTools(ctx)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestDiscoverToolsConcurrently(t *testing.T) {
+	newManager := func(delay time.Duration) *Manager {
+		manager := NewManager()
+		for i := 0; i < 10; i++ {
+			server := createMockServer(fmt.Sprintf("server%d", i))
+			server.Client = &slowClient{MockClient: server.Client.(*MockClient), delay: delay}
+			manager.servers[server.Name] = server
+		}
+		return manager
+	}
+
+	t.Run("queries servers in parallel", func(t *testing.T) {
+		manager := newManager(50 * time.Millisecond)
+
+		start := time.Now()
+		toolMap, err := manager.DiscoverTools(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to discover tools: %v", err)
+		}
+
+		if len(toolMap) != 10 {
+			t.Fatalf("Expected tools from 10 servers, got %d", len(toolMap))
+		}
+		if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+			t.Fatalf("Discovery took %v, expected servers to be queried concurrently", elapsed)
+		}
+	})
+
+	t.Run("does not block other readers", func(t *testing.T) {
+		manager := newManager(200 * time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			manager.DiscoverTools(context.Background())
+			close(done)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		manager.mutex.Lock()
+		manager.mutex.Unlock()
+
+		select {
+		case <-done:
+			t.Fatal("Lock should be available while discovery is still running")
+		default:
+		}
+		<-done
+	})
+
+	t.Run("can be read while tools are replaced", func(t *testing.T) {
+		manager := newManager(0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					manager.DiscoverTools(context.Background())
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					for _, name := range manager.ListServers() {
+						if server, err := manager.GetServer(name); err == nil {
+							server.GetTools()
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		server, _ := manager.GetServer("server0")
+		if len(server.GetTools()) == 0 {
+			t.Fatal("Expected discovered tools")
+		}
+	})
+
+	t.Run("respects cancellation", func(t *testing.T) {
+		manager := newManager(time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := manager.DiscoverTools(ctx)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+type recordedCall struct {
+	server, tool string
+	failed       bool
+}
+
+// fakeRecorder collects the observations forwarded by WithMetricsRecorder.
+type fakeRecorder struct {
+	mutex        sync.Mutex
+	calls        []recordedCall
+	healthChecks int
+}
+
+func (r *fakeRecorder) ObserveToolCall(server, tool string, latency time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, recordedCall{server, tool, err != nil})
+}
+
+func (r *fakeRecorder) ObserveHealthCheck(server string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.healthChecks++
+}
+
+func TestMetrics(t *testing.T) {
+	recorder := &fakeRecorder{}
+	manager := NewManager(WithMetricsRecorder(recorder))
+
+	mockServer := createMockServer("test-server")
+	manager.servers["test-server"] = mockServer
+	manager.servers["idle-server"] = createMockServer("idle-server")
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := manager.CallTool(ctx, "test-server", "echo", map[string]interface{}{"text": "hi"}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	}
+
+	if _, err := manager.CallTool(ctx, "missing", "echo", nil); !errors.Is(err, ErrServerNotFound) {
+		t.Fatalf("Expected ErrServerNotFound, got %v", err)
+	}
+
+	before := time.Now()
+	manager.MonitorHealth(ctx)
+
+	mockClient := mockServer.Client.(*MockClient)
+	mockClient.Disconnect()
+	if _, err := manager.CallTool(ctx, "test-server", "echo", nil); err == nil {
+		t.Fatal("Expected CallTool on a disconnected client to fail")
+	}
+	manager.MonitorHealth(ctx)
+
+	metrics := manager.Metrics()
+	if len(metrics) != 2 {
+		t.Fatalf("Expected metrics for 2 servers, got %d", len(metrics))
+	}
+
+	got := metrics["test-server"]
+	if got.ToolCalls != 4 || got.Errors != 1 {
+		t.Fatalf("Expected 4 calls and 1 error, got %d and %d", got.ToolCalls, got.Errors)
+	}
+	if got.LastHealthCheck.Before(before) {
+		t.Fatalf("Expected a health check after %v, got %v", before, got.LastHealthCheck)
+	}
+	if got.LastHealthError == nil {
+		t.Fatal("Expected the last health check to have failed")
+	}
+
+	idle := metrics["idle-server"]
+	if idle.ToolCalls != 0 || idle.AverageLatency != 0 || idle.LastHealthError != nil {
+		t.Fatalf("Unexpected metrics for idle server: %+v", idle)
+	}
+
+	recorder.mutex.Lock()
+	if len(recorder.calls) != 4 || recorder.calls[0] != (recordedCall{"test-server", "echo", false}) || !recorder.calls[3].failed {
+		t.Fatalf("Unexpected recorded calls: %+v", recorder.calls)
+	}
+	if recorder.healthChecks != 4 {
+		t.Fatalf("Expected 4 recorded health checks, got %d", recorder.healthChecks)
+	}
+	recorder.mutex.Unlock()
+
+	if err := manager.ShutdownServer(ctx, "test-server"); err != nil {
+		t.Fatalf("ShutdownServer failed: %v", err)
+	}
+	manager.servers["test-server"] = createMockServer("test-server")
+	if got := manager.Metrics()["test-server"]; got.ToolCalls != 0 {
+		t.Fatalf("Expected metrics to reset after shutdown, got %+v", got)
+	}
+}
+
+// queuedTransport reports a fixed number of queued frames.
+type queuedTransport struct {
+	protocol.Transport
+	depth int
+}
+
+func (t *queuedTransport) QueueDepth() int {
+	return t.depth
+}
+
+func TestMetricsQueueDepth(t *testing.T) {
+	manager := NewManager()
+
+	queued := createMockServer("queued")
+	queued.Transport = &queuedTransport{depth: 3}
+	manager.servers["queued"] = queued
+	manager.servers["plain"] = createMockServer("plain")
+
+	metrics := manager.Metrics()
+	if got := metrics["queued"].QueueDepth; got != 3 {
+		t.Fatalf("Expected a queue depth of 3, got %d", got)
+	}
+	if got := metrics["plain"].QueueDepth; got != 0 {
+		t.Fatalf("Expected no queue depth for a transport without a queue, got %d", got)
+	}
+}
+
+func TestListServersOrdering(t *testing.T) {
+	manager := NewManager()
+	for _, name := range []string{"delta", "alpha", "charlie", "bravo"} {
+		manager.servers[name] = createMockServer(name)
+	}
+
+	want := []string{"alpha", "bravo", "charlie", "delta"}
+	for i := 0; i < 10; i++ {
+		if got := manager.ListServers(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// ServerMetrics is a snapshot of the counters the Manager keeps for one
+// server. Counters survive restarts and are reset when the server is shut
+// down.
+type ServerMetrics struct {
+	// ToolCalls is the number of tool calls made through Manager.CallTool.
+	ToolCalls uint64
+	// Errors is the number of those calls that returned an error.
+	Errors uint64
+	// AverageLatency is the mean duration of all tool calls.
+	AverageLatency time.Duration
+	// LastHealthCheck is when MonitorHealth last checked the server, or the
+	// zero time if it never has.
+	LastHealthCheck time.Time
+	// LastHealthError is the outcome of that check; nil means healthy.
+	LastHealthError error
+	// QueueDepth is the number of messages waiting to be written to the
+	// server, for transports that queue them, such as stdio.
+	QueueDepth int
+}
+
+// MetricsRecorder receives every observation the Manager makes, for example
+// to bridge them to Prometheus. Methods may be called concurrently.
+type MetricsRecorder interface {
+	ObserveToolCall(server, tool string, latency time.Duration, err error)
+	ObserveHealthCheck(server string, err error)
+}
+
+// WithMetricsRecorder forwards tool call and health check observations to
+// recorder, in addition to the counters returned by Metrics.
+func WithMetricsRecorder(recorder MetricsRecorder) ManagerOption {
+	return func(m *Manager) {
+		m.recorder = recorder
+	}
+}
+
+type serverMetrics struct {
+	toolCalls       uint64
+	errors          uint64
+	totalLatency    time.Duration
+	lastHealthCheck time.Time
+	lastHealthError error
+	mutex           sync.Mutex
+}
+
+func (s *serverMetrics) snapshot() ServerMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := ServerMetrics{
+		ToolCalls:       s.toolCalls,
+		Errors:          s.errors,
+		LastHealthCheck: s.lastHealthCheck,
+		LastHealthError: s.lastHealthError,
+	}
+	if s.toolCalls > 0 {
+		snapshot.AverageLatency = s.totalLatency / time.Duration(s.toolCalls)
+	}
+
+	return snapshot
+}
+
+// metricsFor returns the counters of the named server, creating them on first
+// use. It takes its own lock so it can be called with m.mutex held.
+func (m *Manager) metricsFor(name string) *serverMetrics {
+	m.metricsMutex.Lock()
+	defer m.metricsMutex.Unlock()
+
+	metrics, exists := m.metrics[name]
+	if !exists {
+		metrics = &serverMetrics{}
+		m.metrics[name] = metrics
+	}
+
+	return metrics
+}
+
+func (m *Manager) resetMetrics(name string) {
+	m.metricsMutex.Lock()
+	defer m.metricsMutex.Unlock()
+
+	delete(m.metrics, name)
+}
+
+func (m *Manager) recordToolCall(server, tool string, latency time.Duration, err error) {
+	metrics := m.metricsFor(server)
+
+	metrics.mutex.Lock()
+	metrics.toolCalls++
+	metrics.totalLatency += latency
+	if err != nil {
+		metrics.errors++
+	}
+	metrics.mutex.Unlock()
+
+	if m.recorder != nil {
+		m.recorder.ObserveToolCall(server, tool, latency, err)
+	}
+}
+
+func (m *Manager) recordHealthCheck(server string, err error) {
+	metrics := m.metricsFor(server)
+
+	metrics.mutex.Lock()
+	metrics.lastHealthCheck = time.Now()
+	metrics.lastHealthError = err
+	metrics.mutex.Unlock()
+
+	if m.recorder != nil {
+		m.recorder.ObserveHealthCheck(server, err)
+	}
+}
+
+// Metrics returns a snapshot of the counters of every managed server.
+func (m *Manager) Metrics() map[string]ServerMetrics {
+	m.mutex.RLock()
+	servers := make([]*Server, 0, len(m.servers))
+	for _, server := range m.servers {
+		servers = append(servers, server)
+	}
+	m.mutex.RUnlock()
+
+	snapshots := make(map[string]ServerMetrics, len(servers))
+	for _, server := range servers {
+		snapshot := m.metricsFor(server.Name).snapshot()
+		if queue, ok := server.Transport.(protocol.QueueReporter); ok {
+			snapshot.QueueDepth = queue.QueueDepth()
+		}
+		snapshots[server.Name] = snapshot
+	}
+
+	return snapshots
+}
+
+// CallTool calls a tool on the named server and records the call in the
+// server's metrics. Calls over the server's MaxConcurrency wait, or fail,
+// before being sent and are not recorded.
+func (m *Manager) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}, opts ...protocol.CallOption) (interface{}, error) {
+	server, err := m.GetServer(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := server.acquireCall(ctx, m.rejectWhenBusy)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	result, err := server.Client.CallTool(ctx, toolName, args, opts...)
+	m.recordToolCall(serverName, toolName, time.Since(start), err)
+
+	return result, err
+}
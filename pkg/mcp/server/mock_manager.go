@@ -96,7 +96,7 @@ func (m *MockManager) DiscoverTools(ctx context.Context) (map[string][]protocol.
 	tools := make(map[string][]protocol.Tool)
 
 	for name, server := range m.servers {
-		tools[name] = server.Tools
+		tools[name] = server.GetTools()
 	}
 
 	return tools, nil
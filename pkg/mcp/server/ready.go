@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// ErrNotReady is returned by LaunchServer when a server's ReadyCheck does not
+// pass in time.
+var ErrNotReady = errors.New("server not ready")
+
+// DefaultReadyCheckInterval is how long a ReadyCheck waits between attempts
+// when it sets no Interval.
+const DefaultReadyCheckInterval = 100 * time.Millisecond
+
+// ReadyCheck makes LaunchServer, and restarts, wait after the handshake until
+// a server that needs to warm up answers successfully.
+type ReadyCheck struct {
+	// Tool, when set, is called with Arguments on every attempt, which passes
+	// once the call succeeds without the tool reporting an error. Otherwise
+	// the server is pinged.
+	Tool      string
+	Arguments map[string]interface{}
+
+	// Interval is the wait between attempts; zero means
+	// DefaultReadyCheckInterval.
+	Interval time.Duration
+
+	// Timeout bounds how long to wait for the server. Zero leaves only
+	// LaunchTimeout and the context given to LaunchServer.
+	Timeout time.Duration
+}
+
+// waitReady runs check against client until it passes or ctx, bounded by the
+// check's Timeout, is done.
+func waitReady(ctx context.Context, client protocol.MCPClient, check ReadyCheck) error {
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	interval := check.Interval
+	if interval <= 0 {
+		interval = DefaultReadyCheckInterval
+	}
+
+	for {
+		err := check.attempt(ctx, client)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: last attempt: %v", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+func (check ReadyCheck) attempt(ctx context.Context, client protocol.MCPClient) error {
+	if check.Tool == "" {
+		return client.HealthCheck(ctx)
+	}
+
+	result, err := client.CallTool(ctx, check.Tool, check.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if decoded, err := protocol.DecodeCallToolResult(result); err == nil && decoded.IsError {
+		return fmt.Errorf("%w: %s", protocol.ErrToolResultError, decoded.Text())
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// warmingUp is a fake server that fails pings, and calls to its "warmup"
+// tool, until it has been asked readyAfter times.
+type warmingUp struct {
+	readyAfter int32
+	attempts   atomic.Int32
+}
+
+func (w *warmingUp) factory(config ServerConfig) (protocol.Transport, error) {
+	clientEnd, serverEnd := protocol.NewInMemoryPair()
+	serverEnd.Start()
+	go w.serve(serverEnd)
+	return clientEnd, nil
+}
+
+func (w *warmingUp) serve(transport *protocol.InMemoryTransport) {
+	for {
+		req, err := transport.ReceiveRequest()
+		if err != nil {
+			return
+		}
+
+		var response *protocol.JSONRPCResponse
+		switch req.Method {
+		case "initialize":
+			response = protocol.NewResponse(req.ID, map[string]interface{}{
+				"protocolVersion": req.Params["protocolVersion"],
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+			})
+		case "mcp.list_tools":
+			response = protocol.NewResponse(req.ID, map[string]interface{}{"tools": []interface{}{}})
+		case "ping":
+			if w.attempts.Add(1) <= w.readyAfter {
+				response = protocol.NewErrorResponse(req.ID, protocol.ErrInternalError, "warming up", nil)
+			} else {
+				response = protocol.NewResponse(req.ID, map[string]interface{}{})
+			}
+		case "warmup":
+			ready := w.attempts.Add(1) > w.readyAfter
+			response = protocol.NewResponse(req.ID, map[string]interface{}{
+				"content": []interface{}{map[string]interface{}{"type": "text", "text": "warming up"}},
+				"isError": !ready,
+			})
+		default:
+			continue
+		}
+
+		if err := transport.SendResponse(response); err != nil {
+			return
+		}
+	}
+}
+
+func TestReadyCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waits for the server to answer pings", func(t *testing.T) {
+		fake := &warmingUp{readyAfter: 3}
+		manager := NewManager(WithTransportFactory(fake.factory))
+		defer manager.ShutdownAll(ctx)
+
+		_, err := manager.LaunchServer(ctx, ServerConfig{
+			Name:       "slow",
+			Command:    "fake",
+			ReadyCheck: &ReadyCheck{Interval: time.Millisecond},
+		})
+		if err != nil {
+			t.Fatalf("LaunchServer failed: %v", err)
+		}
+		if got := fake.attempts.Load(); got != 4 {
+			t.Fatalf("Expected LaunchServer to return after the 4th ping, got %d pings", got)
+		}
+	})
+
+	t.Run("calls the configured tool", func(t *testing.T) {
+		fake := &warmingUp{readyAfter: 2}
+		manager := NewManager(WithTransportFactory(fake.factory))
+		defer manager.ShutdownAll(ctx)
+
+		_, err := manager.LaunchServer(ctx, ServerConfig{
+			Name:       "slow",
+			Command:    "fake",
+			ReadyCheck: &ReadyCheck{Tool: "warmup", Interval: time.Millisecond},
+		})
+		if err != nil {
+			t.Fatalf("LaunchServer failed: %v", err)
+		}
+		if got := fake.attempts.Load(); got != 3 {
+			t.Fatalf("Expected LaunchServer to return after the 3rd call, got %d calls", got)
+		}
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		fake := &warmingUp{readyAfter: 1 << 30}
+		manager := NewManager(WithTransportFactory(fake.factory))
+
+		start := time.Now()
+		_, err := manager.LaunchServer(ctx, ServerConfig{
+			Name:       "stuck",
+			Command:    "fake",
+			ReadyCheck: &ReadyCheck{Interval: 5 * time.Millisecond, Timeout: 50 * time.Millisecond},
+		})
+		if !errors.Is(err, ErrNotReady) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected ErrNotReady after a deadline, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("LaunchServer took %v", elapsed)
+		}
+		if fake.attempts.Load() < 2 {
+			t.Fatalf("Expected several attempts, got %d", fake.attempts.Load())
+		}
+		if _, err := manager.GetServer("stuck"); !errors.Is(err, ErrServerNotFound) {
+			t.Fatalf("Expected no server to be registered, got %v", err)
+		}
+	})
+}
+
+func TestReadyCheckDoesNotBlockManager(t *testing.T) {
+	ctx := context.Background()
+
+	slow := &warmingUp{readyAfter: 1 << 30}
+	fast := &warmingUp{}
+	manager := NewManager(WithTransportFactory(func(config ServerConfig) (protocol.Transport, error) {
+		if config.Name == "slow" {
+			return slow.factory(config)
+		}
+		return fast.factory(config)
+	}))
+	defer manager.ShutdownAll(ctx)
+
+	if _, err := manager.LaunchServer(ctx, ServerConfig{Name: "fast", Command: "fake"}); err != nil {
+		t.Fatalf("LaunchServer failed: %v", err)
+	}
+
+	launched := make(chan error, 1)
+	go func() {
+		_, err := manager.LaunchServer(ctx, ServerConfig{
+			Name:       "slow",
+			Command:    "fake",
+			ReadyCheck: &ReadyCheck{Interval: 5 * time.Millisecond, Timeout: time.Minute},
+		})
+		launched <- err
+	}()
+	waitFor(t, func() bool { return slow.attempts.Load() >= 2 })
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := manager.CallTool(ctx, "fast", "warmup", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("CallTool blocked behind a server warming up")
+	}
+
+	if names := manager.ListServers(); len(names) != 1 || names[0] != "fast" {
+		t.Fatalf("Expected only the running server to be listed, got %v", names)
+	}
+	if _, err := manager.LaunchServer(ctx, ServerConfig{Name: "slow", Command: "fake"}); !errors.Is(err, ErrServerExists) {
+		t.Fatalf("Expected the launching name to be taken, got %v", err)
+	}
+
+	if err := manager.ShutdownServer(ctx, "slow"); err != nil {
+		t.Fatalf("ShutdownServer failed: %v", err)
+	}
+	select {
+	case err := <-launched:
+		if !errors.Is(err, ErrLaunchAborted) {
+			t.Fatalf("Expected ErrLaunchAborted, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LaunchServer did not return after the shutdown")
+	}
+	if _, err := manager.GetServer("slow"); !errors.Is(err, ErrServerNotFound) {
+		t.Fatalf("Expected no server to be registered, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package tool
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go-mcp/pkg/mcp/protocol"
+)
+
+// resultCache holds tool results keyed by qualified tool name and then by
+// canonicalized arguments, so a whole tool can be invalidated at once.
+// Invalidating a tool also bumps its generation, which keeps results computed
+// before the invalidation from being stored after it.
+type resultCache struct {
+	ttl         time.Duration
+	maxEntries  int
+	entries     map[string]map[string]cacheEntry
+	generations map[string]uint64
+	size        int
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+type cacheEntry struct {
+	result  *protocol.CallToolResult
+	expires time.Time
+}
+
+func newResultCache(ttl time.Duration, maxEntries int) *resultCache {
+	return &resultCache{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		entries:     make(map[string]map[string]cacheEntry),
+		generations: make(map[string]uint64),
+		now:         time.Now,
+	}
+}
+
+// canonicalArguments encodes args so that equal arguments always produce the
+// same key. encoding/json sorts map keys at every level, which is what makes
+// the result independent of map ordering.
+func canonicalArguments(args map[string]interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "{}", true
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (c *resultCache) get(tool, args string) (*protocol.CallToolResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.entries[tool][args]
+	if !exists {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expires) {
+		c.remove(tool, args)
+		return nil, false
+	}
+
+	return entry.result, true
+}
+
+// generation returns the number of times tool has been invalidated. Read it
+// before running the tool and pass it to put.
+func (c *resultCache) generation(tool string) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generations[tool]
+}
+
+// put stores result unless tool has been invalidated since generation was
+// read, in which case the result may come from a tool that is gone.
+func (c *resultCache) put(tool, args string, generation uint64, result *protocol.CallToolResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generations[tool] != generation {
+		return
+	}
+
+	now := c.now()
+
+	if _, exists := c.entries[tool][args]; !exists && c.maxEntries > 0 && c.size >= c.maxEntries {
+		c.evict(now)
+	}
+
+	if c.entries[tool] == nil {
+		c.entries[tool] = make(map[string]cacheEntry)
+	}
+	if _, exists := c.entries[tool][args]; !exists {
+		c.size++
+	}
+	c.entries[tool][args] = cacheEntry{result: result, expires: now.Add(c.ttl)}
+}
+
+// evict drops expired entries and, if the cache is still full, the entry
+// closest to expiry. With a single TTL that is the oldest one.
+func (c *resultCache) evict(now time.Time) {
+	var oldestTool, oldestArgs string
+	var oldest time.Time
+
+	for tool, byArgs := range c.entries {
+		for args, entry := range byArgs {
+			if !now.Before(entry.expires) {
+				c.remove(tool, args)
+				continue
+			}
+			if oldest.IsZero() || entry.expires.Before(oldest) {
+				oldestTool, oldestArgs, oldest = tool, args, entry.expires
+			}
+		}
+	}
+
+	if c.size >= c.maxEntries && !oldest.IsZero() {
+		c.remove(oldestTool, oldestArgs)
+	}
+}
+
+func (c *resultCache) remove(tool, args string) {
+	if _, exists := c.entries[tool][args]; !exists {
+		return
+	}
+
+	delete(c.entries[tool], args)
+	c.size--
+	if len(c.entries[tool]) == 0 {
+		delete(c.entries, tool)
+	}
+}
+
+func (c *resultCache) invalidate(tool string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.size -= len(c.entries[tool])
+	delete(c.entries, tool)
+	c.generations[tool]++
+}
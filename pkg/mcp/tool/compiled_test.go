@@ -0,0 +1,118 @@
+package tool
+
+import (
+	"testing"
+
+	"go-mcp/pkg/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// compiledTestSchema exercises every keyword the validator understands.
+func compiledTestSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []interface{}{"name"},
+		"properties": map[string]interface{}{
+			"name":     map[string]interface{}{"type": "string", "minLength": 2, "maxLength": 10, "pattern": "^[a-z]+$"},
+			"mode":     map[string]interface{}{"type": "string", "enum": []string{"fast", "slow"}},
+			"count":    map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 10},
+			"ratio":    map[string]interface{}{"type": "number", "exclusiveMinimum": 0.0, "exclusiveMaximum": 1.0},
+			"verbose":  map[string]interface{}{"type": "boolean"},
+			"password": map[string]interface{}{"type": "string", "minLength": 8, "x-sensitive": true},
+			"tags": map[string]interface{}{
+				"type": "array", "minItems": 1, "maxItems": 3,
+				"items": map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b"}},
+			},
+			"config": map[string]interface{}{
+				"type":                 "object",
+				"required":             []string{"retries"},
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"retries": map[string]interface{}{"type": "integer", "minimum": 0},
+					"token":   map[string]interface{}{"type": "string", "enum": []interface{}{"x"}, "x-sensitive": true},
+				},
+			},
+		},
+	}
+}
+
+func TestRegistryValidatesWithCompiledSchema(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.RegisterTool(&protocol.Tool{
+		Name:        "check",
+		InputSchema: compiledTestSchema(),
+		Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+			return &protocol.CallToolResult{}, nil
+		},
+	}, "source"))
+
+	t.Run("registered tools validate with their compiled schema", func(t *testing.T) {
+		cases := []struct {
+			args map[string]interface{}
+			want string
+		}{
+			{map[string]interface{}{}, "missing required field: name"},
+			{map[string]interface{}{"name": "Ada"}, `invalid argument name: value "Ada" does not match pattern "^[a-z]+$"`},
+			{map[string]interface{}{"name": "ada", "mode": "medium"}, `invalid argument mode: value "medium" not in enum [fast slow]`},
+			{map[string]interface{}{"name": "ada", "tags": []interface{}{"c"}}, `invalid argument tags[0]: value "c" not in enum [a b]`},
+			{map[string]interface{}{"name": "ada", "config": map[string]interface{}{}}, "invalid argument config: missing required field: retries"},
+		}
+		for _, tc := range cases {
+			_, err := registry.ExecuteTool(&protocol.ToolCall{Name: "check", Arguments: tc.args})
+			assert.EqualError(t, err, "invalid arguments: "+tc.want)
+		}
+
+		_, err := registry.ExecuteTool(&protocol.ToolCall{Name: "check", Arguments: map[string]interface{}{"name": "ada", "mode": "fast"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("schema updates are compiled", func(t *testing.T) {
+		assert.NoError(t, registry.UpdateToolSchema("check", map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"id"},
+		}))
+
+		_, err := registry.ExecuteTool(&protocol.ToolCall{Name: "check", Arguments: map[string]interface{}{"name": "ada"}})
+		assert.EqualError(t, err, "invalid arguments: missing required field: id")
+	})
+
+	t.Run("copies handed out validate against their own schema", func(t *testing.T) {
+		tool, _ := registry.GetTool("check")
+		tool.InputSchema = map[string]interface{}{"type": "object"}
+
+		assert.NoError(t, tool.ValidateArguments(map[string]interface{}{}))
+	})
+}
+
+func BenchmarkValidateArguments(b *testing.B) {
+	args := map[string]interface{}{
+		"name":   "ada",
+		"mode":   "fast",
+		"count":  3.0,
+		"ratio":  0.5,
+		"tags":   []interface{}{"a", "b"},
+		"config": map[string]interface{}{"retries": 2.0},
+	}
+
+	b.Run("raw", func(b *testing.B) {
+		tool := &protocol.Tool{Name: "check", InputSchema: compiledTestSchema()}
+		for i := 0; i < b.N; i++ {
+			if err := tool.ValidateArguments(args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("compiled", func(b *testing.B) {
+		tool := own(&protocol.Tool{Name: "check", InputSchema: compiledTestSchema()})
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := tool.ValidateArguments(args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package tool
+
+// SubscriberBuffer is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const SubscriberBuffer = 64
+
+type RegistryEventType string
+
+const (
+	ToolRegistered   RegistryEventType = "registered"
+	ToolUnregistered RegistryEventType = "unregistered"
+	ToolReplaced     RegistryEventType = "replaced"
+)
+
+// RegistryEvent reports a change to the set of registered tools. Name is the
+// tool's own name; QualifiedName(Source, Name) is its key in the registry.
+type RegistryEvent struct {
+	Type   RegistryEventType
+	Name   string
+	Source string
+}
+
+// Subscribe returns a channel receiving an event for every tool registered,
+// replaced or unregistered from now on. Sending never blocks the registry: a subscriber
+// more than SubscriberBuffer events behind misses events until it catches up.
+func (r *Registry) Subscribe() <-chan RegistryEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ch := make(chan RegistryEvent, SubscriberBuffer)
+	r.subscribers = append(r.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes
+// it.
+func (r *Registry) Unsubscribe(events <-chan RegistryEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, ch := range r.subscribers {
+		if ch == events {
+			r.subscribers = append(r.subscribers[:i], r.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// publish must be called with r.mutex held, which keeps events in the order
+// the changes happened.
+func (r *Registry) publish(event RegistryEvent) {
+	for _, ch := range r.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
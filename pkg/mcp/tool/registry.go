@@ -2,28 +2,121 @@ package tool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"go-mcp/pkg/mcp/protocol"
 )
 
+// NameSeparator joins a source and a tool name into a qualified name such as
+// "server1/echo".
+const NameSeparator = "/"
+
+var (
+	ErrToolNotFound  = errors.New("tool not found")
+	ErrAmbiguousTool = errors.New("tool name is ambiguous")
+)
+
+func QualifiedName(source, name string) string {
+	if source == "" {
+		return name
+	}
+	return source + NameSeparator + name
+}
+
+// Resolve finds the key for name in tools, which are keyed by qualified name.
+// An exact key match wins; otherwise an unqualified name must match exactly one
+// tool.
+func Resolve(tools map[string]*protocol.Tool, name string) (string, error) {
+	if _, exists := tools[name]; exists {
+		return name, nil
+	}
+
+	var matches []string
+	for key, tool := range tools {
+		if tool.Name == name {
+			matches = append(matches, key)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s matches %s", ErrAmbiguousTool, name, strings.Join(matches, ", "))
+	}
+}
+
+// SortedKeys returns the keys of tools, which are keyed by qualified name,
+// ordered by tool name and then by key, so that tools of the same name from
+// different sources are ordered by source.
+func SortedKeys(tools map[string]*protocol.Tool) []string {
+	keys := make([]string, 0, len(tools))
+	for key := range tools {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := tools[keys[i]].Name, tools[keys[j]].Name
+		if a != b {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Registry holds tools keyed by their qualified name, so different sources
+// may register tools with the same name. Tools are copied on the way in and
+// out, so no caller can modify a registered tool behind the registry's back.
 type Registry struct {
 	tools map[string]*protocol.Tool
 
 	sources map[string]string
 
+	cache *resultCache
+
+	subscribers []chan RegistryEvent
+
 	mutex sync.RWMutex
 }
 
-func NewRegistry() *Registry {
-	return &Registry{
+// RegistryOption configures a Registry created by NewRegistry.
+type RegistryOption func(*Registry)
+
+// WithCache makes ExecuteTool reuse the result of an identical earlier call
+// for ttl. Calls are identical when they resolve to the same tool with equal
+// arguments. At most maxEntries results are kept; zero means no limit. Only
+// successful results are cached, and they are shared between callers, who
+// must not modify them.
+func WithCache(ttl time.Duration, maxEntries int) RegistryOption {
+	return func(r *Registry) {
+		r.cache = newResultCache(ttl, maxEntries)
+	}
+}
+
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
 		tools:   make(map[string]*protocol.Tool),
 		sources: make(map[string]string),
 		mutex:   sync.RWMutex{},
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
+// RegisterTool adds tool under source. A tool without an InputSchema accepts
+// any arguments, as described on protocol.Tool.ValidateArguments.
 func (r *Registry) RegisterTool(tool *protocol.Tool, source string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -32,52 +125,169 @@ func (r *Registry) RegisterTool(tool *protocol.Tool, source string) error {
 		return fmt.Errorf("tool name cannot be empty")
 	}
 
-	if tool.InputSchema == nil {
-		return fmt.Errorf("tool input schema cannot be nil")
+	if err := protocol.ValidateSchema(tool.InputSchema); err != nil {
+		return fmt.Errorf("tool %s: %w", tool.Name, err)
+	}
+
+	key := QualifiedName(source, tool.Name)
+	if _, exists := r.tools[key]; exists {
+		return fmt.Errorf("tool %s already registered by source %s", tool.Name, source)
+	}
+
+	r.tools[key] = own(tool)
+	r.sources[key] = source
+
+	r.publish(RegistryEvent{Type: ToolRegistered, Name: tool.Name, Source: source})
+
+	return nil
+}
+
+// ReplaceTool atomically swaps the tool registered under tool.Name by source
+// for tool. It fails if source has no such tool, including when the name
+// belongs to a different source.
+func (r *Registry) ReplaceTool(tool *protocol.Tool, source string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+
+	if err := protocol.ValidateSchema(tool.InputSchema); err != nil {
+		return fmt.Errorf("tool %s: %w", tool.Name, err)
 	}
 
-	if existingSource, exists := r.sources[tool.Name]; exists {
-		return fmt.Errorf("tool %s already registered by source %s", tool.Name, existingSource)
+	key := QualifiedName(source, tool.Name)
+	if _, exists := r.tools[key]; !exists {
+		if other, err := Resolve(r.tools, tool.Name); err == nil {
+			return fmt.Errorf("tool %s is registered by source %s, not %s", tool.Name, r.sources[other], source)
+		}
+		return fmt.Errorf("%w: %s", ErrToolNotFound, key)
+	}
+
+	r.replace(key, own(tool))
+	return nil
+}
+
+// UpdateToolSchema replaces the input schema of the named tool, which may be
+// qualified or bare as for ResolveTool. A nil schema accepts any arguments.
+func (r *Registry) UpdateToolSchema(name string, schema map[string]interface{}) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key, err := Resolve(r.tools, name)
+	if err != nil {
+		return err
 	}
 
-	r.tools[tool.Name] = tool
-	r.sources[tool.Name] = source
+	if err := protocol.ValidateSchema(schema); err != nil {
+		return fmt.Errorf("tool %s: %w", r.tools[key].Name, err)
+	}
 
+	// Concurrent executions may still be using the old tool, so it is copied
+	// rather than modified in place.
+	updated := *r.tools[key]
+	updated.InputSchema = schema
+	r.replace(key, own(&updated))
 	return nil
 }
 
+// own returns the registry's private copy of tool, with its input schema
+// compiled once so that validating calls need not re-read it.
+func own(tool *protocol.Tool) *protocol.Tool {
+	owned := tool.Clone()
+	owned.Compile()
+	return owned
+}
+
+// replace must be called with r.mutex held.
+func (r *Registry) replace(key string, tool *protocol.Tool) {
+	r.tools[key] = tool
+
+	if r.cache != nil {
+		r.cache.invalidate(key)
+	}
+
+	r.publish(RegistryEvent{Type: ToolReplaced, Name: tool.Name, Source: r.sources[key]})
+}
+
+// RegisterProtocolTool is RegisterTool for a tool held by value, such as one
+// listed by a server.
 func (r *Registry) RegisterProtocolTool(protocolTool protocol.Tool, source string) error {
-	mcpTool := &protocol.Tool{
-		Name:        protocolTool.Name,
-		Description: protocolTool.Description,
-		InputSchema: protocolTool.InputSchema,
+	return r.RegisterTool(&protocolTool, source)
+}
+
+// ResolveTool looks a tool up by qualified name ("source/name") or by bare
+// name when only one source provides it. The tool returned is a copy; use
+// ReplaceTool or UpdateToolSchema to change the registered one.
+func (r *Registry) ResolveTool(name string) (*protocol.Tool, string, error) {
+	tool, source, err := r.resolve(name)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return r.RegisterTool(mcpTool, source)
+	return tool.Clone(), source, nil
 }
 
-func (r *Registry) GetTool(name string) (*protocol.Tool, bool) {
+// resolve is ResolveTool without the copy, for callers that do not hand the
+// tool out.
+func (r *Registry) resolve(name string) (*protocol.Tool, string, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	tool, exists := r.tools[name]
-	return tool, exists
+	key, err := Resolve(r.tools, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return r.tools[key], r.sources[key], nil
 }
 
-func (r *Registry) GetToolSource(name string) (string, bool) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+func (r *Registry) GetTool(name string) (*protocol.Tool, bool) {
+	tool, _, err := r.ResolveTool(name)
+	return tool, err == nil
+}
 
-	source, exists := r.sources[name]
-	return source, exists
+func (r *Registry) GetToolSource(name string) (string, bool) {
+	_, source, err := r.resolve(name)
+	return source, err == nil
 }
 
 func (r *Registry) UnregisterTool(name string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	delete(r.tools, name)
-	delete(r.sources, name)
+	key, err := Resolve(r.tools, name)
+	if err != nil {
+		return
+	}
+
+	tool, source := r.tools[key], r.sources[key]
+	delete(r.tools, key)
+	delete(r.sources, key)
+
+	if r.cache != nil {
+		r.cache.invalidate(key)
+	}
+
+	r.publish(RegistryEvent{Type: ToolUnregistered, Name: tool.Name, Source: source})
+}
+
+// InvalidateCache drops every cached result of the named tool. It does
+// nothing when the registry was created without WithCache.
+func (r *Registry) InvalidateCache(name string) {
+	if r.cache == nil {
+		return
+	}
+
+	r.mutex.RLock()
+	key, err := Resolve(r.tools, name)
+	r.mutex.RUnlock()
+	if err != nil {
+		key = name
+	}
+
+	r.cache.invalidate(key)
 }
 
 func (r *Registry) ImportFromServer(server *protocol.Client, serverName string) error {
@@ -98,38 +308,100 @@ func (r *Registry) ImportFromServer(server *protocol.Client, serverName string)
 	return nil
 }
 
+// ListTools returns every tool, in the order of SortedKeys: by name, then by
+// source.
 func (r *Registry) ListTools() []*protocol.Tool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	tools := make([]*protocol.Tool, 0, len(r.tools))
-	for _, tool := range r.tools {
-		tools = append(tools, tool)
+	for _, key := range SortedKeys(r.tools) {
+		tools = append(tools, r.tools[key].Clone())
+	}
+	return tools
+}
+
+// FindTools returns the tools for which match reports true, ordered like
+// ListTools.
+func (r *Registry) FindTools(match func(*protocol.Tool) bool) []*protocol.Tool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var tools []*protocol.Tool
+	for _, key := range SortedKeys(r.tools) {
+		if tool := r.tools[key]; match(tool) {
+			tools = append(tools, tool.Clone())
+		}
 	}
 	return tools
 }
 
+// SearchTools returns the tools whose name or description contains query,
+// ignoring case, ordered like ListTools. An empty query matches every tool.
+func (r *Registry) SearchTools(query string) []*protocol.Tool {
+	return r.FindTools(func(tool *protocol.Tool) bool {
+		return MatchesQuery(tool, query)
+	})
+}
+
+// MatchesQuery reports whether tool's name or description contains query,
+// ignoring case.
+func MatchesQuery(tool *protocol.Tool, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(tool.Name), query) ||
+		strings.Contains(strings.ToLower(tool.Description), query)
+}
+
+// ListToolsFromSource returns the tools registered by source, sorted by name.
 func (r *Registry) ListToolsFromSource(source string) []*protocol.Tool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	var tools []*protocol.Tool
-	for name, toolSource := range r.sources {
-		if toolSource == source {
-			tools = append(tools, r.tools[name])
+	for _, key := range SortedKeys(r.tools) {
+		if r.sources[key] == source {
+			tools = append(tools, r.tools[key].Clone())
 		}
 	}
 	return tools
 }
 
 func (r *Registry) ExecuteTool(call *protocol.ToolCall) (*protocol.CallToolResult, error) {
+	if r.cache == nil {
+		tool, _, err := r.resolve(call.Name)
+		if err != nil {
+			return nil, err
+		}
+		return tool.ValidateAndExecute(call.Arguments)
+	}
+
+	// The generation is read together with the tool, so that replacing or
+	// unregistering it while it runs keeps the result out of the cache.
 	r.mutex.RLock()
-	tool, exists := r.tools[call.Name]
+	key, err := Resolve(r.tools, call.Name)
+	if err != nil {
+		r.mutex.RUnlock()
+		return nil, err
+	}
+	tool := r.tools[key]
+	generation := r.cache.generation(key)
 	r.mutex.RUnlock()
 
-	if !exists {
-		return nil, fmt.Errorf("tool %s not found", call.Name)
+	args, cacheable := canonicalArguments(call.Arguments)
+	if cacheable {
+		if result, hit := r.cache.get(key, args); hit {
+			return result, nil
+		}
+	}
+
+	result, err := tool.ValidateAndExecute(call.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable && result != nil && !result.IsError {
+		r.cache.put(key, args, generation, result)
 	}
 
-	return tool.ValidateAndExecute(call.Arguments)
+	return result, nil
 }
@@ -1,7 +1,11 @@
 package tool
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"go-mcp/pkg/mcp/protocol"
 
@@ -101,10 +105,74 @@ func TestRegistry(t *testing.T) {
 		assert.Equal(t, "test-source", source, "Source should match")
 
 		// Try to register the same tool again (should fail)
-		err = registry.RegisterTool(tool, "another-source")
+		err = registry.RegisterTool(tool, "test-source")
 		assert.Error(t, err, "Registering duplicate tool should fail")
 	})
 
+	t.Run("Namespacing", func(t *testing.T) {
+		registry := NewRegistry()
+
+		newEcho := func(reply string) *protocol.Tool {
+			return &protocol.Tool{
+				Name:        "echo",
+				InputSchema: map[string]interface{}{"type": "object"},
+				Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+					return &protocol.CallToolResult{
+						Content: []protocol.Content{protocol.TextContent{Type: string(protocol.ContentTypeText), Text: reply}},
+					}, nil
+				},
+			}
+		}
+
+		assert.NoError(t, registry.RegisterTool(newEcho("one"), "server1"))
+		assert.NoError(t, registry.RegisterTool(newEcho("two"), "server2"), "Same name from another source should register")
+		assert.Len(t, registry.ListTools(), 2)
+
+		// Qualified lookups pick the right source
+		_, source, err := registry.ResolveTool("server2/echo")
+		assert.NoError(t, err)
+		assert.Equal(t, "server2", source)
+
+		// Unqualified lookups are ambiguous
+		_, _, err = registry.ResolveTool("echo")
+		assert.ErrorIs(t, err, ErrAmbiguousTool)
+
+		// Execution routes to the qualified tool
+		result, err := registry.ExecuteTool(&protocol.ToolCall{Name: "server1/echo"})
+		assert.NoError(t, err)
+		assert.Equal(t, "one", result.Content[0].(protocol.TextContent).Text)
+
+		result, err = registry.ExecuteTool(&protocol.ToolCall{Name: "server2/echo"})
+		assert.NoError(t, err)
+		assert.Equal(t, "two", result.Content[0].(protocol.TextContent).Text)
+
+		// Once one is removed the bare name resolves again
+		registry.UnregisterTool("server1/echo")
+		_, source, err = registry.ResolveTool("echo")
+		assert.NoError(t, err)
+		assert.Equal(t, "server2", source)
+	})
+
+	t.Run("rejects malformed schemas", func(t *testing.T) {
+		registry := NewRegistry()
+		malformed := map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"path": "string"},
+		}
+
+		err := registry.RegisterTool(&protocol.Tool{Name: "read", InputSchema: malformed}, "source1")
+		assert.EqualError(t, err, "tool read: invalid schema: properties.path: expected a schema object, got string")
+		_, exists := registry.GetTool("read")
+		assert.False(t, exists)
+
+		assert.NoError(t, registry.RegisterTool(createTestTools()[0], "source1"))
+		assert.Error(t, registry.ReplaceTool(&protocol.Tool{Name: "echo", InputSchema: malformed}, "source1"))
+		assert.Error(t, registry.UpdateToolSchema("echo", malformed))
+
+		tool, _ := registry.GetTool("echo")
+		assert.Equal(t, createTestTools()[0].InputSchema, tool.InputSchema, "A rejected change should keep the old schema")
+	})
+
 	t.Run("RegisterProtocolTool", func(t *testing.T) {
 		registry := NewRegistry()
 		protocolTool := createTestProtocolTools()[0]
@@ -120,6 +188,22 @@ func TestRegistry(t *testing.T) {
 		assert.Equal(t, "List files in a directory", tool.Description, "Description should match")
 	})
 
+	t.Run("RegisterProtocolTool keeps every field", func(t *testing.T) {
+		registry := NewRegistry()
+		protocolTool := protocol.Tool{
+			Name:            "login",
+			InputSchema:     map[string]interface{}{"type": "object"},
+			ApplyDefaults:   true,
+			SensitiveFields: []string{"password"},
+		}
+		assert.NoError(t, registry.RegisterProtocolTool(protocolTool, "server1"))
+
+		tool, exists := registry.GetTool("login")
+		assert.True(t, exists)
+		assert.True(t, tool.ApplyDefaults)
+		assert.Equal(t, []string{"password"}, tool.SensitiveFields)
+	})
+
 	t.Run("UnregisterTool", func(t *testing.T) {
 		registry := NewRegistry()
 		tool := createTestTools()[0]
@@ -140,6 +224,104 @@ func TestRegistry(t *testing.T) {
 		assert.False(t, exists, "Tool source should not exist after unregistering")
 	})
 
+	t.Run("ReplaceTool", func(t *testing.T) {
+		registry := NewRegistry()
+		events := registry.Subscribe()
+		assert.NoError(t, registry.RegisterTool(createTestTools()[0], "source1"))
+		<-events
+
+		replacement := &protocol.Tool{
+			Name:        "echo",
+			Description: "Echo, version two",
+			InputSchema: map[string]interface{}{"type": "object"},
+		}
+		assert.NoError(t, registry.ReplaceTool(replacement, "source1"))
+
+		tool, exists := registry.GetTool("echo")
+		assert.True(t, exists)
+		assert.Equal(t, replacement, tool)
+		assert.Equal(t, RegistryEvent{Type: ToolReplaced, Name: "echo", Source: "source1"}, <-events)
+
+		// Another source may not replace it
+		err := registry.ReplaceTool(replacement, "source2")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "registered by source source1")
+
+		tool, _ = registry.GetTool("echo")
+		assert.Equal(t, "Echo, version two", tool.Description)
+
+		// Nor can a missing tool be replaced
+		err = registry.ReplaceTool(&protocol.Tool{Name: "missing", InputSchema: map[string]interface{}{}}, "source1")
+		assert.ErrorIs(t, err, ErrToolNotFound)
+	})
+
+	t.Run("UpdateToolSchema", func(t *testing.T) {
+		registry := NewRegistry()
+		assert.NoError(t, registry.RegisterTool(createTestTools()[0], "source1"))
+		before, _ := registry.GetTool("echo")
+
+		schema := map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"text"},
+		}
+		assert.NoError(t, registry.UpdateToolSchema("source1/echo", schema))
+
+		after, _ := registry.GetTool("echo")
+		assert.Equal(t, schema, after.InputSchema)
+		assert.NotEqual(t, schema, before.InputSchema, "Earlier lookups should keep the old schema")
+
+		_, err := registry.ExecuteTool(&protocol.ToolCall{Name: "echo", Arguments: map[string]interface{}{}})
+		assert.Error(t, err, "The new schema should be enforced")
+
+		assert.ErrorIs(t, registry.UpdateToolSchema("missing", schema), ErrToolNotFound)
+
+		assert.NoError(t, registry.UpdateToolSchema("echo", nil))
+		_, err = registry.ExecuteTool(&protocol.ToolCall{Name: "echo", Arguments: map[string]interface{}{}})
+		assert.EqualError(t, err, "tool has no execute handler: echo", "A nil schema should accept any arguments")
+	})
+
+	t.Run("tools without an input schema accept any arguments", func(t *testing.T) {
+		registry := NewRegistry()
+		var received map[string]interface{}
+		tool := &protocol.Tool{
+			Name: "ping",
+			Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+				received = args
+				return &protocol.CallToolResult{}, nil
+			},
+		}
+		assert.NoError(t, registry.RegisterTool(tool, "source1"))
+		assert.NoError(t, registry.RegisterProtocolTool(protocol.Tool{Name: "pong"}, "source1"))
+
+		args := map[string]interface{}{"anything": 1.0}
+		_, err := registry.ExecuteTool(&protocol.ToolCall{Name: "ping", Arguments: args})
+		assert.NoError(t, err)
+		assert.Equal(t, args, received)
+
+		_, err = registry.ExecuteTool(&protocol.ToolCall{Name: "ping"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, registry.ReplaceTool(&protocol.Tool{Name: "ping", Execute: tool.Execute}, "source1"))
+		_, err = registry.ExecuteTool(&protocol.ToolCall{Name: "ping", Arguments: args})
+		assert.NoError(t, err)
+	})
+
+	t.Run("returned tools are copies", func(t *testing.T) {
+		registry := NewRegistry()
+		tool := createTestTools()[0]
+		assert.NoError(t, registry.RegisterTool(tool, "source1"))
+
+		// Neither the registered value nor a looked-up one aliases the registry.
+		tool.InputSchema["type"] = "array"
+		retrieved, _ := registry.GetTool("echo")
+		retrieved.Description = "changed"
+		retrieved.InputSchema["properties"].(map[string]interface{})["text"] = map[string]interface{}{"type": "number"}
+		registry.ListTools()[0].InputSchema["required"] = []string{"text"}
+
+		again, _ := registry.GetTool("echo")
+		assert.Equal(t, createTestTools()[0], again)
+	})
+
 	t.Run("ListTools", func(t *testing.T) {
 		registry := NewRegistry()
 		tools := createTestTools()
@@ -163,6 +345,32 @@ func TestRegistry(t *testing.T) {
 		assert.Equal(t, "add", source2Tools[0].Name, "Tool name should be 'add'")
 	})
 
+	t.Run("SearchTools", func(t *testing.T) {
+		registry := NewRegistry()
+		for _, tool := range createTestTools() {
+			assert.NoError(t, registry.RegisterTool(tool, "source1"))
+		}
+
+		assert.Len(t, registry.SearchTools(""), 2, "Empty query should match every tool")
+
+		tools := registry.SearchTools("ECHO")
+		assert.Len(t, tools, 1)
+		assert.Equal(t, "echo", tools[0].Name)
+
+		tools = registry.SearchTools("numbers")
+		assert.Len(t, tools, 1, "Descriptions should be searched too")
+		assert.Equal(t, "add", tools[0].Name)
+
+		assert.Empty(t, registry.SearchTools("no such tool"))
+
+		tools = registry.FindTools(func(tool *protocol.Tool) bool {
+			_, hasText := tool.InputSchema["properties"].(map[string]interface{})["text"]
+			return hasText
+		})
+		assert.Len(t, tools, 1)
+		assert.Equal(t, "echo", tools[0].Name)
+	})
+
 	t.Run("ExecuteTool", func(t *testing.T) {
 		registry := NewRegistry()
 
@@ -193,6 +401,7 @@ func TestRegistry(t *testing.T) {
 		}
 
 		// Register the mock tool
+		mockTool.Tool.Execute = mockTool.ExecuteFn
 		registry.RegisterTool(&mockTool.Tool, "test-source")
 
 		// Create a tool call
@@ -223,3 +432,280 @@ func TestRegistry(t *testing.T) {
 		assert.Error(t, err, "ExecuteTool should return an error for non-existent tool")
 	})
 }
+
+func TestRegistryCache(t *testing.T) {
+	// newCountingTool returns a tool that echoes its "n" argument and counts
+	// how often it really ran.
+	newCountingTool := func(name string, calls *int64) *protocol.Tool {
+		return &protocol.Tool{
+			Name:        name,
+			InputSchema: map[string]interface{}{"type": "object"},
+			Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+				atomic.AddInt64(calls, 1)
+				return &protocol.CallToolResult{
+					Content: []protocol.Content{protocol.TextContent{Type: string(protocol.ContentTypeText), Text: "ok"}},
+				}, nil
+			},
+		}
+	}
+
+	call := func(name string, args map[string]interface{}) *protocol.ToolCall {
+		return &protocol.ToolCall{Name: name, Arguments: args}
+	}
+
+	t.Run("reuses results for equal arguments regardless of map order", func(t *testing.T) {
+		var calls int64
+		registry := NewRegistry(WithCache(time.Minute, 0))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("slow", &calls), "s"))
+
+		first := map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"x": "1", "y": "2"}}
+		second := map[string]interface{}{"b": map[string]interface{}{"y": "2", "x": "1"}, "a": 1.0}
+
+		_, err := registry.ExecuteTool(call("slow", first))
+		assert.NoError(t, err)
+		_, err = registry.ExecuteTool(call("s/slow", second))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), calls)
+
+		_, err = registry.ExecuteTool(call("slow", map[string]interface{}{"a": 2.0}))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), calls)
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		var calls int64
+		registry := NewRegistry(WithCache(time.Minute, 0))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("slow", &calls), "s"))
+
+		now := time.Now()
+		registry.cache.now = func() time.Time { return now }
+
+		registry.ExecuteTool(call("slow", nil))
+		registry.ExecuteTool(call("slow", nil))
+		assert.Equal(t, int64(1), calls)
+
+		now = now.Add(time.Minute)
+		registry.ExecuteTool(call("slow", nil))
+		assert.Equal(t, int64(2), calls)
+	})
+
+	t.Run("evicts the oldest entry when full", func(t *testing.T) {
+		var calls int64
+		registry := NewRegistry(WithCache(time.Minute, 2))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("slow", &calls), "s"))
+
+		now := time.Now()
+		registry.cache.now = func() time.Time { return now }
+
+		for _, n := range []float64{1, 2, 3} {
+			registry.ExecuteTool(call("slow", map[string]interface{}{"n": n}))
+			now = now.Add(time.Second)
+		}
+		assert.Equal(t, int64(3), calls)
+
+		registry.ExecuteTool(call("slow", map[string]interface{}{"n": 3.0}))
+		assert.Equal(t, int64(3), calls, "Newest entry should still be cached")
+
+		registry.ExecuteTool(call("slow", map[string]interface{}{"n": 1.0}))
+		assert.Equal(t, int64(4), calls, "Oldest entry should have been evicted")
+	})
+
+	t.Run("InvalidateCache drops one tool's results", func(t *testing.T) {
+		var slowCalls, fastCalls int64
+		registry := NewRegistry(WithCache(time.Minute, 0))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("slow", &slowCalls), "s"))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("fast", &fastCalls), "s"))
+
+		registry.ExecuteTool(call("slow", nil))
+		registry.ExecuteTool(call("fast", nil))
+
+		registry.InvalidateCache("slow")
+
+		registry.ExecuteTool(call("slow", nil))
+		registry.ExecuteTool(call("fast", nil))
+		assert.Equal(t, int64(2), slowCalls)
+		assert.Equal(t, int64(1), fastCalls)
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		var calls int64
+		registry := NewRegistry(WithCache(time.Minute, 0))
+		registry.RegisterTool(&protocol.Tool{
+			Name:        "broken",
+			InputSchema: map[string]interface{}{"type": "object"},
+			Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+				atomic.AddInt64(&calls, 1)
+				return &protocol.CallToolResult{IsError: true}, nil
+			},
+		}, "s")
+
+		registry.ExecuteTool(call("broken", nil))
+		registry.ExecuteTool(call("broken", nil))
+		assert.Equal(t, int64(2), calls)
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		var calls int64
+		registry := NewRegistry(WithCache(time.Minute, 4))
+		assert.NoError(t, registry.RegisterTool(newCountingTool("slow", &calls), "s"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				result, err := registry.ExecuteTool(call("slow", map[string]interface{}{"n": float64(i % 8)}))
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				if i%10 == 0 {
+					registry.InvalidateCache("slow")
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, registry.cache.size, 4)
+	})
+
+	t.Run("does not cache results of a tool changed while it ran", func(t *testing.T) {
+		// textTool returns a tool answering text, which waits for release
+		// once it has signalled started.
+		textTool := func(text string, started, release chan struct{}) *protocol.Tool {
+			return &protocol.Tool{
+				Name: "slow",
+				Execute: func(args map[string]interface{}) (*protocol.CallToolResult, error) {
+					if started != nil {
+						close(started)
+						<-release
+					}
+					return &protocol.CallToolResult{
+						Content: []protocol.Content{protocol.TextContent{Type: string(protocol.ContentTypeText), Text: text}},
+					}, nil
+				},
+			}
+		}
+
+		for name, change := range map[string]func(registry *Registry) error{
+			"replaced": func(registry *Registry) error {
+				return registry.ReplaceTool(textTool("new", nil, nil), "s")
+			},
+			"schema updated": func(registry *Registry) error {
+				return registry.UpdateToolSchema("slow", map[string]interface{}{"type": "object"})
+			},
+			"unregistered and registered again": func(registry *Registry) error {
+				registry.UnregisterTool("slow")
+				return registry.RegisterTool(textTool("new", nil, nil), "s")
+			},
+			"invalidated": func(registry *Registry) error {
+				registry.InvalidateCache("slow")
+				return nil
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				registry := NewRegistry(WithCache(time.Minute, 0))
+				started, release := make(chan struct{}), make(chan struct{})
+				assert.NoError(t, registry.RegisterTool(textTool("old", started, release), "s"))
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					_, err := registry.ExecuteTool(call("slow", nil))
+					assert.NoError(t, err)
+				}()
+
+				<-started
+				assert.NoError(t, change(registry))
+				close(release)
+				<-done
+
+				assert.Equal(t, 0, registry.cache.size, "The old tool's result should not be cached")
+			})
+		}
+	})
+}
+
+func TestRegistryEvents(t *testing.T) {
+	t.Run("reports registrations and removals in order", func(t *testing.T) {
+		registry := NewRegistry()
+		events := registry.Subscribe()
+
+		tools := createTestTools()
+		assert.NoError(t, registry.RegisterTool(tools[0], "source1"))
+		assert.NoError(t, registry.RegisterTool(tools[1], "source2"))
+		registry.UnregisterTool("echo")
+
+		assert.Equal(t, RegistryEvent{Type: ToolRegistered, Name: "echo", Source: "source1"}, <-events)
+		assert.Equal(t, RegistryEvent{Type: ToolRegistered, Name: "add", Source: "source2"}, <-events)
+		assert.Equal(t, RegistryEvent{Type: ToolUnregistered, Name: "echo", Source: "source1"}, <-events)
+	})
+
+	t.Run("does not report failed changes", func(t *testing.T) {
+		registry := NewRegistry()
+		tool := createTestTools()[0]
+		assert.NoError(t, registry.RegisterTool(tool, "source1"))
+
+		events := registry.Subscribe()
+		assert.Error(t, registry.RegisterTool(tool, "source1"))
+		registry.UnregisterTool("missing")
+
+		assert.Len(t, events, 0)
+	})
+
+	t.Run("drops events for a slow subscriber instead of blocking", func(t *testing.T) {
+		registry := NewRegistry()
+		events := registry.Subscribe()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < SubscriberBuffer+10; i++ {
+				registry.RegisterTool(&protocol.Tool{
+					Name:        "tool",
+					InputSchema: map[string]interface{}{"type": "object"},
+				}, fmt.Sprintf("source%d", i))
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("registry blocked on a slow subscriber")
+		}
+		assert.Len(t, events, SubscriberBuffer)
+	})
+
+	t.Run("Unsubscribe closes the channel", func(t *testing.T) {
+		registry := NewRegistry()
+		events := registry.Subscribe()
+		registry.Unsubscribe(events)
+
+		assert.NoError(t, registry.RegisterTool(createTestTools()[0], "source1"))
+		_, open := <-events
+		assert.False(t, open)
+	})
+}
+
+func TestRegistryOrdering(t *testing.T) {
+	registry := NewRegistry()
+	for _, entry := range []struct{ name, source string }{
+		{"zeta", "b"}, {"alpha", "b"}, {"mid", "a"}, {"alpha", "a"}, {"beta", ""},
+	} {
+		assert.NoError(t, registry.RegisterTool(&protocol.Tool{Name: entry.name, InputSchema: map[string]interface{}{"type": "object"}}, entry.source))
+	}
+
+	names := func(tools []*protocol.Tool) []string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []string{"alpha", "alpha", "beta", "mid", "zeta"}, names(registry.ListTools()))
+		assert.Equal(t, []string{"alpha", "zeta"}, names(registry.ListToolsFromSource("b")))
+		assert.Equal(t, []string{"alpha", "alpha", "beta", "zeta"}, names(registry.SearchTools("a")))
+	}
+
+	assert.Equal(t, []string{"a/alpha", "b/alpha", "beta", "a/mid", "b/zeta"}, SortedKeys(registry.tools))
+}
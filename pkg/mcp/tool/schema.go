@@ -0,0 +1,134 @@
+package tool
+
+import "strings"
+
+// SchemaBuilder assembles a tool's InputSchema without spelling out nested
+// maps by hand:
+//
+//	schema := NewSchema().
+//		StringProp("path", "File to read").
+//		NumberProp("count").
+//		Required("path").
+//		Build()
+//
+// Descriptions are optional; several are joined with spaces.
+type SchemaBuilder struct {
+	schemaType string
+	properties map[string]interface{}
+	required   []string
+	additional *bool
+}
+
+// NewSchema starts an object schema with no properties.
+func NewSchema() *SchemaBuilder {
+	return &SchemaBuilder{
+		schemaType: "object",
+		properties: make(map[string]interface{}),
+	}
+}
+
+// Object sets the schema type to "object". It is the default, so calling it
+// only documents intent.
+func (b *SchemaBuilder) Object() *SchemaBuilder {
+	b.schemaType = "object"
+	return b
+}
+
+// Prop adds a property with an arbitrary schema, for anything the typed
+// helpers do not cover. Adding a name twice replaces the earlier schema.
+func (b *SchemaBuilder) Prop(name string, schema map[string]interface{}) *SchemaBuilder {
+	b.properties[name] = schema
+	return b
+}
+
+func (b *SchemaBuilder) StringProp(name string, description ...string) *SchemaBuilder {
+	return b.Prop(name, propSchema("string", description))
+}
+
+func (b *SchemaBuilder) NumberProp(name string, description ...string) *SchemaBuilder {
+	return b.Prop(name, propSchema("number", description))
+}
+
+func (b *SchemaBuilder) IntegerProp(name string, description ...string) *SchemaBuilder {
+	return b.Prop(name, propSchema("integer", description))
+}
+
+func (b *SchemaBuilder) BooleanProp(name string, description ...string) *SchemaBuilder {
+	return b.Prop(name, propSchema("boolean", description))
+}
+
+// ArrayProp adds an array property whose items match items, which may be nil
+// to allow any items.
+func (b *SchemaBuilder) ArrayProp(name string, items map[string]interface{}, description ...string) *SchemaBuilder {
+	schema := propSchema("array", description)
+	if items != nil {
+		schema["items"] = items
+	}
+	return b.Prop(name, schema)
+}
+
+// ObjectProp adds a nested object property described by another builder.
+func (b *SchemaBuilder) ObjectProp(name string, nested *SchemaBuilder, description ...string) *SchemaBuilder {
+	schema := nested.Build()
+	if desc := strings.Join(description, " "); desc != "" {
+		schema["description"] = desc
+	}
+	return b.Prop(name, schema)
+}
+
+// Required marks properties as required. Names are kept in the order given
+// and listed once.
+func (b *SchemaBuilder) Required(names ...string) *SchemaBuilder {
+	for _, name := range names {
+		if !containsString(b.required, name) {
+			b.required = append(b.required, name)
+		}
+	}
+	return b
+}
+
+// AdditionalProperties sets whether arguments not declared as properties are
+// accepted. When never called the schema leaves it unset, which accepts them.
+func (b *SchemaBuilder) AdditionalProperties(allowed bool) *SchemaBuilder {
+	b.additional = &allowed
+	return b
+}
+
+// Build returns the schema. Each call returns new top-level maps, so the
+// builder can be extended and built again without changing earlier results.
+func (b *SchemaBuilder) Build() map[string]interface{} {
+	properties := make(map[string]interface{}, len(b.properties))
+	for name, schema := range b.properties {
+		properties[name] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":       b.schemaType,
+		"properties": properties,
+	}
+	if len(b.required) > 0 {
+		schema["required"] = append([]string(nil), b.required...)
+	}
+	if b.additional != nil {
+		schema["additionalProperties"] = *b.additional
+	}
+
+	return schema
+}
+
+func propSchema(schemaType string, description []string) map[string]interface{} {
+	schema := map[string]interface{}{"type": schemaType}
+	if desc := strings.Join(description, " "); desc != "" {
+		schema["description"] = desc
+	}
+	return schema
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package tool
+
+import (
+	"testing"
+
+	"go-mcp/pkg/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	t.Run("builds the equivalent map", func(t *testing.T) {
+		schema := NewSchema().Object().
+			StringProp("path", "File to read").
+			NumberProp("count").
+			BooleanProp("follow", "Follow", "symlinks").
+			ArrayProp("tags", map[string]interface{}{"type": "string"}).
+			ObjectProp("options", NewSchema().IntegerProp("depth").Required("depth")).
+			Required("path", "count", "path").
+			AdditionalProperties(false).
+			Build()
+
+		assert.Equal(t, map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path":   map[string]interface{}{"type": "string", "description": "File to read"},
+				"count":  map[string]interface{}{"type": "number"},
+				"follow": map[string]interface{}{"type": "boolean", "description": "Follow symlinks"},
+				"tags":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				"options": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"depth": map[string]interface{}{"type": "integer"}},
+					"required":   []string{"depth"},
+				},
+			},
+			"required":             []string{"path", "count"},
+			"additionalProperties": false,
+		}, schema)
+	})
+
+	t.Run("produces schemas that validate", func(t *testing.T) {
+		tool := &protocol.Tool{
+			Name: "read",
+			InputSchema: NewSchema().
+				StringProp("path").
+				IntegerProp("lines").
+				ObjectProp("options", NewSchema().BooleanProp("follow").Required("follow")).
+				Required("path").
+				Build(),
+		}
+
+		assert.NoError(t, tool.ValidateArguments(map[string]interface{}{"path": "/tmp/a", "lines": 10.0}))
+		assert.EqualError(t, tool.ValidateArguments(map[string]interface{}{"lines": 10.0}), "missing required field: path")
+		assert.Error(t, tool.ValidateArguments(map[string]interface{}{"path": 1.0}))
+		assert.Error(t, tool.ValidateArguments(map[string]interface{}{"path": "/tmp/a", "lines": 1.5}))
+		assert.EqualError(t, tool.ValidateArguments(map[string]interface{}{
+			"path":    "/tmp/a",
+			"options": map[string]interface{}{},
+		}), "invalid argument options: missing required field: follow")
+
+		registry := NewRegistry()
+		require.NoError(t, registry.RegisterTool(tool, "local"))
+	})
+
+	t.Run("earlier builds are unaffected by later changes", func(t *testing.T) {
+		builder := NewSchema().StringProp("a").Required("a")
+		first := builder.Build()
+
+		builder.StringProp("b").Required("b")
+		second := builder.Build()
+
+		assert.Len(t, first["properties"], 1)
+		assert.Equal(t, []string{"a"}, first["required"])
+		assert.Len(t, second["properties"], 2)
+		assert.Equal(t, []string{"a", "b"}, second["required"])
+	})
+}